@@ -1,82 +1,446 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
 
-	"github.com/Kaamkiya/gg/internal/app/blackjack"
-	"github.com/Kaamkiya/gg/internal/app/connect4"
-	"github.com/Kaamkiya/gg/internal/app/dodger"
-	"github.com/Kaamkiya/gg/internal/app/hangman"
-	"github.com/Kaamkiya/gg/internal/app/maze"
-	"github.com/Kaamkiya/gg/internal/app/pong"
-	"github.com/Kaamkiya/gg/internal/app/snake"
-	"github.com/Kaamkiya/gg/internal/app/sudoku"
-	"github.com/Kaamkiya/gg/internal/app/tetris"
-	"github.com/Kaamkiya/gg/internal/app/tictactoe"
-	"github.com/Kaamkiya/gg/internal/app/twenty48"
-	"github.com/Kaamkiya/gg/internal/app/typespeed"
-
-	"github.com/charmbracelet/huh"
+	"github.com/Kaamkiya/gg/internal/achievements"
+	_ "github.com/Kaamkiya/gg/internal/app/blackjack"
+	_ "github.com/Kaamkiya/gg/internal/app/connect4"
+	_ "github.com/Kaamkiya/gg/internal/app/dodger"
+	_ "github.com/Kaamkiya/gg/internal/app/hangman"
+	_ "github.com/Kaamkiya/gg/internal/app/maze"
+	_ "github.com/Kaamkiya/gg/internal/app/pong"
+	_ "github.com/Kaamkiya/gg/internal/app/snake"
+	_ "github.com/Kaamkiya/gg/internal/app/sudoku"
+	_ "github.com/Kaamkiya/gg/internal/app/tetris"
+	_ "github.com/Kaamkiya/gg/internal/app/tictactoe"
+	_ "github.com/Kaamkiya/gg/internal/app/twenty48"
+	_ "github.com/Kaamkiya/gg/internal/app/typespeed"
+	"github.com/Kaamkiya/gg/internal/config"
+	"github.com/Kaamkiya/gg/internal/daily"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/launcher"
+	"github.com/Kaamkiya/gg/internal/replay"
+	"github.com/Kaamkiya/gg/internal/scores"
+	"github.com/Kaamkiya/gg/internal/stats"
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
+const usage = `gg - a tui for small offline games
+
+Usage:
+  gg [-theme name]    choose a game from the menu
+  gg [-theme name] <game> [flags]
+                      start a game directly
+  gg list             list the available games
+  gg scores [game]    show the high scores of every game, or just one
+  gg daily [game]     show today's challenges, or play one
+  gg stats [game]     show the lifetime stats of every game, starting on one
+  gg achievements     list the achievements and those you have unlocked
+  gg record <file> <game> [flags]
+                      play a real-time game and record it to file
+  gg replay [-speed n] <file>
+                      watch a recorded game
+  gg help             show this help
+
+The theme is one of auto, dark, light, high-contrast, colorblind and mono. It
+can also be set in the config file. Without it, gg picks dark or light to
+suit the terminal, or mono if NO_COLOR is set.
+
+Run 'gg <game> -h' to see the flags a game accepts.
+`
+
 func main() {
-	var game string
-
-	fmt.Println("gg - a tui for small offline games")
-
-	err := huh.NewSelect[string]().
-		Title("choose a game:").
-		Options(
-			huh.NewOption("typespeed", "typespeed"),
-			huh.NewOption("blackjack", "blackjack"),
-			huh.NewOption("2048", "twenty48"),
-			huh.NewOption("sudoku", "sudoku"),
-			huh.NewOption("dodger", "dodger"),
-			huh.NewOption("maze", "maze"),
-			huh.NewOption("hangman", "hangman"),
-			huh.NewOption("snake", "snake"),
-			huh.NewOption("tetris", "tetris"),
-			huh.NewOption("connect 4 (2 player)", "connect4"),
-			huh.NewOption("pong (2 player)", "pong"),
-			huh.NewOption("tictactoe (2 player)", "tictactoe"),
-			huh.NewOption("tictactoe (vs AI)", "tictactoe-ai"),
-		).
-		Value(&game).
-		Run()
+	os.Exit(run(os.Args[1:]))
+}
+
+// run executes the command described by args and returns the exit code.
+func run(args []string) int {
+	flags := flag.NewFlagSet("gg", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	themeName := flags.String("theme", "", "")
+
+	// The flags before the command apply to every game.
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fmt.Print(usage)
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "gg: %v\n\n%s", err, usage)
+		return 2
+	}
+	args = flags.Args()
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "help":
+			fmt.Print(usage)
+			return 0
+		case "list":
+			list(os.Stdout)
+			return 0
+		case "scores":
+			return showScores(os.Stdout, args[1:])
+		case "achievements":
+			return listAchievements(os.Stdout)
+		}
+	}
+
+	// The config only matters once a game is played.
+	if err := configure(*themeName); err != nil {
+		fmt.Fprintf(os.Stderr, "gg: %v\n", err)
+		return 2
+	}
+
+	if len(args) == 0 {
+		return menu()
+	}
+
+	switch args[0] {
+	case "daily":
+		return playDaily(os.Stdout, args[1:])
+	case "stats":
+		return showStats(args[1:])
+	case "record":
+		return record(args[1:])
+	case "replay":
+		return watch(args[1:])
+	}
+
+	g, ok := game.Lookup(args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "gg: unknown game %q\nRun 'gg list' to see the available games.\n", args[0])
+		return 2
+	}
+
+	model, code := parse(g, args[1:])
+	if model == nil {
+		return code
+	}
+
+	return play(launcher.Play(g, model))
+}
+
+// parse builds the model of g from its command line arguments. If they don't
+// describe a round to play, the model is nil and the program should exit with
+// the returned code.
+func parse(g game.Game, args []string) (tea.Model, int) {
+	model, err := g.Parse(args, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil, 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gg %s: %v\nRun 'gg %s -h' to see the flags it accepts.\n", g.ID, err, g.ID)
+		return nil, 2
+	}
+
+	return model, 0
+}
+
+// configure applies the settings in the player's config file. The theme named
+// on the command line, if any, replaces the one in the file.
+func configure(themeName string) error {
+	c, err := config.Load()
 	if err != nil {
-		fmt.Println("Error: failed to run selection menu.")
-		panic(err)
-	}
-
-	switch game {
-	case "blackjack":
-		blackjack.Run()
-	case "maze":
-		maze.Run()
-	case "pong":
-		pong.Run()
-	case "tictactoe":
-		tictactoe.Run()
-	case "tictactoe-ai":
-		tictactoe.RunVsAi()
-	case "dodger":
-		dodger.Run()
-	case "hangman":
-		hangman.Run()
-	case "twenty48":
-		twenty48.Run()
-	case "connect4":
-		connect4.Run()
-	case "snake":
-		snake.Run()
-	case "sudoku":
-		sudoku.Run()
-	case "tetris":
-		tetris.Run()
-	case "typespeed":
-		typespeed.Run()
+		return err
+	}
+
+	path, _ := config.Path()
+	if err := game.ConfigureKeys(c.Keys); err != nil {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+
+	if themeName != "" {
+		return theme.Set(themeName)
+	}
+	if err := theme.Set(c.Theme); err != nil {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// menu shows the game menu. Games started from it return to it when they end.
+func menu() int {
+	return play(launcher.New())
+}
+
+func play(model tea.Model) int {
+	// Real-time games pause when the terminal loses focus.
+	if _, err := tea.NewProgram(model, tea.WithReportFocus()).Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
 
+// list prints every game along with its description.
+func list(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	for _, g := range game.All() {
+		players := "1 player"
+		if g.Players > 1 {
+			players = fmt.Sprintf("%d players", g.Players)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", g.ID, players, g.Description)
+	}
+	tw.Flush()
+}
+
+// showScores prints the leaderboards of the games named in args, or of every
+// game with a leaderboard if args is empty.
+func showScores(w io.Writer, args []string) int {
+	var games []game.Game
+
+	switch len(args) {
+	case 0:
+		for _, g := range game.All() {
+			if g.HighScores {
+				games = append(games, g)
+			}
+		}
+	case 1:
+		g, ok := game.Lookup(args[0])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "gg scores: unknown game %q\nRun 'gg list' to see the available games.\n", args[0])
+			return 2
+		}
+		if !g.HighScores {
+			fmt.Fprintf(os.Stderr, "gg scores: %s doesn't keep high scores\n", g.ID)
+			return 2
+		}
+		games = append(games, g)
 	default:
-		panic("This game either doesn't exist or hasn't been implemented.")
+		fmt.Fprintln(os.Stderr, "gg scores: expected at most one game")
+		return 2
+	}
+
+	boards, err := scores.All()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gg scores: %v\n", err)
+		return 1
+	}
+
+	for i, g := range games {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		fmt.Fprintln(w, g.Name)
+		if len(boards[g.ID]) == 0 {
+			fmt.Fprintln(w, "  no high scores yet")
+		}
+		for rank, e := range boards[g.ID] {
+			fmt.Fprintln(w, "  "+scores.Format(rank+1, e))
+		}
 	}
+
+	return 0
+}
+
+// listAchievements prints every achievement along with the day it was
+// unlocked, if it has been.
+func listAchievements(w io.Writer) int {
+	unlocked, err := achievements.Unlocked()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gg achievements: %v\n", err)
+		return 1
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	for _, a := range achievements.All() {
+		name := a.Game
+		if g, ok := game.Lookup(a.Game); ok {
+			name = g.Name
+		}
+
+		when := "locked"
+		if t, ok := unlocked[a.ID]; ok {
+			when = "unlocked " + t.Format("Jan 2 2006")
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", a.Title, name, a.Description, when)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d of %d unlocked\n", len(unlocked), len(achievements.All()))
+	return 0
+}
+
+// showStats shows the stats screen, starting on the game named in args, if
+// any.
+func showStats(args []string) int {
+	id := ""
+
+	switch len(args) {
+	case 0:
+	case 1:
+		g, ok := game.Lookup(args[0])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "gg stats: unknown game %q\nRun 'gg list' to see the available games.\n", args[0])
+			return 2
+		}
+		id = g.ID
+	default:
+		fmt.Fprintln(os.Stderr, "gg stats: expected at most one game")
+		return 2
+	}
+
+	return play(stats.NewScreen(id))
+}
+
+// playDaily plays today's challenge of the game named in args, or lists the
+// challenges along with their results if args is empty.
+func playDaily(w io.Writer, args []string) int {
+	switch len(args) {
+	case 0:
+	case 1:
+		g, ok := game.Lookup(args[0])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "gg daily: unknown game %q\nRun 'gg daily' to see the daily challenges.\n", args[0])
+			return 2
+		}
+		if g.Daily == nil {
+			fmt.Fprintf(os.Stderr, "gg daily: %s has no daily challenge\n", g.ID)
+			return 2
+		}
+		return play(launcher.Daily(g))
+	default:
+		fmt.Fprintln(os.Stderr, "gg daily: expected at most one game")
+		return 2
+	}
+
+	day := daily.Today()
+	fmt.Fprintf(w, "Daily challenges of %s\n\n", day)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	for _, g := range game.All() {
+		if g.Daily == nil {
+			continue
+		}
+
+		r, played, err := daily.Lookup(day, g.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gg daily: %v\n", err)
+			return 1
+		}
+
+		streak, err := daily.Streak(day, g.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gg daily: %v\n", err)
+			return 1
+		}
+
+		status := "not played yet"
+		if played {
+			status = r.Summary
+			if status == "" {
+				status = fmt.Sprintf("score %d", r.Score)
+			}
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%d day streak\n", g.ID, status, streak)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w, "\nRun 'gg daily <game>' to play one.")
+	return 0
+}
+
+// record plays the game named in args, with the flags that follow it, and
+// saves a recording of the round to the file named first.
+func record(args []string) int {
+	if len(args) < 2 {
+		fmt.Fprintln(os.Stderr, "gg record: expected a file and a game")
+		return 2
+	}
+
+	path := args[0]
+	g, ok := game.Lookup(args[1])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "gg record: unknown game %q\nRun 'gg list' to see the available games.\n", args[1])
+		return 2
+	}
+	if !g.Recordable {
+		fmt.Fprintf(os.Stderr, "gg record: %s can't be recorded\n", g.ID)
+		return 2
+	}
+
+	// The replay parses the same arguments, so the seed is pinned here. A
+	// -seed passed by the player comes later and wins.
+	gameArgs := append([]string{"-seed", strconv.FormatUint(game.RandomSeed(), 10)}, args[2:]...)
+	model, code := parse(g, gameArgs)
+	if model == nil {
+		return code
+	}
+
+	rec := replay.New(g.ID, gameArgs)
+	if code := play(launcher.Record(g, model, rec)); code != 0 {
+		return code
+	}
+
+	if err := rec.Save(path); err != nil {
+		fmt.Fprintf(os.Stderr, "gg record: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Recorded to %s. Run 'gg replay %s' to watch it.\n", path, path)
+	return 0
+}
+
+// watch plays back the recording named in args.
+func watch(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	speed := fs.Float64("speed", 1, "playback speed, e.g. 2 to watch at twice the speed")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: gg replay [-speed n] <file>\n\nFlags:")
+		fs.PrintDefaults()
+	}
+
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		return 2
+	}
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "gg replay: expected a file")
+		return 2
+	}
+	if *speed <= 0 {
+		fmt.Fprintln(os.Stderr, "gg replay: the speed must be positive")
+		return 2
+	}
+
+	rec, err := replay.Load(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gg replay: %v\n", err)
+		return 1
+	}
+
+	g, ok := game.Lookup(rec.Game)
+	if !ok || !g.Recordable {
+		fmt.Fprintf(os.Stderr, "gg replay: %s is a recording of an unknown game %q\n", fs.Arg(0), rec.Game)
+		return 1
+	}
+
+	model, err := g.Parse(rec.Args, io.Discard)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gg replay: %s has invalid arguments for %s: %v\n", fs.Arg(0), g.ID, err)
+		return 1
+	}
+
+	return play(replay.NewPlayer(rec, model, *speed))
 }
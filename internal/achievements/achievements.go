@@ -0,0 +1,200 @@
+// Package achievements unlocks the player's achievements from the events the
+// games publish. Each achievement is a rule on the events of one game, and the
+// dates they were unlocked live in a single versioned file in gg's data
+// directory.
+package achievements
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	fileName = "achievements.json"
+
+	// version is the current version of the achievements file. Files
+	// written by a newer version of gg are refused rather than overwritten.
+	version = 1
+)
+
+// Achievement is something the player can unlock in a game.
+type Achievement struct {
+	// ID identifies the achievement in the achievements file.
+	ID    string
+	Title string
+
+	// Description tells the player how to unlock the achievement.
+	Description string
+
+	// Game is the ID of the game whose events unlock the achievement.
+	Game string
+
+	// Event is the name of the event that unlocks the achievement once its
+	// value is at least AtLeast.
+	Event   string
+	AtLeast int
+}
+
+// achievements are the rules of every achievement, listed by game.
+var achievements = []Achievement{
+	{
+		ID:          "twenty48-2048",
+		Title:       "2048",
+		Description: "Reach the 2048 tile.",
+		Game:        "twenty48",
+		Event:       "tile",
+		AtLeast:     2048,
+	},
+	{
+		ID:          "tetris-tetris",
+		Title:       "Tetris",
+		Description: "Clear four lines at once in tetris.",
+		Game:        "tetris",
+		Event:       "lines",
+		AtLeast:     4,
+	},
+	{
+		ID:          "blackjack-five-cards",
+		Title:       "Five card hand",
+		Description: "Win a hand of blackjack holding five cards.",
+		Game:        "blackjack",
+		Event:       "win",
+		AtLeast:     5,
+	},
+	{
+		ID:          "tictactoe-ai-beaten",
+		Title:       "Outsmarted",
+		Description: "Beat the tictactoe AI.",
+		Game:        "tictactoe-ai",
+		Event:       "win",
+	},
+	{
+		ID:          "sudoku-flawless",
+		Title:       "Flawless",
+		Description: "Solve a sudoku without a wrong entry, a hint or checking.",
+		Game:        "sudoku",
+		Event:       "flawless",
+	},
+	{
+		ID:          "typespeed-100",
+		Title:       "Speed typist",
+		Description: "Type at 100 WPM in typespeed.",
+		Game:        "typespeed",
+		Event:       "wpm",
+		AtLeast:     100,
+	},
+}
+
+// UnlockedMsg is sent when an event has unlocked achievements.
+type UnlockedMsg struct {
+	// Achievements are the achievements unlocked for the first time.
+	Achievements []Achievement
+	Err          error
+}
+
+func init() {
+	game.Subscribe(observe)
+}
+
+// All returns every achievement, listed by game.
+func All() []Achievement {
+	return achievements
+}
+
+// matches reports whether an event of the game with the given ID unlocks a.
+func (a Achievement) matches(id string, e game.Event) bool {
+	return a.Game == id && a.Event == e.Name && e.Value >= a.AtLeast
+}
+
+// observe is the subscriber unlocking the achievements an event earns. The
+// achievements file is only touched when the event matches a rule.
+func observe(id string, e game.Event) tea.Cmd {
+	var earned []Achievement
+	for _, a := range achievements {
+		if a.matches(id, e) {
+			earned = append(earned, a)
+		}
+	}
+
+	if len(earned) == 0 {
+		return nil
+	}
+
+	return func() tea.Msg {
+		unlocked, err := unlock(earned, time.Now())
+		return UnlockedMsg{unlocked, err}
+	}
+}
+
+type file struct {
+	Version int `json:"version"`
+
+	// Unlocked holds when each achievement was unlocked, by ID.
+	Unlocked map[string]time.Time `json:"unlocked"`
+}
+
+func decode(data []byte) (file, error) {
+	f := file{Version: version}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &f); err != nil {
+			return f, fmt.Errorf("achievements: reading %s: %w", fileName, err)
+		}
+	}
+
+	if f.Version > version {
+		return f, fmt.Errorf("achievements: %s was written by a newer version of gg", fileName)
+	}
+
+	if f.Unlocked == nil {
+		f.Unlocked = map[string]time.Time{}
+	}
+
+	return f, nil
+}
+
+// Unlocked returns when each unlocked achievement was unlocked, by ID.
+func Unlocked() (map[string]time.Time, error) {
+	data, err := storage.Read(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := decode(data)
+	return f.Unlocked, err
+}
+
+// unlock saves earned as unlocked at now and returns those that weren't
+// unlocked already.
+func unlock(earned []Achievement, now time.Time) ([]Achievement, error) {
+	var unlocked []Achievement
+
+	err := storage.Update(fileName, func(data []byte) ([]byte, error) {
+		f, err := decode(data)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range earned {
+			if _, ok := f.Unlocked[a.ID]; !ok {
+				f.Unlocked[a.ID] = now
+				unlocked = append(unlocked, a)
+			}
+		}
+
+		f.Version = version
+		return json.MarshalIndent(f, "", "  ")
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return unlocked, nil
+}
@@ -0,0 +1,57 @@
+package achievements
+
+import (
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/game"
+)
+
+func TestObserve(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	if cmd := observe("twenty48", game.Event{Name: "tile", Value: 1024}); cmd != nil {
+		t.Error("expected a 1024 tile to unlock nothing")
+	}
+	if cmd := observe("tetris", game.Event{Name: "tile", Value: 2048}); cmd != nil {
+		t.Error("expected the events of another game to unlock nothing")
+	}
+
+	cmd := observe("twenty48", game.Event{Name: "tile", Value: 4096})
+	if cmd == nil {
+		t.Fatal("expected a 4096 tile to unlock 2048")
+	}
+	msg := cmd().(UnlockedMsg)
+	if msg.Err != nil {
+		t.Fatal(msg.Err)
+	}
+	if len(msg.Achievements) != 1 || msg.Achievements[0].ID != "twenty48-2048" {
+		t.Fatalf("expected 2048 to be unlocked, got %+v", msg.Achievements)
+	}
+
+	// An achievement is only unlocked once.
+	msg = observe("twenty48", game.Event{Name: "tile", Value: 2048})().(UnlockedMsg)
+	if msg.Err != nil || len(msg.Achievements) != 0 {
+		t.Errorf("expected nothing new to be unlocked, got %+v", msg)
+	}
+
+	unlocked, err := Unlocked()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := unlocked["twenty48-2048"]; !ok || len(unlocked) != 1 {
+		t.Errorf("expected 2048 to be saved as unlocked, got %v", unlocked)
+	}
+}
+
+func TestAchievementsAreUnique(t *testing.T) {
+	seen := map[string]bool{}
+	for _, a := range All() {
+		if a.ID == "" || a.Title == "" || a.Game == "" || a.Event == "" {
+			t.Errorf("incomplete achievement %+v", a)
+		}
+		if seen[a.ID] {
+			t.Errorf("achievement %s is defined twice", a.ID)
+		}
+		seen[a.ID] = true
+	}
+}
@@ -0,0 +1,111 @@
+// Package ai holds computer players for the two-player board games, which play
+// any game described by a Game, without knowing its rules.
+package ai
+
+import "math/rand/v2"
+
+// Game describes the rules of a two-player, zero-sum game with perfect
+// information, whose positions are of type S and moves of type M.
+type Game[S, M any] interface {
+	// Legal returns the moves of the player to move in s.
+	Legal(s S) []M
+
+	// Play returns the position after the player to move in s plays m,
+	// leaving s unchanged.
+	Play(s S, m M) S
+
+	// Result returns whether the game is over in s and, if so, its value for
+	// the player to move: 1 if they won, -1 if they lost and 0 for a draw.
+	Result(s S) (over bool, value float64)
+}
+
+// Evaluator can be implemented by a Game to estimate the value of a position
+// that isn't over for the player to move, between -1 and 1. Greedy and
+// Minimax players use it when they can't see the end of the game.
+type Evaluator[S any] interface {
+	Evaluate(s S) float64
+}
+
+// Hasher can be implemented by a Game to tell positions apart by a key, so
+// that Minimax players search the positions reached by different moves only
+// once.
+type Hasher[S any] interface {
+	Hash(s S) uint64
+}
+
+// Roller can be implemented by a Game to play the random games of MCTS
+// players itself, picking likely moves rather than any legal move, which
+// keeps the search useful on games with many moves, or playing them faster
+// than with Play.
+type Roller[S any] interface {
+	// Rollout plays s with moves picked with rng and returns the result for
+	// the player to move in s, 0 if it stops before the end.
+	Rollout(s S, rng *rand.Rand) float64
+}
+
+// AI is a computer player.
+type AI[S, M any] interface {
+	// Solve returns the move to play in s, which must not be over.
+	Solve(s S) M
+}
+
+// evaluate returns the estimate of g for s, or 0 if it makes none.
+func evaluate[S, M any](g Game[S, M], s S) float64 {
+	if e, ok := g.(Evaluator[S]); ok {
+		return e.Evaluate(s)
+	}
+
+	return 0
+}
+
+type random[S, M any] struct {
+	game Game[S, M]
+	rng  *rand.Rand
+}
+
+// NewRandom returns a player picking any legal move with rng.
+func NewRandom[S, M any](game Game[S, M], rng *rand.Rand) AI[S, M] {
+	return &random[S, M]{game, rng}
+}
+
+func (r *random[S, M]) Solve(s S) M {
+	moves := r.game.Legal(s)
+	return moves[r.rng.IntN(len(moves))]
+}
+
+type greedy[S, M any] struct {
+	game Game[S, M]
+	rng  *rand.Rand
+}
+
+// NewGreedy returns a player looking a single move ahead, which takes a win
+// when it sees one and otherwise the move leaving the position its game
+// evaluates best, picking among equal moves with rng.
+func NewGreedy[S, M any](game Game[S, M], rng *rand.Rand) AI[S, M] {
+	return &greedy[S, M]{game, rng}
+}
+
+func (g *greedy[S, M]) Solve(s S) M {
+	var best []M
+	bestValue := 0.0
+
+	for _, m := range g.game.Legal(s) {
+		next := g.game.Play(s, m)
+
+		// The values of the next position are for the opponent.
+		var value float64
+		if over, v := g.game.Result(next); over {
+			value = -v * 2
+		} else {
+			value = -evaluate(g.game, next)
+		}
+
+		if len(best) == 0 || value > bestValue {
+			best, bestValue = []M{m}, value
+		} else if value == bestValue {
+			best = append(best, m)
+		}
+	}
+
+	return best[g.rng.IntN(len(best))]
+}
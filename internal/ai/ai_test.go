@@ -0,0 +1,106 @@
+package ai
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+// nim is played with a pile of stones, from which the players take one to
+// three in turn. The player taking the last stone wins, so the player to move
+// loses when the pile is a multiple of four.
+type nim struct{}
+
+func (nim) Legal(pile int) []int {
+	var moves []int
+	for take := 1; take <= min(3, pile); take++ {
+		moves = append(moves, take)
+	}
+
+	return moves
+}
+
+func (nim) Play(pile, take int) int {
+	return pile - take
+}
+
+func (nim) Result(pile int) (bool, float64) {
+	// The opponent took the last stone.
+	return pile == 0, -1
+}
+
+func (nim) Evaluate(pile int) float64 {
+	if pile%4 == 0 {
+		return -0.5
+	}
+
+	return 0.5
+}
+
+func TestSolve(t *testing.T) {
+	ais := map[string]AI[int, int]{
+		"greedy":  NewGreedy[int, int](nim{}, rand.New(rand.NewPCG(1, 2))),
+		"minimax": NewMinimax[int, int](nim{}, 4),
+		"mcts":    NewMCTS[int, int](nim{}, MCTSOptions{Iterations: 250, Trees: 4}, rand.New(rand.NewPCG(1, 2))),
+	}
+
+	for name, ai := range ais {
+		for pile := 1; pile <= 11; pile++ {
+			if pile%4 == 0 {
+				continue
+			}
+
+			if take := ai.Solve(pile); take != pile%4 {
+				t.Errorf("%s took %d from %d, want %d", name, take, pile, pile%4)
+			}
+		}
+	}
+}
+
+func TestRandom(t *testing.T) {
+	r := NewRandom[int, int](nim{}, rand.New(rand.NewPCG(1, 2)))
+	seen := map[int]bool{}
+	for range 100 {
+		take := r.Solve(2)
+		if take < 1 || take > 2 {
+			t.Fatalf("took %d from 2", take)
+		}
+		seen[take] = true
+	}
+
+	if len(seen) != 2 {
+		t.Errorf("took %v, want both moves", seen)
+	}
+}
+
+func TestMCTSIsDeterministic(t *testing.T) {
+	solve := func() []int {
+		player := NewMCTS[int, int](nim{}, MCTSOptions{Iterations: 20, Trees: 8}, rand.New(rand.NewPCG(3, 4)))
+
+		var moves []int
+		for pile := 4; pile <= 40; pile += 4 {
+			moves = append(moves, player.Solve(pile))
+		}
+		return moves
+	}
+
+	first := solve()
+	for range 5 {
+		if moves := solve(); !slices.Equal(moves, first) {
+			t.Fatalf("expected the moves %v again, got %v", first, moves)
+		}
+	}
+}
+
+func TestMCTSBudget(t *testing.T) {
+	player := NewMCTS[int, int](nim{}, MCTSOptions{Budget: 20 * time.Millisecond, Trees: 2}, rand.New(rand.NewPCG(1, 2)))
+
+	start := time.Now()
+	if take := player.Solve(7); take != 3 {
+		t.Errorf("took %d from 7, want 3", take)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the search to stop after its budget, took %v", elapsed)
+	}
+}
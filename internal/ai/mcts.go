@@ -0,0 +1,227 @@
+package ai
+
+import (
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// explore weighs the exploration of the moves tried the least against the
+// exploitation of the best ones in the tree search.
+const explore = 1.41
+
+// MCTSOptions set how long a Monte Carlo tree search thinks. It stops after
+// Iterations random games or once Budget has passed, whichever comes first,
+// so at least one of them must be set.
+type MCTSOptions struct {
+	Iterations int
+	Budget     time.Duration
+
+	// Trees is the number of trees searched independently, on as many
+	// goroutines as there are CPUs, before their visits of each move are
+	// added up. Each tree runs Iterations games. The search plays the same
+	// move for the same random numbers as long as it has no Budget, whatever
+	// the number of CPUs. The Game must be safe for concurrent use when there
+	// is more than one tree.
+	Trees int
+}
+
+type mcts[S, M any] struct {
+	game Game[S, M]
+	opts MCTSOptions
+	rng  *rand.Rand
+}
+
+// NewMCTS returns a Monte Carlo tree search, which picks the moves to explore
+// with rng. It plays better the more games it plays.
+func NewMCTS[S, M any](game Game[S, M], opts MCTSOptions, rng *rand.Rand) AI[S, M] {
+	if opts.Iterations <= 0 && opts.Budget <= 0 {
+		opts.Iterations = 1
+	}
+	opts.Trees = max(opts.Trees, 1)
+
+	return &mcts[S, M]{game, opts, rng}
+}
+
+func (m *mcts[S, M]) Solve(s S) M {
+	var deadline time.Time
+	if m.opts.Budget > 0 {
+		deadline = time.Now().Add(m.opts.Budget)
+	}
+
+	// Every tree gets its own random numbers, drawn in order so that they
+	// don't depend on how the goroutines are scheduled.
+	rngs := make([]*rand.Rand, m.opts.Trees)
+	for i := range rngs {
+		rngs[i] = rand.New(rand.NewPCG(m.rng.Uint64(), m.rng.Uint64()))
+	}
+
+	legal := m.game.Legal(s)
+	visits := make([][]int, m.opts.Trees)
+
+	trees := make(chan int)
+	var wg sync.WaitGroup
+	for range min(m.opts.Trees, runtime.NumCPU()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range trees {
+				visits[i] = m.search(s, legal, rngs[i], deadline)
+			}
+		}()
+	}
+	for i := range m.opts.Trees {
+		trees <- i
+	}
+	close(trees)
+	wg.Wait()
+
+	best, bestVisits := 0, -1
+	for move := range legal {
+		total := 0
+		for _, v := range visits {
+			total += v[move]
+		}
+
+		if total > bestVisits {
+			best, bestVisits = move, total
+		}
+	}
+
+	return legal[best]
+}
+
+// search grows a tree from s, whose legal moves are legal, and returns how
+// many times each of them was visited.
+func (m *mcts[S, M]) search(s S, legal []M, rng *rand.Rand, deadline time.Time) []int {
+	root := &node[S, M]{state: s, legal: legal, untried: indices(len(legal))}
+
+	for i := 0; m.opts.Iterations <= 0 || i < m.opts.Iterations; i++ {
+		if i > 0 && !deadline.IsZero() && time.Now().After(deadline) {
+			break
+		}
+
+		n := root
+		for n.isExpanded() {
+			n = n.selectChild()
+		}
+
+		over, value := m.game.Result(n.state)
+		if over {
+			// The value is for the player to move, who didn't make it here.
+			value = -value
+		} else {
+			n = m.expand(n, rng)
+			value = m.simulate(n.state, rng)
+		}
+
+		n.backpropagate(value)
+	}
+
+	visits := make([]int, len(legal))
+	for _, child := range root.children {
+		visits[child.index] = child.visits
+	}
+
+	return visits
+}
+
+// node is a position of the search tree.
+type node[S, M any] struct {
+	state    S
+	legal    []M
+	parent   *node[S, M]
+	children []*node[S, M]
+
+	// index is the index of the move leading to the node in the legal moves
+	// of its parent.
+	index int
+
+	// untried are the indices of the legal moves without a child yet.
+	untried []int
+
+	// value sums the results of the games played through the node, for the
+	// player who made its move.
+	value  float64
+	visits int
+}
+
+// indices returns the numbers from 0 to n-1.
+func indices(n int) []int {
+	is := make([]int, n)
+	for i := range is {
+		is[i] = i
+	}
+
+	return is
+}
+
+// expand adds a child to n for one of its untried moves and returns it.
+func (m *mcts[S, M]) expand(n *node[S, M], rng *rand.Rand) *node[S, M] {
+	i := rng.IntN(len(n.untried))
+	index := n.untried[i]
+	n.untried = append(n.untried[:i], n.untried[i+1:]...)
+
+	state := m.game.Play(n.state, n.legal[index])
+	legal := m.game.Legal(state)
+	child := &node[S, M]{
+		state:   state,
+		legal:   legal,
+		parent:  n,
+		index:   index,
+		untried: indices(len(legal)),
+	}
+	n.children = append(n.children, child)
+
+	return child
+}
+
+// simulate plays random moves from s until the game is over and returns its
+// result for the player who moved into s.
+func (m *mcts[S, M]) simulate(s S, rng *rand.Rand) float64 {
+	if r, ok := m.game.(Roller[S]); ok {
+		return -r.Rollout(s, rng)
+	}
+
+	sign := -1.0
+	for {
+		if over, value := m.game.Result(s); over {
+			return sign * value
+		}
+
+		moves := m.game.Legal(s)
+		s = m.game.Play(s, moves[rng.IntN(len(moves))])
+		sign = -sign
+	}
+}
+
+func (n *node[S, M]) backpropagate(value float64) {
+	for ; n != nil; n = n.parent {
+		n.visits++
+		n.value += value
+		value = -value
+	}
+}
+
+func (n *node[S, M]) isExpanded() bool {
+	return len(n.children) > 0 && len(n.untried) == 0
+}
+
+// selectChild returns the child with the highest upper confidence bound.
+func (n *node[S, M]) selectChild() *node[S, M] {
+	var selected *node[S, M]
+	best := math.Inf(-1)
+
+	for _, child := range n.children {
+		// The mean result is mapped from -1..1 to 0..1.
+		mean := (child.value/float64(child.visits) + 1) / 2
+		ucb := mean + explore*math.Sqrt(math.Log(float64(n.visits))/float64(child.visits))
+		if ucb > best {
+			selected, best = child, ucb
+		}
+	}
+
+	return selected
+}
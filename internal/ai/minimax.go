@@ -0,0 +1,119 @@
+package ai
+
+import "math"
+
+// maxEntries bounds the size of the transposition table of a search.
+const maxEntries = 1 << 20
+
+type minimax[S, M any] struct {
+	game  Game[S, M]
+	depth int
+
+	// seen is the transposition table of the current search, if the game
+	// implements Hasher.
+	hasher Hasher[S]
+	seen   map[uint64]entry
+}
+
+// bound tells how an entry of the transposition table relates to the value of
+// its position.
+type bound int
+
+const (
+	exact bound = iota
+	lower
+	upper
+)
+
+// entry is the value found for a position depth moves from the end of the
+// search.
+type entry struct {
+	depth int
+	value float64
+	bound bound
+}
+
+// NewMinimax returns a player searching depth moves ahead with alpha-beta
+// pruning, which evaluates the positions it can't see the end of with its
+// game's Evaluator. It always plays the same move in the same position, the
+// first of the best ones in the order of Legal.
+func NewMinimax[S, M any](game Game[S, M], depth int) AI[S, M] {
+	hasher, _ := game.(Hasher[S])
+	return &minimax[S, M]{game: game, depth: depth, hasher: hasher}
+}
+
+func (m *minimax[S, M]) Solve(s S) M {
+	if m.hasher != nil {
+		m.seen = map[uint64]entry{}
+		defer func() { m.seen = nil }()
+	}
+
+	var best M
+	alpha := math.Inf(-1)
+
+	for i, move := range m.game.Legal(s) {
+		value := -m.search(m.game.Play(s, move), m.depth-1, math.Inf(-1), -alpha)
+		if i == 0 || value > alpha {
+			best, alpha = move, value
+		}
+	}
+
+	return best
+}
+
+// search returns the value of s for the player to move, looking depth moves
+// ahead, or a bound of it outside of alpha and beta.
+func (m *minimax[S, M]) search(s S, depth int, alpha, beta float64) float64 {
+	if over, value := m.game.Result(s); over {
+		// Weigh the results by the depth left so that the sooner wins and
+		// the later losses are preferred.
+		return value * float64(depth+2)
+	}
+	if depth <= 0 {
+		return evaluate(m.game, s)
+	}
+
+	start := alpha
+
+	// Positions are only looked up at the depth they were stored at, since
+	// the weight of the results depends on it.
+	var key uint64
+	if m.hasher != nil {
+		key = m.hasher.Hash(s)
+		if e, ok := m.seen[key]; ok && e.depth == depth {
+			switch e.bound {
+			case exact:
+				return e.value
+			case lower:
+				alpha = max(alpha, e.value)
+			case upper:
+				beta = min(beta, e.value)
+			}
+			if alpha >= beta {
+				return e.value
+			}
+		}
+	}
+
+	best := math.Inf(-1)
+	for _, move := range m.game.Legal(s) {
+		value := -m.search(m.game.Play(s, move), depth-1, -beta, -alpha)
+		best = max(best, value)
+		alpha = max(alpha, value)
+		if alpha >= beta {
+			break
+		}
+	}
+
+	if m.hasher != nil && len(m.seen) < maxEntries {
+		e := entry{depth: depth, value: best, bound: exact}
+		if best <= start {
+			e.bound = upper
+		} else if best >= beta {
+			e.bound = lower
+		}
+		m.seen[key] = e
+	}
+
+	return best
+}
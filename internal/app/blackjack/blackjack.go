@@ -2,99 +2,47 @@ package blackjack
 
 import (
 	"fmt"
-	"math/rand"
-	"time"
+
+	"github.com/Kaamkiya/gg/internal/app/blackjack/core"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/theme"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-type Card struct {
-	Suit string
-	Rank string
+// bindings are the default keys of the player's actions.
+var bindings = []game.Binding{
+	{Action: "hit", Keys: []string{"h"}, Help: "hit"},
+	{Action: "stand", Keys: []string{"s"}, Help: "stand"},
+	{Action: "new-hand", Keys: []string{"n"}, Help: "deal a new hand"},
+	{Action: "quit", Keys: []string{"q"}, Help: "quit"},
 }
 
-type Deck []Card
-
 type model struct {
-	deck         Deck
-	playerHand   []Card
-	dealerHand   []Card
-	playerTurn   bool
-	gameOver     bool
+	opts         game.Options
+	keys         game.KeyMap
+	state        core.State
 	message      string
 	playerStyle  lipgloss.Style
 	dealerStyle  lipgloss.Style
 	defaultStyle lipgloss.Style
 }
 
-func NewDeck() Deck {
-	suits := []string{"♥", "♦", "♣", "♠"}
-	ranks := []string{"2", "3", "4", "5", "6", "7", "8", "9", "10", "J", "Q", "K", "A"}
-	deck := make(Deck, 0, 52)
-
-	for _, suit := range suits {
-		for _, rank := range ranks {
-			deck = append(deck, Card{Suit: suit, Rank: rank})
-		}
-	}
-	return deck
-}
-
-func (d Deck) Shuffle() {
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(d), func(i, j int) {
-		d[i], d[j] = d[j], d[i]
-	})
-}
-
-func (d *Deck) Draw() Card {
-	card := (*d)[0]
-	*d = (*d)[1:]
-	return card
-}
-
-func HandValue(hand []Card) int {
-	value := 0
-	aces := 0
-	for _, card := range hand {
-		switch card.Rank {
-		case "A":
-			aces++
-			value += 11
-		case "K", "Q", "J":
-			value += 10
-		default:
-			rankValue := 0
-			fmt.Sscanf(card.Rank, "%d", &rankValue)
-			value += rankValue
-		}
-	}
-
-	for value > 21 && aces > 0 {
-		value -= 10
-		aces--
-	}
-	return value
-}
-
-func initialModel() tea.Model {
-	deck := NewDeck()
-	deck.Shuffle()
-
-	playerHand := []Card{deck.Draw(), deck.Draw()}
-	dealerHand := []Card{deck.Draw(), deck.Draw()}
+// initialModel deals a new hand. Every hand of a game is dealt from the same
+// random numbers, so the seed replays the whole sequence of hands.
+func initialModel(opts game.Options) tea.Model {
+	keys := game.Keys("blackjack")
+	t := theme.Current()
 
 	return model{
-		deck:         deck,
-		playerHand:   playerHand,
-		dealerHand:   dealerHand,
-		playerTurn:   true,
-		gameOver:     false,
-		message:      "Hit (h) or Stand (s)?",
-		playerStyle:  lipgloss.NewStyle().Foreground(lipgloss.Color("99")),
-		dealerStyle:  lipgloss.NewStyle().Foreground(lipgloss.Color("208")),
-		defaultStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("255")),
+		opts:         opts,
+		keys:         keys,
+		state:        core.New(opts.Rand),
+		message:      fmt.Sprintf("Hit (%s) or Stand (%s)?", keys.Keys("hit"), keys.Keys("stand")),
+		playerStyle:  t.Players[0],
+		dealerStyle:  t.Players[1],
+		defaultStyle: t.Text,
 	}
 }
 
@@ -105,76 +53,92 @@ func (m model) Init() tea.Cmd {
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		switch m.keys.Action(msg) {
+		case "quit":
 			return m, tea.Quit
-		case "h":
-			if m.playerTurn && !m.gameOver {
-				m.playerHand = append(m.playerHand, m.deck.Draw())
-				if HandValue(m.playerHand) > 21 {
-					m.message = "Player busts! Dealer wins."
-					m.gameOver = true
-				}
+		case "hit":
+			if m.state.Step(core.Hit) == nil {
+				return m.end()
 			}
-		case "s":
-			if m.playerTurn && !m.gameOver {
-				m.playerTurn = false
-				// Dealer's turn
-				for HandValue(m.dealerHand) < 17 {
-					m.dealerHand = append(m.dealerHand, m.deck.Draw())
-				}
-
-				playerValue := HandValue(m.playerHand)
-				dealerValue := HandValue(m.dealerHand)
-
-				if dealerValue > 21 || playerValue > dealerValue {
-					m.message = "Player wins!"
-				} else if dealerValue > playerValue {
-					m.message = "Dealer wins!"
-				} else {
-					m.message = "Push (Tie)!"
-				}
-				m.gameOver = true
+		case "stand":
+			if m.state.Step(core.Stand) == nil {
+				return m.end()
 			}
-		case "n":
-			if m.gameOver {
-				return initialModel(), nil
+		case "new-hand":
+			if m.state.Outcome() != core.Playing {
+				return initialModel(m.opts), nil
 			}
 		}
 	}
 	return m, nil
 }
 
+// end tells the player how the hand ended, if it has, and reports it.
+func (m model) end() (tea.Model, tea.Cmd) {
+	playerValue := core.HandValue(m.state.Player)
+	dealerValue := core.HandValue(m.state.Dealer)
+
+	var cmds []tea.Cmd
+	outcome := game.Draw
+	switch m.state.Outcome() {
+	case core.Playing:
+		return m, nil
+	case core.Bust:
+		m.message = "Player busts! Dealer wins."
+		return m, game.Over(game.Result{Outcome: game.Loss, Summary: "bust"})
+	case core.Win:
+		m.message = "Player wins!"
+		outcome = game.Win
+		cmds = append(cmds, game.Publish("win", len(m.state.Player)))
+	case core.Loss:
+		m.message = "Dealer wins!"
+		outcome = game.Loss
+	case core.Push:
+		m.message = "Push (Tie)!"
+	}
+
+	cmds = append(cmds, game.Over(game.Result{
+		Outcome: outcome,
+		Summary: fmt.Sprintf("%d against %d", playerValue, dealerValue),
+	}))
+	return m, tea.Batch(cmds...)
+}
+
 func (m model) View() string {
 	s := "Blackjack\n\n"
 
 	s += m.dealerStyle.Render("Dealer's Hand:\n")
-	if m.playerTurn && !m.gameOver {
-		s += fmt.Sprintf("[ %s %s ] [ ? ]\n", m.dealerHand[0].Rank, m.dealerHand[0].Suit)
+	over := m.state.Outcome() != core.Playing
+	if !over {
+		s += fmt.Sprintf("[ %s %s ] [ ? ]\n", m.state.Dealer[0].Rank, m.state.Dealer[0].Suit)
 	} else {
-		for _, card := range m.dealerHand {
+		for _, card := range m.state.Dealer {
 			s += fmt.Sprintf("[ %s %s ] ", card.Rank, card.Suit)
 		}
-		s += fmt.Sprintf(" (Value: %d)\n", HandValue(m.dealerHand))
+		s += fmt.Sprintf(" (Value: %d)\n", core.HandValue(m.state.Dealer))
 	}
 
 	s += "\n" + m.playerStyle.Render("Player's Hand:\n")
-	for _, card := range m.playerHand {
+	for _, card := range m.state.Player {
 		s += fmt.Sprintf("[ %s %s ] ", card.Rank, card.Suit)
 	}
-	s += fmt.Sprintf(" (Value: %d)\n", HandValue(m.playerHand))
+	s += fmt.Sprintf(" (Value: %d)\n", core.HandValue(m.state.Player))
 
 	s += "\n" + m.defaultStyle.Render(m.message) + "\n"
-	if m.gameOver {
-		s += "\nPress 'q' to quit or 'n' to start a new game.\n"
+	if over {
+		s += fmt.Sprintf("\nPress '%s' to quit or '%s' to start a new game.\nSeed: %d\n", m.keys.Keys("quit"), m.keys.Keys("new-hand"), m.opts.Seed)
 	}
 
 	return s
 }
 
-func Run() {
-	p := tea.NewProgram(initialModel())
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Alas, there's been an error: %v", err)
-	}
+func init() {
+	game.Register(game.Game{
+		ID:          "blackjack",
+		Name:        "blackjack",
+		Players:     1,
+		Description: "Beat the dealer to 21 without going bust.",
+		Keys:        bindings,
+		New:         initialModel,
+	})
 }
@@ -0,0 +1,161 @@
+// Package core holds the rules of blackjack, free of any rendering, so that
+// they can be tested on their own and played by bots.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+)
+
+type Card struct {
+	Suit string
+	Rank string
+}
+
+type Deck []Card
+
+func NewDeck() Deck {
+	suits := []string{"♥", "♦", "♣", "♠"}
+	ranks := []string{"2", "3", "4", "5", "6", "7", "8", "9", "10", "J", "Q", "K", "A"}
+	deck := make(Deck, 0, 52)
+
+	for _, suit := range suits {
+		for _, rank := range ranks {
+			deck = append(deck, Card{Suit: suit, Rank: rank})
+		}
+	}
+	return deck
+}
+
+func (d Deck) Shuffle(r *rand.Rand) {
+	r.Shuffle(len(d), func(i, j int) {
+		d[i], d[j] = d[j], d[i]
+	})
+}
+
+func (d *Deck) Draw() Card {
+	card := (*d)[0]
+	*d = (*d)[1:]
+	return card
+}
+
+func HandValue(hand []Card) int {
+	value := 0
+	aces := 0
+	for _, card := range hand {
+		switch card.Rank {
+		case "A":
+			aces++
+			value += 11
+		case "K", "Q", "J":
+			value += 10
+		default:
+			rankValue := 0
+			fmt.Sscanf(card.Rank, "%d", &rankValue)
+			value += rankValue
+		}
+	}
+
+	for value > 21 && aces > 0 {
+		value -= 10
+		aces--
+	}
+	return value
+}
+
+// Action is a move of the player.
+type Action int
+
+const (
+	Hit Action = iota
+	Stand
+)
+
+// Outcome tells how the hand ended, if it has.
+type Outcome int
+
+const (
+	Playing Outcome = iota
+
+	// Bust is the outcome once the player has gone over 21.
+	Bust
+
+	Win
+	Loss
+	Push
+)
+
+// State is a hand of blackjack.
+type State struct {
+	Deck   Deck
+	Player []Card
+	Dealer []Card
+
+	// Stood is set once the player has stood and the dealer has played.
+	Stood bool
+}
+
+// New shuffles the deck with r and deals two cards to the player and two to
+// the dealer.
+func New(r *rand.Rand) State {
+	deck := NewDeck()
+	deck.Shuffle(r)
+
+	s := State{Deck: deck}
+	s.Player = []Card{s.Deck.Draw(), s.Deck.Draw()}
+	s.Dealer = []Card{s.Deck.Draw(), s.Deck.Draw()}
+	return s
+}
+
+// Legal returns the player's moves, none once the hand is over.
+func (s State) Legal() []Action {
+	if s.Outcome() != Playing {
+		return nil
+	}
+
+	return []Action{Hit, Stand}
+}
+
+// Step plays the player's move. Standing lets the dealer draw up to 17.
+func (s *State) Step(a Action) error {
+	if s.Outcome() != Playing {
+		return errors.New("blackjack: the hand is over")
+	}
+
+	switch a {
+	case Hit:
+		s.Player = append(s.Player, s.Deck.Draw())
+	case Stand:
+		s.Stood = true
+		for HandValue(s.Dealer) < 17 {
+			s.Dealer = append(s.Dealer, s.Deck.Draw())
+		}
+	default:
+		return errors.New("blackjack: unknown action")
+	}
+
+	return nil
+}
+
+// Outcome returns how the hand ended for the player, or Playing while it goes
+// on.
+func (s State) Outcome() Outcome {
+	player := HandValue(s.Player)
+	if player > 21 {
+		return Bust
+	}
+	if !s.Stood {
+		return Playing
+	}
+
+	dealer := HandValue(s.Dealer)
+	switch {
+	case dealer > 21 || player > dealer:
+		return Win
+	case dealer > player:
+		return Loss
+	}
+
+	return Push
+}
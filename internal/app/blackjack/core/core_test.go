@@ -0,0 +1,71 @@
+package core
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func hand(ranks ...string) []Card {
+	var cards []Card
+	for _, r := range ranks {
+		cards = append(cards, Card{Suit: "♠", Rank: r})
+	}
+	return cards
+}
+
+func TestHandValue(t *testing.T) {
+	tests := []struct {
+		hand []Card
+		want int
+	}{
+		{hand("K", "7"), 17},
+		{hand("A", "K"), 21},
+		{hand("A", "A", "9"), 21},
+		{hand("A", "9", "5"), 15},
+		{hand("10", "Q", "2"), 22},
+	}
+
+	for _, tt := range tests {
+		if got := HandValue(tt.hand); got != tt.want {
+			t.Errorf("HandValue(%v) = %d, want %d", tt.hand, got, tt.want)
+		}
+	}
+}
+
+func TestOutcome(t *testing.T) {
+	tests := []struct {
+		name  string
+		state State
+		want  Outcome
+	}{
+		{"playing", State{Player: hand("K", "7"), Dealer: hand("9", "9")}, Playing},
+		{"bust", State{Player: hand("K", "7", "5"), Dealer: hand("9", "9")}, Bust},
+		{"win", State{Player: hand("K", "9"), Dealer: hand("9", "9"), Stood: true}, Win},
+		{"dealer bust", State{Player: hand("K", "2"), Dealer: hand("9", "7", "8"), Stood: true}, Win},
+		{"loss", State{Player: hand("K", "7"), Dealer: hand("9", "9"), Stood: true}, Loss},
+		{"push", State{Player: hand("K", "8"), Dealer: hand("9", "9"), Stood: true}, Push},
+	}
+
+	for _, tt := range tests {
+		if got := tt.state.Outcome(); got != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestStand(t *testing.T) {
+	s := New(rand.New(rand.NewPCG(1, 2)))
+	if len(s.Deck) != 48 || len(s.Legal()) != 2 {
+		t.Fatalf("expected two cards each and two moves, got %d cards left and %v", len(s.Deck), s.Legal())
+	}
+
+	if err := s.Step(Stand); err != nil {
+		t.Fatal(err)
+	}
+	if HandValue(s.Dealer) < 17 {
+		t.Errorf("expected the dealer to draw up to 17, got %v", s.Dealer)
+	}
+	if s.Legal() != nil || s.Step(Hit) == nil {
+		t.Error("expected no move once the player has stood")
+	}
+}
@@ -0,0 +1,200 @@
+package connect4
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/ai"
+	"github.com/Kaamkiya/gg/internal/app/connect4/core"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// levels are the strengths of the computer, from the weakest.
+var levels = []string{"easy", "medium", "hard"}
+
+// newAI returns the computer player of the given level. The easy one only
+// looks at its next move, the others search with the bitboards of the core,
+// the hard one deep enough to take about a tenth of a second.
+func newAI(level string, opts game.Options) ai.AI[core.Bits, int] {
+	switch level {
+	case "easy":
+		return ai.NewGreedy[core.Bits, int](core.BitRules{}, opts.Rand)
+	case "medium":
+		return ai.NewMinimax[core.Bits, int](core.BitRules{}, 4)
+	}
+
+	return ai.NewMinimax[core.Bits, int](core.BitRules{}, 10)
+}
+
+func flags(fs *flag.FlagSet) func(game.Options) (tea.Model, error) {
+	level := game.LevelFlag(fs, levels)
+
+	return func(opts game.Options) (tea.Model, error) {
+		l, err := level()
+		switch {
+		case err != nil:
+			return nil, err
+		case l == "":
+			return newSetup(opts), nil
+		}
+
+		return newComputerModel(l, opts), nil
+	}
+}
+
+// newSetup asks for the level of the computer before the game starts.
+func newSetup(opts game.Options) tea.Model {
+	return game.PickLevel(levels, func(level string) tea.Model {
+		return newComputerModel(level, opts)
+	})
+}
+
+// computerModel plays against the computer, which plays o.
+type computerModel struct {
+	level string
+	state core.State
+	ai    ai.AI[core.Bits, int]
+	keys  game.KeyMap
+
+	// history holds the positions before the player's moves, so that undoing
+	// takes back the computer's reply along with the move.
+	history game.History[core.State]
+
+	// thinking is set while the computer picks its move.
+	thinking bool
+
+	xStyle     lipgloss.Style
+	oStyle     lipgloss.Style
+	mutedStyle lipgloss.Style
+}
+
+func newComputerModel(level string, opts game.Options) tea.Model {
+	t := theme.Current()
+
+	return computerModel{
+		level:      level,
+		state:      core.New(),
+		ai:         newAI(level, opts),
+		keys:       game.Keys("connect4-ai"),
+		history:    game.NewHistory[core.State](game.UnlimitedUndo),
+		xStyle:     t.Players[0],
+		oStyle:     t.Players[1],
+		mutedStyle: t.Muted,
+	}
+}
+
+func (m computerModel) Init() tea.Cmd {
+	return nil
+}
+
+// moveMsg carries the column the computer drops its piece in.
+type moveMsg struct{ col int }
+
+func (m computerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case moveMsg:
+		m.thinking = false
+		return m.drop(msg.col)
+
+	case tea.KeyMsg:
+		action := m.keys.Action(msg)
+		if action == "quit" {
+			return m, tea.Quit
+		}
+
+		// The board can't be changed while the computer thinks or once the
+		// game is over.
+		if m.thinking || m.state.Outcome() != core.Empty {
+			break
+		}
+
+		switch action {
+		case "undo":
+			m.state, _ = m.history.Undo(m.state)
+		case "redo":
+			m.state, _ = m.history.Redo(m.state)
+		}
+
+		if n, ok := strings.CutPrefix(action, columnPrefix); ok {
+			// The actions are named after the columns' numbers, from 1.
+			col, _ := strconv.Atoi(n)
+			if slices.Contains(m.state.Legal(), col-1) {
+				m.history.Record(m.state)
+			}
+			return m.drop(col - 1)
+		}
+	}
+
+	return m, nil
+}
+
+// drop plays in col for the player to move, then lets the computer think or
+// ends the game.
+func (m computerModel) drop(col int) (tea.Model, tea.Cmd) {
+	// Full columns and moves after the end are refused.
+	if err := m.state.Step(col); err != nil {
+		return m, nil
+	}
+
+	if winner := m.state.Outcome(); winner != core.Empty {
+		r := m.result(winner)
+		r.Undos = m.history.Undos()
+		return m, game.Over(r)
+	}
+
+	if m.state.Turn == core.O {
+		m.thinking = true
+		return m, m.think()
+	}
+
+	return m, nil
+}
+
+// think searches the computer's move away from the UI, which keeps drawing
+// and taking keys meanwhile.
+func (m computerModel) think() tea.Cmd {
+	bits, player := core.BitsOf(m.state), m.ai
+	return func() tea.Msg {
+		return moveMsg{player.Solve(bits)}
+	}
+}
+
+// result reports the outcome of the game for the player.
+func (m computerModel) result(winner rune) game.Result {
+	switch winner {
+	case core.X:
+		return game.Result{Outcome: game.Win, Summary: "beat the " + m.level + " computer"}
+	case core.O:
+		return game.Result{Outcome: game.Loss, Summary: "lost to the " + m.level + " computer"}
+	}
+
+	return game.Result{Outcome: game.Draw, Summary: "tie against the " + m.level + " computer"}
+}
+
+func (m computerModel) View() string {
+	s := drawBoard(m.state, m.xStyle, m.oStyle)
+
+	switch winner := m.state.Outcome(); winner {
+	case core.Empty:
+		if m.thinking {
+			s += "\n" + m.mutedStyle.Render("thinking…") + "\n"
+		} else {
+			s += "\nyour turn\n"
+		}
+	case core.Tie:
+		s += fmt.Sprintf("\ntie! Press %s to quit.\n", m.keys.Keys("quit"))
+	case core.X:
+		s += fmt.Sprintf("\nyou win! Press %s to quit.\n", m.keys.Keys("quit"))
+	default:
+		s += fmt.Sprintf("\nthe computer wins! Press %s to quit.\n", m.keys.Keys("quit"))
+	}
+
+	return s + m.mutedStyle.Render(fmt.Sprintf("level: %s", m.level))
+}
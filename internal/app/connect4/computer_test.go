@@ -0,0 +1,52 @@
+package connect4
+
+import (
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/connect4/core"
+	"github.com/Kaamkiya/gg/internal/game"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func key(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestUndoTakesBackTheComputersReply(t *testing.T) {
+	var m tea.Model = newComputerModel("easy", game.Seeded(1))
+
+	m, cmd := m.Update(key("4"))
+	if cmd == nil {
+		t.Fatal("expected the computer to think about its reply")
+	}
+	m, _ = m.Update(cmd())
+
+	played := m.(computerModel).state
+	if pieces(played) != 2 || played.Turn != core.X {
+		t.Fatal("expected the computer to have replied")
+	}
+
+	m, _ = m.Update(key("u"))
+	if m.(computerModel).state != core.New() {
+		t.Error("expected the move and the reply to have been taken back")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if m.(computerModel).state != played {
+		t.Error("expected the move and the reply to have been played again")
+	}
+}
+
+// pieces counts the pieces on the board of s.
+func pieces(s core.State) int {
+	n := 0
+	for _, row := range s.Board {
+		for _, piece := range row {
+			if piece != core.Empty {
+				n++
+			}
+		}
+	}
+	return n
+}
@@ -3,32 +3,49 @@ package connect4
 import (
 	"fmt"
 	"strconv"
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/app/connect4/core"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/theme"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// columnPrefix starts the names of the actions dropping a piece, which end
+// with the number of the column.
+const columnPrefix = "column-"
+
+// bindings returns the default keys of the players' actions.
+func bindings() []game.Binding {
+	var b []game.Binding
+	for col := 1; col <= 7; col++ {
+		n := strconv.Itoa(col)
+		b = append(b, game.Binding{Action: columnPrefix + n, Keys: []string{n}, Help: "drop in column " + n})
+	}
+	b = append(b, game.UndoBindings...)
+	return append(b, game.Binding{Action: "quit", Keys: []string{"q"}, Help: "quit"})
+}
+
 type model struct {
-	board [6][7]rune // [y][x]
-	turn  rune
+	state   core.State
+	keys    game.KeyMap
+	history game.History[core.State]
 
 	xStyle lipgloss.Style
 	oStyle lipgloss.Style
 }
 
-func initialModel() tea.Model {
-	board := [6][7]rune{}
-	for y := range board {
-		for x := range board[y] {
-			board[y][x] = ' '
-		}
-	}
+func initialModel(game.Options) tea.Model {
+	t := theme.Current()
 
 	return model{
-		board:  board,
-		turn:   'x',
-		xStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
-		oStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("9")),
+		state:   core.New(),
+		keys:    game.Keys("connect4"),
+		history: game.NewHistory[core.State](game.UnlimitedUndo),
+		xStyle:  t.Players[0],
+		oStyle:  t.Players[1],
 	}
 }
 
@@ -39,156 +56,114 @@ func (m model) Init() tea.Cmd {
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		action := m.keys.Action(msg)
+		if action == "quit" {
 			return m, tea.Quit
-		case "1", "2", "3", "4", "5", "6", "7":
-			/* Don't check for errors because there can't be one.
-			 * This only gets called if an integer was inputted.
-			 */
-			col, _ := strconv.Atoi(msg.String())
-			col-- // Go is 0 indexed, inputs are not.
-
-			// A piece can only go in that column if it's not full.
-			if m.board[0][col] == ' ' {
-				for y := len(m.board) - 1; y >= 0; y-- {
-					if m.board[y][col] == ' ' {
-						m.board[y][col] = m.turn
-
-						if m.turn == 'x' {
-							m.turn = 'o'
-						} else {
-							m.turn = 'x'
-						}
-
-						break
-					}
-				}
-			}
 		}
-	}
-
-	if m.CheckForWin() != ' ' {
-		return m, tea.Quit
-	}
 
-	return m, nil
-}
+		// The board can't be changed once the game is over.
+		if m.state.Outcome() != core.Empty {
+			break
+		}
 
-func (m model) View() string {
-	s := "| 1 | 2 | 3 | 4 | 5 | 6 | 7 |\n"
-	s += "+---------------------------+\n"
+		switch action {
+		case "undo":
+			m.state, _ = m.history.Undo(m.state)
+		case "redo":
+			m.state, _ = m.history.Redo(m.state)
+		}
 
-	for _, row := range m.board {
-		s += "| "
-		for _, cell := range row {
-			style := m.oStyle
+		if n, ok := strings.CutPrefix(action, columnPrefix); ok {
+			/* Don't check for errors because there can't be one.
+			 * The actions are named after the columns' numbers.
+			 */
+			col, _ := strconv.Atoi(n)
+			col-- // Go is 0 indexed, the columns' numbers are not.
 
-			if cell == 'x' {
-				style = m.xStyle
+			// Full columns are refused.
+			before := m.state
+			if err := m.state.Step(col); err != nil {
+				break
 			}
+			m.history.Record(before)
 
-			s += style.Render(string(cell)) + " | "
+			if winner := m.state.Outcome(); winner != core.Empty {
+				r := result(winner)
+				r.Undos = m.history.Undos()
+				return m, game.Over(r)
+			}
 		}
-		s += "\n"
 	}
 
-	s += "+---------------------------+\n"
+	return m, nil
+}
 
-	switch m.CheckForWin() {
-	case ' ':
-		s += fmt.Sprintf("\n%c's turn\n", m.turn)
-	case 't':
-		s += "\ntie!\n"
-	default:
-		s += fmt.Sprintf("\n%c wins!\n", m.CheckForWin())
+// result reports the outcome of the game for x, who plays first.
+func result(winner rune) game.Result {
+	switch winner {
+	case core.X:
+		return game.Result{Outcome: game.Win, Summary: "x wins"}
+	case core.O:
+		return game.Result{Outcome: game.Loss, Summary: "o wins"}
 	}
 
-	return s
+	return game.Result{Outcome: game.Draw, Summary: "tie"}
 }
 
-func (m model) CheckForWin() rune {
-	// Check for a win horizontally.
-	for y := 0; y < len(m.board[0]); y++ {
-		for x := 0; x < len(m.board)-3; x++ {
-			tile1 := m.board[x][y]
-			tile2 := m.board[x+1][y]
-			tile3 := m.board[x+2][y]
-			tile4 := m.board[x+3][y]
-			if tile1 == 'x' && tile2 == 'x' && tile3 == 'x' && tile4 == 'x' {
-				return 'x'
-			}
-			if tile1 == 'o' && tile2 == 'o' && tile3 == 'o' && tile4 == 'o' {
-				return 'o'
-			}
-		}
-	}
+func (m model) View() string {
+	s := drawBoard(m.state, m.xStyle, m.oStyle)
 
-	// Check for a win vertically.
-	for y := 0; y < len(m.board[0])-3; y++ {
-		for x := 0; x < len(m.board); x++ {
-			tile1 := m.board[x][y]
-			tile2 := m.board[x][y+1]
-			tile3 := m.board[x][y+2]
-			tile4 := m.board[x][y+3]
-			if tile1 == 'x' && tile2 == 'x' && tile3 == 'x' && tile4 == 'x' {
-				return 'x'
-			}
-			if tile1 == 'o' && tile2 == 'o' && tile3 == 'o' && tile4 == 'o' {
-				return 'o'
-			}
-		}
+	switch winner := m.state.Outcome(); winner {
+	case core.Empty:
+		s += fmt.Sprintf("\n%c's turn\n", m.state.Turn)
+	case core.Tie:
+		s += fmt.Sprintf("\ntie! Press %s to quit.\n", m.keys.Keys("quit"))
+	default:
+		s += fmt.Sprintf("\n%c wins! Press %s to quit.\n", winner, m.keys.Keys("quit"))
 	}
 
-	for y := 0; y < len(m.board[0])-3; y++ {
-		for x := 0; x < len(m.board)-3; x++ {
-			// Check left down diagonal.
-			tile1 := m.board[x][y]
-			tile2 := m.board[x+1][y+1]
-			tile3 := m.board[x+2][y+2]
-			tile4 := m.board[x+3][y+3]
-			if tile1 == 'x' && tile2 == 'x' && tile3 == 'x' && tile4 == 'x' {
-				return 'x'
-			}
-			if tile1 == 'o' && tile2 == 'o' && tile3 == 'o' && tile4 == 'o' {
-				return 'o'
-			}
-
-			// Check right down diagonal.
-			tile1 = m.board[x+3][y]
-			tile2 = m.board[x+2][y+1]
-			tile3 = m.board[x+1][y+2]
-			tile4 = m.board[x][y+3]
-			if tile1 == 'x' && tile2 == 'x' && tile3 == 'x' && tile4 == 'x' {
-				return 'x'
-			}
-			if tile1 == 'o' && tile2 == 'o' && tile3 == 'o' && tile4 == 'o' {
-				return 'o'
-			}
-		}
-	}
+	return s
+}
 
-	emptyCount := len(m.board) * len(m.board[0])
+// drawBoard draws the board of s with the pieces of x and o in their styles.
+func drawBoard(s core.State, xStyle, oStyle lipgloss.Style) string {
+	b := "| 1 | 2 | 3 | 4 | 5 | 6 | 7 |\n"
+	b += "+---------------------------+\n"
 
-	for _, row := range m.board {
+	for _, row := range s.Board {
+		b += "| "
 		for _, cell := range row {
-			if cell != ' ' {
-				emptyCount--
+			style := oStyle
+
+			if cell == core.X {
+				style = xStyle
 			}
-		}
-	}
 
-	if emptyCount <= 0 {
-		return 't'
+			b += style.Render(string(cell)) + " | "
+		}
+		b += "\n"
 	}
 
-	return ' '
+	return b + "+---------------------------+\n"
 }
 
-func Run() {
-	p := tea.NewProgram(initialModel())
-
-	if _, err := p.Run(); err != nil {
-		panic(err)
-	}
+func init() {
+	game.Register(game.Game{
+		ID:          "connect4",
+		Name:        "connect 4",
+		Players:     2,
+		Description: "Drop pieces to line up four in a row.",
+		Keys:        bindings(),
+		New:         initialModel,
+	})
+
+	game.Register(game.Game{
+		ID:          "connect4-ai",
+		Name:        "connect 4 (vs AI)",
+		Players:     1,
+		Description: "Drop pieces to line up four in a row before the computer does.",
+		Keys:        bindings(),
+		New:         newSetup,
+		Flags:       flags,
+	})
 }
@@ -0,0 +1,157 @@
+package core
+
+import "math/bits"
+
+// Bits is a position of the game packed in two bitboards, which the computer
+// players search much faster than a State. Each column takes Rows+1 bits,
+// from the bottom up, the last one staying free so that lines can't wrap
+// from one column to the next.
+type Bits struct {
+	// Current has the pieces of the player to move.
+	Current uint64
+
+	// Mask has the pieces of both players.
+	Mask uint64
+
+	// Moves is the number of pieces on the board.
+	Moves int
+}
+
+// height is the number of bits of a column.
+const height = Rows + 1
+
+// order lists the columns from the middle out, which are usually the best
+// moves, so that searches cut the other ones short sooner.
+var order = [Cols]int{3, 2, 4, 1, 5, 0, 6}
+
+// windows are the masks of every four squares in a row.
+var windows []uint64
+
+// middle is the mask of the squares of the middle column.
+var middle = (uint64(1)<<Rows - 1) << (Cols / 2 * height)
+
+func init() {
+	square := func(y, x int) uint64 { return 1 << (x*height + y) }
+	for y := range Rows {
+		for x := range Cols {
+			for _, d := range [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}} {
+				if y+3*d[0] >= Rows || x+3*d[1] < 0 || x+3*d[1] >= Cols {
+					continue
+				}
+
+				var w uint64
+				for i := range 4 {
+					w |= square(y+i*d[0], x+i*d[1])
+				}
+				windows = append(windows, w)
+			}
+		}
+	}
+}
+
+// BitsOf packs the position s.
+func BitsOf(s State) Bits {
+	var b Bits
+	for y := range Rows {
+		for x := range Cols {
+			p := s.Board[Rows-1-y][x]
+			if p == Empty {
+				continue
+			}
+
+			bit := uint64(1) << (x*height + y)
+			b.Mask |= bit
+			b.Moves++
+			if p == s.Turn {
+				b.Current |= bit
+			}
+		}
+	}
+
+	return b
+}
+
+// bottom returns the mask of the lowest square of col.
+func bottom(col int) uint64 {
+	return 1 << (col * height)
+}
+
+// top returns the mask of the highest square of col.
+func top(col int) uint64 {
+	return 1 << (col*height + Rows - 1)
+}
+
+// aligned tells whether the pieces of pos have four in a row.
+func aligned(pos uint64) bool {
+	// Horizontal, both diagonals and vertical.
+	for _, shift := range []int{height, height - 1, height + 1, 1} {
+		m := pos & (pos >> shift)
+		if m&(m>>(2*shift)) != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BitRules describes connect 4 on Bits to the computer players of package ai,
+// with the columns as moves.
+type BitRules struct{}
+
+func (r BitRules) Legal(b Bits) []int {
+	if over, _ := r.Result(b); over {
+		return nil
+	}
+
+	cols := make([]int, 0, Cols)
+	for _, col := range order {
+		if b.Mask&top(col) == 0 {
+			cols = append(cols, col)
+		}
+	}
+
+	return cols
+}
+
+func (BitRules) Play(b Bits, col int) Bits {
+	b.Current ^= b.Mask
+	b.Mask |= b.Mask + bottom(col)
+	b.Moves++
+	return b
+}
+
+func (BitRules) Result(b Bits) (bool, float64) {
+	// Only the player who just moved can have four in a row.
+	if aligned(b.Current ^ b.Mask) {
+		return true, -1
+	}
+	if b.Moves == Rows*Cols {
+		return true, 0
+	}
+
+	return false, 0
+}
+
+// Evaluate scores the position like Rules does.
+func (BitRules) Evaluate(b Bits) float64 {
+	mine, theirs := b.Current, b.Current^b.Mask
+
+	score := 0
+	for _, w := range windows {
+		m, t := bits.OnesCount64(mine&w), bits.OnesCount64(theirs&w)
+		if t == 0 {
+			score += weights[m]
+		} else if m == 0 {
+			score -= weights[t]
+		}
+	}
+	score += 3 * (bits.OnesCount64(mine&middle) - bits.OnesCount64(theirs&middle))
+
+	return max(-1, min(1, float64(score)/200))
+}
+
+// Hash returns a key telling the positions apart, for the transposition
+// table of the search.
+func (BitRules) Hash(b Bits) uint64 {
+	return b.Current + b.Mask
+}
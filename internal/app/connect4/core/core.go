@@ -0,0 +1,153 @@
+// Package core holds the rules of connect 4, free of any rendering, so that
+// they can be tested on their own and played by bots.
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	Rows = 6
+	Cols = 7
+)
+
+// The pieces of the players and the marks of the board. Tie is only ever
+// returned by Outcome.
+const (
+	Empty = ' '
+	X     = 'x'
+	O     = 'o'
+	Tie   = 't'
+)
+
+// State is a position of the game.
+type State struct {
+	Board [Rows][Cols]rune // [y][x]
+
+	// Turn is the piece of the player to move. X moves first.
+	Turn rune
+}
+
+// New returns the empty board, with X to move.
+func New() State {
+	s := State{Turn: X}
+	for y := range s.Board {
+		for x := range s.Board[y] {
+			s.Board[y][x] = Empty
+		}
+	}
+
+	return s
+}
+
+// Legal returns the columns, counted from 0, a piece can be dropped in. There
+// are none once the game is over.
+func (s State) Legal() []int {
+	if s.Outcome() != Empty {
+		return nil
+	}
+
+	var cols []int
+	for x := range Cols {
+		if s.Board[0][x] == Empty {
+			cols = append(cols, x)
+		}
+	}
+
+	return cols
+}
+
+// Step drops a piece of the player to move in column col, counted from 0, and
+// passes the turn to the other player.
+func (s *State) Step(col int) error {
+	if s.Outcome() != Empty {
+		return errors.New("connect4: the game is over")
+	}
+	if col < 0 || col >= Cols {
+		return fmt.Errorf("connect4: no column %d", col)
+	}
+	if s.Board[0][col] != Empty {
+		return fmt.Errorf("connect4: column %d is full", col)
+	}
+
+	for y := Rows - 1; y >= 0; y-- {
+		if s.Board[y][col] == Empty {
+			s.Board[y][col] = s.Turn
+			break
+		}
+	}
+
+	s.Turn = Other(s.Turn)
+	return nil
+}
+
+// Other returns the piece of the opponent of the player with piece p.
+func Other(p rune) rune {
+	if p == X {
+		return O
+	}
+
+	return X
+}
+
+// Outcome returns the piece of the player with four in a row, Tie once the
+// board is full without one, or Empty while the game goes on.
+func (s State) Outcome() rune {
+	// Check for a win vertically.
+	for x := range Cols {
+		for y := range Rows - 3 {
+			if w := s.line(y, x, 1, 0); w != Empty {
+				return w
+			}
+		}
+	}
+
+	// Check for a win horizontally.
+	for y := range Rows {
+		for x := range Cols - 3 {
+			if w := s.line(y, x, 0, 1); w != Empty {
+				return w
+			}
+		}
+	}
+
+	for y := range Rows - 3 {
+		for x := range Cols - 3 {
+			// Check the diagonal going down to the right.
+			if w := s.line(y, x, 1, 1); w != Empty {
+				return w
+			}
+
+			// Check the diagonal going down to the left.
+			if w := s.line(y, x+3, 1, -1); w != Empty {
+				return w
+			}
+		}
+	}
+
+	for x := range Cols {
+		if s.Board[0][x] == Empty {
+			return Empty
+		}
+	}
+
+	return Tie
+}
+
+// line returns the piece filling the four squares from y, x in the direction
+// dy, dx, or Empty if they don't all hold the same piece.
+func (s State) line(y, x, dy, dx int) rune {
+	p := s.Board[y][x]
+	if p == Empty {
+		return Empty
+	}
+
+	for i := 1; i < 4; i++ {
+		if s.Board[y+i*dy][x+i*dx] != p {
+			return Empty
+		}
+	}
+
+	return p
+}
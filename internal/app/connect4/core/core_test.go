@@ -0,0 +1,86 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// play drops pieces in the given columns in turn, starting with X.
+func play(t *testing.T, cols ...int) State {
+	t.Helper()
+
+	s := New()
+	for _, col := range cols {
+		if err := s.Step(col); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func TestOutcome(t *testing.T) {
+	tests := []struct {
+		name string
+		cols []int
+		want rune
+	}{
+		{"empty", nil, Empty},
+		{"vertical", []int{0, 1, 0, 1, 0, 1, 0}, X},
+		{"horizontal", []int{6, 0, 6, 1, 5, 2, 5, 3}, O},
+		{"diagonal", []int{0, 1, 1, 2, 2, 3, 2, 3, 3, 6, 3}, X},
+		{"anti-diagonal", []int{6, 5, 5, 4, 4, 3, 4, 3, 3, 0, 3}, X},
+	}
+
+	for _, tt := range tests {
+		if got := play(t, tt.cols...).Outcome(); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestTie(t *testing.T) {
+	// Columns are filled in pairs in an order that never lines up four.
+	var cols []int
+	for _, pair := range [][2]int{{0, 1}, {2, 3}, {4, 5}} {
+		for range 3 {
+			cols = append(cols, pair[0], pair[1])
+		}
+		for range 3 {
+			cols = append(cols, pair[1], pair[0])
+		}
+	}
+	for range Rows {
+		cols = append(cols, 6)
+	}
+
+	s := play(t, cols...)
+	if got := s.Outcome(); got != Tie {
+		t.Fatalf("expected a tie, got %q\n%v", got, s.Board)
+	}
+	if legal := s.Legal(); legal != nil {
+		t.Errorf("expected no legal moves on a full board, got %v", legal)
+	}
+}
+
+func TestStep(t *testing.T) {
+	s := play(t, 3, 3)
+	if s.Board[Rows-1][3] != X || s.Board[Rows-2][3] != O || s.Turn != X {
+		t.Errorf("expected pieces to stack up in turn, got %v", s.Board)
+	}
+
+	s = play(t, 2, 2, 2, 2, 2, 2)
+	if err := s.Step(2); err == nil {
+		t.Error("expected a full column to be refused")
+	}
+	if err := s.Step(Cols); err == nil {
+		t.Error("expected a column off the board to be refused")
+	}
+	if want := []int{0, 1, 3, 4, 5, 6}; !reflect.DeepEqual(s.Legal(), want) {
+		t.Errorf("expected the legal columns %v, got %v", want, s.Legal())
+	}
+
+	s = play(t, 0, 1, 0, 1, 0, 1, 0)
+	if err := s.Step(4); err == nil {
+		t.Error("expected no move after the game is won")
+	}
+}
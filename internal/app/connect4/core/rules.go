@@ -0,0 +1,82 @@
+package core
+
+// Rules describes connect 4 to the computer players of package ai, with the
+// columns as moves.
+type Rules struct{}
+
+func (Rules) Legal(s State) []int {
+	return s.Legal()
+}
+
+func (Rules) Play(s State, col int) State {
+	s.Step(col)
+	return s
+}
+
+func (Rules) Result(s State) (bool, float64) {
+	switch s.Outcome() {
+	case Empty:
+		return false, 0
+	case Tie:
+		return true, 0
+	case s.Turn:
+		return true, 1
+	default:
+		return true, -1
+	}
+}
+
+// Evaluate scores every four squares in a row that only one player has
+// pieces in, the more pieces the better, and the pieces in the middle
+// column, which are part of the most rows.
+func (Rules) Evaluate(s State) float64 {
+	score := 0
+	window := func(y, x, dy, dx int) {
+		mine, theirs := 0, 0
+		for i := range 4 {
+			switch s.Board[y+i*dy][x+i*dx] {
+			case s.Turn:
+				mine++
+			case Other(s.Turn):
+				theirs++
+			}
+		}
+
+		if theirs == 0 {
+			score += weights[mine]
+		} else if mine == 0 {
+			score -= weights[theirs]
+		}
+	}
+
+	for y := range Rows {
+		for x := range Cols {
+			if x+3 < Cols {
+				window(y, x, 0, 1)
+			}
+			if y+3 < Rows {
+				window(y, x, 1, 0)
+				if x+3 < Cols {
+					window(y, x, 1, 1)
+				}
+				if x-3 >= 0 {
+					window(y, x, 1, -1)
+				}
+			}
+		}
+
+		switch s.Board[y][Cols/2] {
+		case s.Turn:
+			score += 3
+		case Other(s.Turn):
+			score -= 3
+		}
+	}
+
+	// Keep the estimate between -1 and 1, below the value of a win.
+	return max(-1, min(1, float64(score)/200))
+}
+
+// weights score four squares in a row by the number of pieces of a single
+// player in them.
+var weights = [4]int{0, 1, 4, 16}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/ai"
+)
+
+func TestRulesWinsAndBlocks(t *testing.T) {
+	tests := []struct {
+		name string
+		cols []int
+		want int
+	}{
+		// X has three in column 2 and plays the fourth.
+		{"win", []int{2, 3, 2, 3, 2, 4}, 2},
+		// O has three in a row from column 1 and X blocks either end.
+		{"block", []int{6, 1, 6, 2, 0, 3}, 4},
+	}
+
+	player := ai.NewMinimax[State, int](Rules{}, 4)
+	for _, tt := range tests {
+		if got := player.Solve(play(t, tt.cols...)); got != tt.want {
+			t.Errorf("%s: expected column %d, got %d", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestRulesEvaluate(t *testing.T) {
+	// X to move again after two more pieces in the middle for X.
+	s := play(t, 3, 0, 3, 0)
+	if v := (Rules{}).Evaluate(s); v <= 0 || v >= 1 {
+		t.Errorf("expected a good position below a win for x, got %v", v)
+	}
+
+	s.Turn = O
+	if v := (Rules{}).Evaluate(s); v >= 0 {
+		t.Errorf("expected a bad position for o, got %v", v)
+	}
+}
+
+func TestBitRules(t *testing.T) {
+	s := play(t, 3, 3, 2, 4, 4, 2, 1)
+	b := BitsOf(s)
+
+	for _, col := range []int{0, 5, 3} {
+		s.Step(col)
+		b = (BitRules{}).Play(b, col)
+		if b != BitsOf(s) {
+			t.Fatalf("after column %d: expected %+v, got %+v", col, BitsOf(s), b)
+		}
+	}
+
+	// The results agree with Outcome along a game X wins on a diagonal.
+	s, b = New(), Bits{}
+	for _, col := range []int{0, 1, 1, 2, 2, 3, 2, 3, 3, 6, 3} {
+		over, _ := (BitRules{}).Result(b)
+		if over != (s.Outcome() != Empty) {
+			t.Fatalf("expected over to be %v", s.Outcome() != Empty)
+		}
+		s.Step(col)
+		b = (BitRules{}).Play(b, col)
+	}
+	if over, value := (BitRules{}).Result(b); !over || value != -1 {
+		t.Errorf("expected the player to move to have lost, got %v and %v", over, value)
+	}
+	if (BitRules{}).Evaluate(BitsOf(play(t, 3, 0, 3, 0))) != (Rules{}).Evaluate(play(t, 3, 0, 3, 0)) {
+		t.Error("expected the evaluations of Rules and BitRules to agree")
+	}
+}
+
+func TestBitRulesSearch(t *testing.T) {
+	// The same positions as TestRulesWinsAndBlocks, searched deeper with the
+	// transposition table.
+	player := ai.NewMinimax[Bits, int](BitRules{}, 8)
+	if got := player.Solve(BitsOf(play(t, 2, 3, 2, 3, 2, 4))); got != 2 {
+		t.Errorf("expected the win in column 2, got %d", got)
+	}
+	if got := player.Solve(BitsOf(play(t, 6, 1, 6, 2, 0, 3))); got != 4 {
+		t.Errorf("expected the block in column 4, got %d", got)
+	}
+}
@@ -0,0 +1,131 @@
+// Package core holds the rules of dodger, free of any rendering and timing,
+// so that they can be tested on their own and played by bots. The host
+// decides how often blocks appear and fall.
+package core
+
+import (
+	"errors"
+	"math/rand/v2"
+)
+
+// Vector is a square of the playing field, X counting columns from the left
+// and Y rows from the top.
+type Vector struct {
+	X, Y int
+}
+
+// Action is something that can happen in the game: the player moving left or
+// right, a block appearing at the top, or the blocks falling one row.
+type Action int
+
+const (
+	Left Action = iota
+	Right
+	Spawn
+	Fall
+)
+
+// Outcome tells whether the game is over.
+type Outcome int
+
+const (
+	Playing Outcome = iota
+
+	// Hit is the outcome once a block has hit the player.
+	Hit
+)
+
+// State is a position of the game. The blocks appear in columns drawn from
+// rand, so two games played with generators in the same state get the same
+// blocks.
+type State struct {
+	Size   Vector
+	Player Vector
+	Blocks []Vector
+
+	// Score counts the blocks that have fallen off the field.
+	Score int
+
+	hit  bool
+	rand *rand.Rand
+}
+
+// New returns the game on a field of the given size, with the player in the
+// middle of the bottom row and no blocks yet.
+func New(size Vector, r *rand.Rand) State {
+	return State{
+		Size:   size,
+		Player: Vector{size.X / 2, size.Y - 1},
+		Blocks: []Vector{},
+		rand:   r,
+	}
+}
+
+// Legal returns every action until a block has hit the player, and none
+// after that.
+func (s State) Legal() []Action {
+	if s.hit {
+		return nil
+	}
+
+	return []Action{Left, Right, Spawn, Fall}
+}
+
+// Step takes an action. The player going off one side of the field comes back
+// on the other, and a block falling off the bottom scores a point.
+func (s *State) Step(a Action) error {
+	if s.hit {
+		return errors.New("dodger: the game is over")
+	}
+
+	switch a {
+	case Left:
+		s.Player.X--
+		if s.Player.X < 0 {
+			s.Player.X = s.Size.X - 1
+		}
+	case Right:
+		s.Player.X++
+		if s.Player.X >= s.Size.X {
+			s.Player.X = 0
+		}
+	case Spawn:
+		s.Blocks = append(s.Blocks, Vector{s.rand.IntN(s.Size.X), 0})
+	case Fall:
+		s.fall()
+	default:
+		return errors.New("dodger: unknown action")
+	}
+
+	for _, b := range s.Blocks {
+		if b == s.Player {
+			s.hit = true
+		}
+	}
+
+	return nil
+}
+
+// Outcome returns Hit once a block has hit the player.
+func (s State) Outcome() Outcome {
+	if s.hit {
+		return Hit
+	}
+
+	return Playing
+}
+
+func (s *State) fall() {
+	for i := range s.Blocks {
+		s.Blocks[i].Y++
+	}
+
+	for i := range s.Blocks {
+		if s.Blocks[i].Y > s.Size.Y {
+			s.Blocks = append(s.Blocks[:i], s.Blocks[i+1:]...)
+			s.Score++
+			// There can only be one block to be removed every time.
+			break
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func newRand() *rand.Rand {
+	return rand.New(rand.NewPCG(1, 2))
+}
+
+func TestMove(t *testing.T) {
+	s := New(Vector{5, 5}, newRand())
+
+	for range 3 {
+		if err := s.Step(Right); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s.Player != (Vector{0, 4}) {
+		t.Errorf("expected the player to come back on the left, got %v", s.Player)
+	}
+
+	if err := s.Step(Left); err != nil || s.Player != (Vector{4, 4}) {
+		t.Errorf("expected the player to come back on the right, got %v", s.Player)
+	}
+}
+
+func TestScore(t *testing.T) {
+	s := New(Vector{5, 5}, newRand())
+	s.Blocks = []Vector{{0, 3}}
+
+	for range 3 {
+		if err := s.Step(Fall); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if s.Score != 1 || len(s.Blocks) != 0 {
+		t.Errorf("expected the block to fall off and score, got %d and %v", s.Score, s.Blocks)
+	}
+}
+
+func TestHit(t *testing.T) {
+	s := New(Vector{5, 5}, newRand())
+	s.Blocks = []Vector{{s.Player.X, 3}}
+
+	if err := s.Step(Fall); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Outcome() != Hit {
+		t.Fatal("expected the block to hit the player")
+	}
+	if s.Legal() != nil || s.Step(Left) == nil {
+		t.Error("expected no actions once hit")
+	}
+}
@@ -1,10 +1,14 @@
 package dodger
 
 import (
+	"flag"
 	"fmt"
-	"math/rand/v2"
 	"time"
 
+	"github.com/Kaamkiya/gg/internal/app/dodger/core"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/theme"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -14,83 +18,157 @@ type (
 	moveBlockMsg  struct{}
 )
 
-type vector struct {
-	x int
-	y int
+const (
+	// spawnInterval is the time between two new blocks appearing.
+	spawnInterval = 200 * time.Millisecond
+
+	defaultWidth  = 30
+	defaultHeight = 20
+	minSize       = 5
+
+	// chromeHeight is the number of rows of the view above and below the
+	// playing field.
+	chromeHeight = 4
+)
+
+// bindings are the default keys of the player's actions.
+var bindings = []game.Binding{
+	{Action: "left", Keys: []string{"left", "h"}, Help: "move left"},
+	{Action: "right", Keys: []string{"right", "l"}, Help: "move right"},
+	{Action: "pause", Keys: []string{game.PauseKey}, Help: "pause"},
+	{Action: "quit", Keys: []string{"q"}, Help: "quit"},
+}
+
+func spawnBlock() tea.Cmd {
+	return game.Tick(spawnInterval, spawnBlockMsg{})
 }
 
 type model struct {
-	size   vector   // The size of the screen.
-	player vector   // The position of the player.
-	blocks []vector // The positions of each block on the screen.
-	score  int      // The amount of blocks that have gone off-screen.
+	opts  game.Options
+	state core.State
+	want  core.Vector // The size asked for, which the field's is at most.
+	begun bool        // Whether the first block has appeared.
+	keys  game.KeyMap
+	pause game.Pause
 
 	blockStyle  lipgloss.Style
 	playerStyle lipgloss.Style
 }
 
-func initialModel() tea.Model {
-	size := vector{30, 20}
+func initialModel(opts game.Options) tea.Model {
+	return newModel(opts, core.Vector{X: defaultWidth, Y: defaultHeight})
+}
+
+func newModel(opts game.Options, size core.Vector) model {
+	keys := game.Keys("dodger")
+
 	return model{
-		size:        size,
-		player:      vector{int(size.x / 2), size.y - 1},
-		blocks:      []vector{},
-		score:       0,
-		blockStyle:  lipgloss.NewStyle().Foreground(lipgloss.Color("#cccccc")),
-		playerStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("#aaaaff")),
+		opts:        opts,
+		keys:        keys,
+		pause:       game.NewPause(keys),
+		state:       core.New(size, opts.Rand),
+		want:        size,
+		blockStyle:  theme.Current().Text,
+		playerStyle: theme.Current().Players[0],
 	}
 }
 
 func (m model) Init() tea.Cmd {
-	return nil
+	return spawnBlock()
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	if m.state.Outcome() == core.Hit {
+		if msg, ok := msg.(tea.KeyMsg); ok && m.keys.Action(msg) == "quit" {
+			return m, tea.Quit
+		}
+		return m, nil
+	}
+
+	if cmd, handled, resumed := m.pause.Update(msg); handled {
+		if resumed {
+			return m, spawnBlock()
+		}
+		return m, cmd
+	}
+
+	// The blocks start falling again when the game resumes.
+	if !m.pause.Running() {
+		if msg, ok := msg.(tea.KeyMsg); ok && m.keys.Action(msg) == "quit" {
+			return m, tea.Quit
+		}
+		return m, nil
+	}
+
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// The playing field shrinks to fit the terminal until the first
+		// block appears.
+		if !m.begun {
+			return m.fit(msg), nil
+		}
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		switch m.keys.Action(msg) {
+		case "quit":
 			return m, tea.Quit
-		case "left", "h":
-			m.player.x--
-			if m.player.x < 0 {
-				m.player.x = m.size.x - 1
-			}
-		case "right", "l":
-			m.player.x++
-			if m.player.x >= m.size.x {
-				m.player.x = 0
-			}
+		case "left":
+			m.state.Step(core.Left)
+		case "right":
+			m.state.Step(core.Right)
 		}
 	case spawnBlockMsg:
-		m.blocks = append(m.blocks, vector{rand.IntN(m.size.x), 0})
+		m.begun = true
+		m.state.Step(core.Spawn)
+		// Every new block moves the others down by one line.
+		cmd = func() tea.Msg {
+			return moveBlockMsg{}
+		}
 	case moveBlockMsg:
-		m.moveBlocks()
+		m.state.Step(core.Fall)
+		cmd = spawnBlock()
 	}
 
-	for _, b := range m.blocks {
-		if b.x == m.player.x && b.y == m.player.y {
-			return m, tea.Quit
-		}
+	if m.state.Outcome() == core.Hit {
+		return m, game.Over(game.Result{Score: m.state.Score})
 	}
 
-	return m, nil
+	return m, cmd
+}
+
+// fit starts the round over on the largest playing field up to the size asked
+// for that fits in a terminal of the given size. The round is the one played
+// with the same seed on a field of that size.
+func (m model) fit(msg tea.WindowSizeMsg) model {
+	size := core.Vector{
+		X: max(min(m.want.X, msg.Width), minSize),
+		Y: max(min(m.want.Y, msg.Height-chromeHeight), minSize),
+	}
+	if size == m.state.Size {
+		return m
+	}
+
+	fitted := newModel(game.Seeded(m.opts.Seed), size)
+	fitted.want = m.want
+	fitted.pause = m.pause
+	return fitted
 }
 
 func (m model) View() string {
-	s := fmt.Sprintf("\nScore: %d\n", m.score)
+	s := fmt.Sprintf("\nScore: %d\n", m.state.Score)
 
-	for y := 0; y < m.size.y; y++ {
-		for x := 0; x < m.size.x; x++ {
+	for y := 0; y < m.state.Size.Y; y++ {
+		for x := 0; x < m.state.Size.X; x++ {
 			drew := false
-			for _, b := range m.blocks {
-				if b.x == x && b.y == y {
+			for _, b := range m.state.Blocks {
+				if b.X == x && b.Y == y {
 					s += m.blockStyle.Render(string(rune(0x2022))) // 0x2022 is a unicode bullet point.
 					drew = true
 				}
 			}
 			if !drew {
-				if x == m.player.x && y == m.player.y {
+				if x == m.state.Player.X && y == m.state.Player.Y {
 					s += m.playerStyle.Render(string(rune(0x2205))) // 0x2205 is a unicode rectangle.
 				} else {
 					s += " "
@@ -100,38 +178,43 @@ func (m model) View() string {
 		s += "\n"
 	}
 
-	s += "hjkl or arrows to move"
+	if m.state.Outcome() == core.Hit {
+		s += fmt.Sprintf("Game over! Press %s to quit.\nSeed: %d", m.keys.Keys("quit"), m.opts.Seed)
+		if m.state.Size != m.want {
+			s += fmt.Sprintf(" on a %dx%d field", m.state.Size.X, m.state.Size.Y)
+		}
+	} else if line := m.pause.View(); line != "" {
+		s += line
+	} else {
+		s += game.HelpKey + " for the keys"
+	}
 
 	return s
 }
 
-func (m *model) moveBlocks() {
-	for i := range m.blocks {
-		m.blocks[i].y++
-	}
-
-	for i := range m.blocks {
-		if m.blocks[i].y > m.size.y {
-			m.blocks = append(m.blocks[:i], m.blocks[i+1:]...)
-			m.score++
-			// There can only be one block to be removed every time.
-			break
-		}
-	}
+func init() {
+	game.Register(game.Game{
+		ID:          "dodger",
+		Name:        "dodger",
+		Players:     1,
+		Description: "Dodge the falling blocks for as long as you can.",
+		HighScores:  true,
+		Recordable:  true,
+		Keys:        bindings,
+		New:         initialModel,
+		Flags:       flags,
+	})
 }
 
-func Run() {
-	prog := tea.NewProgram(initialModel())
+func flags(fs *flag.FlagSet) func(game.Options) (tea.Model, error) {
+	width := fs.Int("width", defaultWidth, "width of the playing field")
+	height := fs.Int("height", defaultHeight, "height of the playing field")
 
-	go func() {
-		for {
-			time.Sleep(200 * time.Millisecond)
-			prog.Send(spawnBlockMsg{})
-			prog.Send(moveBlockMsg{})
+	return func(opts game.Options) (tea.Model, error) {
+		if *width < minSize || *height < minSize {
+			return nil, fmt.Errorf("the playing field must be at least %dx%d", minSize, minSize)
 		}
-	}()
 
-	if _, err := prog.Run(); err != nil {
-		panic(err)
+		return newModel(opts, core.Vector{X: *width, Y: *height}), nil
 	}
 }
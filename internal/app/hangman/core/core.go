@@ -0,0 +1,108 @@
+// Package core holds the rules of hangman, free of any rendering, so that they
+// can be tested on their own and played by bots.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Lives is the number of wrong guesses the player can make. The next one
+// hangs them.
+const Lives = 6
+
+// Outcome tells whether the word was found.
+type Outcome int
+
+const (
+	Playing Outcome = iota
+	Found
+	Hanged
+)
+
+// State is a word being guessed.
+type State struct {
+	Word string
+
+	// Shown holds the letters of the word found so far, '_' for the others.
+	Shown []rune
+
+	// Wrong holds the letters guessed that aren't in the word.
+	Wrong []rune
+}
+
+// New returns the game of guessing word, a word of lowercase letters.
+func New(word string) State {
+	return State{
+		Word:  word,
+		Shown: []rune(strings.Repeat("_", len(word))),
+	}
+}
+
+// Legal returns the letters that haven't been guessed yet. There are none
+// once the game is over.
+func (s State) Legal() []rune {
+	if s.Outcome() != Playing {
+		return nil
+	}
+
+	var letters []rune
+	for l := 'a'; l <= 'z'; l++ {
+		if !s.guessed(l) {
+			letters = append(letters, l)
+		}
+	}
+
+	return letters
+}
+
+// Step guesses a letter. Letters that were guessed already are refused.
+func (s *State) Step(letter rune) error {
+	switch {
+	case s.Outcome() != Playing:
+		return errors.New("hangman: the game is over")
+	case letter < 'a' || letter > 'z':
+		return fmt.Errorf("hangman: %q isn't a letter", letter)
+	case s.guessed(letter):
+		return fmt.Errorf("hangman: %c was guessed already", letter)
+	}
+
+	found := false
+	for i, l := range s.Word {
+		if l == letter {
+			s.Shown[i] = l
+			found = true
+		}
+	}
+
+	if !found {
+		s.Wrong = append(s.Wrong, letter)
+	}
+
+	return nil
+}
+
+// Left returns the number of wrong guesses the player can still make, which
+// is below zero once they are hanged.
+func (s State) Left() int {
+	return Lives - len(s.Wrong)
+}
+
+// Outcome returns Found once every letter has been found, Hanged once the
+// player has made too many wrong guesses, and Playing otherwise.
+func (s State) Outcome() Outcome {
+	switch {
+	case s.Left() < 0:
+		return Hanged
+	case string(s.Shown) == s.Word:
+		return Found
+	}
+
+	return Playing
+}
+
+func (s State) guessed(letter rune) bool {
+	return slices.Contains(s.Wrong, letter) || slices.Contains(s.Shown, letter)
+}
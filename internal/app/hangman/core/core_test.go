@@ -0,0 +1,45 @@
+package core
+
+import "testing"
+
+func TestFound(t *testing.T) {
+	s := New("gopher")
+
+	for _, l := range "gopz" {
+		if err := s.Step(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if string(s.Shown) != "gop___" || string(s.Wrong) != "z" {
+		t.Errorf("expected gop___ with z wrong, got %s with %s wrong", string(s.Shown), string(s.Wrong))
+	}
+	if err := s.Step('o'); err == nil {
+		t.Error("expected a letter guessed already to be refused")
+	}
+	if len(s.Legal()) != 26-4 {
+		t.Errorf("expected 22 letters left, got %d", len(s.Legal()))
+	}
+
+	for _, l := range "her" {
+		s.Step(l)
+	}
+	if s.Outcome() != Found || s.Legal() != nil {
+		t.Errorf("expected the word to be found, got %d", s.Outcome())
+	}
+}
+
+func TestHanged(t *testing.T) {
+	s := New("go")
+
+	for _, l := range "abcdef" {
+		s.Step(l)
+	}
+	if s.Outcome() != Playing || s.Left() != 0 {
+		t.Fatalf("expected one more guess after %d wrong ones, got %d left", Lives, s.Left())
+	}
+
+	s.Step('h')
+	if s.Outcome() != Hanged {
+		t.Errorf("expected the player to be hanged, got %d", s.Outcome())
+	}
+}
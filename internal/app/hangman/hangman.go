@@ -1,27 +1,22 @@
 package hangman
 
 import (
-	"math/rand/v2"
-	"slices"
+	"fmt"
+
+	"github.com/Kaamkiya/gg/internal/app/hangman/core"
+	"github.com/Kaamkiya/gg/internal/game"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 type model struct {
-	word     string
-	showWord []rune
-	guesses  int
-	guessed  []string
-	art      []string
+	opts  game.Options
+	state core.State
+	art   []string
 }
 
-func initialModel() tea.Model {
-	word := wordlist[rand.IntN(len(wordlist))]
-
-	showWord := make([]rune, len(word))
-	for i := range word {
-		showWord[i] = '_'
-	}
+func initialModel(opts game.Options) tea.Model {
+	word := wordlist[opts.Rand.IntN(len(wordlist))]
 
 	art := []string{
 		` +--+
@@ -76,11 +71,9 @@ func initialModel() tea.Model {
 	}
 
 	return model{
-		word:     word,
-		showWord: showWord,
-		guesses:  6,
-		guessed:  []string{},
-		art:      art,
+		opts:  opts,
+		state: core.New(word),
+		art:   art,
 	}
 }
 
@@ -91,69 +84,77 @@ func (m model) Init() tea.Cmd {
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// Letters are guesses, so once the game is over any key leaves it.
+		if m.over() {
+			return m, tea.Quit
+		}
+
 		switch msg.String() {
-		case "ctrl+c":
+		case "ctrl+c", "esc":
 			return m, tea.Quit
 		case "a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l", "m", "n", "o", "p", "q", "r", "s", "t", "u", "v", "w", "x", "y", "z":
-			letter := msg.String()
-			if slices.Contains(m.guessed, letter) {
+			// Letters guessed already are refused.
+			if err := m.state.Step(msg.Runes[0]); err != nil {
 				return m, nil
 			}
 
-			inWord := false
-			for i, char := range m.word {
-				if string(char) == letter {
-					m.showWord[i] = char
-					inWord = true
-				}
-			}
-
-			if !inWord {
-				m.guessed = append(m.guessed, letter)
-				m.guesses--
+			if m.over() {
+				return m, game.Over(m.result())
 			}
 		}
 	}
 
-	if m.guesses <= -1 || m.word == string(m.showWord) {
-		return m, tea.Quit
-	}
-
 	return m, nil
 }
 
-func (m model) View() string {
-	s := ""
+func (m model) over() bool {
+	return m.state.Outcome() != core.Playing
+}
 
-	if m.guesses < 0 {
-		s += m.art[len(m.art)-1]
-	} else {
-		s += m.art[6-m.guesses]
+// result reports whether the word was found, and the wrong guesses it took.
+func (m model) result() game.Result {
+	wrong := len(m.state.Wrong)
+	if m.state.Outcome() == core.Hanged {
+		return game.Result{Outcome: game.Loss, Summary: "hanged on " + m.state.Word}
 	}
 
-	s += "\n\nGuessed: "
-	for _, guessed := range m.guessed {
-		s += guessed
+	return game.Result{
+		Outcome: game.Win,
+		Summary: fmt.Sprintf("found %s with %d wrong guesses", m.state.Word, wrong),
+		Stats:   []game.Stat{{Name: "wrong guesses", Value: float64(wrong), LowerIsBetter: true}},
 	}
+}
 
-	s += "\n\nWord: "
-	for _, char := range m.showWord {
-		s += string(char)
-	}
+func (m model) View() string {
+	s := ""
 
+	s += m.art[min(len(m.state.Wrong), len(m.art)-1)]
+
+	s += "\n\nGuessed: " + string(m.state.Wrong)
+	s += "\n\nWord: " + string(m.state.Shown)
 	s += "\n\n"
 
-	if m.guesses < 0 {
-		s += `The word was "` + m.word + "\".\n\n"
+	if m.state.Outcome() == core.Hanged {
+		s += `The word was "` + m.state.Word + "\".\n\n"
+	} else if m.over() {
+		s += "You got it!\n\n"
+	}
+
+	if m.over() {
+		s += fmt.Sprintf("Press any key to quit.\nSeed: %d\n", m.opts.Seed)
+	} else {
+		s += "Press esc to quit.\n"
 	}
 
 	return s
 }
 
-func Run() {
-	p := tea.NewProgram(initialModel())
-
-	if _, err := p.Run(); err != nil {
-		panic(err)
-	}
+func init() {
+	game.Register(game.Game{
+		ID:          "hangman",
+		Name:        "hangman",
+		Players:     1,
+		Description: "Guess the word before the hangman is drawn.",
+		New:         initialModel,
+	})
 }
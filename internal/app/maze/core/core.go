@@ -0,0 +1,136 @@
+// Package core holds the rules of maze, free of any rendering, so that they
+// can be tested on their own and played by bots.
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The squares of a maze.
+const (
+	Wall  = '#'
+	Path  = ' '
+	Start = 'S'
+	End   = 'E'
+)
+
+// Vector is a square of the maze, or a direction.
+type Vector struct {
+	Row, Col int
+}
+
+// Action is a move of the player to the next square up, down, left or right.
+type Action int
+
+const (
+	Up Action = iota
+	Down
+	Left
+	Right
+)
+
+// directions are the directions of the moves.
+var directions = map[Action]Vector{
+	Up:    {-1, 0},
+	Down:  {1, 0},
+	Left:  {0, -1},
+	Right: {0, 1},
+}
+
+var actionNames = map[Action]string{Up: "up", Down: "down", Left: "left", Right: "right"}
+
+// Outcome tells whether the game is over.
+type Outcome int
+
+const (
+	Playing Outcome = iota
+
+	// Escaped is the outcome once the player has reached the end.
+	Escaped
+)
+
+// State is a position of the game.
+type State struct {
+	// Grid holds the squares of the maze by row, walls being Wall.
+	Grid [][]rune
+
+	Pos Vector
+	End Vector
+
+	// Moves counts the moves the player made.
+	Moves int
+}
+
+// New returns the game on the given maze, with the player on its Start
+// square.
+func New(grid [][]rune) State {
+	s := State{Grid: grid}
+	for row := range grid {
+		for col, c := range grid[row] {
+			switch c {
+			case Start:
+				s.Pos = Vector{row, col}
+			case End:
+				s.End = Vector{row, col}
+			}
+		}
+	}
+
+	return s
+}
+
+// Legal returns the moves that don't run into a wall. There are none once
+// the player is out.
+func (s State) Legal() []Action {
+	if s.Outcome() != Playing {
+		return nil
+	}
+
+	var actions []Action
+	for _, a := range []Action{Up, Down, Left, Right} {
+		if s.open(s.moved(a)) {
+			actions = append(actions, a)
+		}
+	}
+
+	return actions
+}
+
+// Step moves the player. Moves into a wall or off the maze are refused.
+func (s *State) Step(a Action) error {
+	if s.Outcome() != Playing {
+		return errors.New("maze: the player is out")
+	}
+	if _, ok := directions[a]; !ok {
+		return errors.New("maze: unknown action")
+	}
+
+	to := s.moved(a)
+	if !s.open(to) {
+		return fmt.Errorf("maze: there is a wall %s", actionNames[a])
+	}
+
+	s.Pos = to
+	s.Moves++
+	return nil
+}
+
+// Outcome returns Escaped once the player has reached the end.
+func (s State) Outcome() Outcome {
+	if s.Pos == s.End {
+		return Escaped
+	}
+
+	return Playing
+}
+
+func (s State) moved(a Action) Vector {
+	d := directions[a]
+	return Vector{s.Pos.Row + d.Row, s.Pos.Col + d.Col}
+}
+
+// open reports whether the square at v is in the maze and not a wall.
+func (s State) open(v Vector) bool {
+	return v.Row >= 0 && v.Row < len(s.Grid) && v.Col >= 0 && v.Col < len(s.Grid[v.Row]) && s.Grid[v.Row][v.Col] != Wall
+}
@@ -0,0 +1,49 @@
+package core
+
+import "testing"
+
+func newMaze() State {
+	rows := []string{
+		"#####",
+		"#S  #",
+		"### #",
+		"#E  #",
+		"#####",
+	}
+
+	grid := make([][]rune, len(rows))
+	for i, row := range rows {
+		grid[i] = []rune(row)
+	}
+	return New(grid)
+}
+
+func TestWalls(t *testing.T) {
+	s := newMaze()
+	if s.Pos != (Vector{1, 1}) || s.End != (Vector{3, 1}) {
+		t.Fatalf("expected the start and end squares, got %v and %v", s.Pos, s.End)
+	}
+
+	if got := s.Legal(); len(got) != 1 || got[0] != Right {
+		t.Errorf("expected only moving right, got %v", got)
+	}
+	if err := s.Step(Down); err == nil || s.Pos != (Vector{1, 1}) || s.Moves != 0 {
+		t.Error("expected moving into a wall to be refused")
+	}
+}
+
+func TestEscape(t *testing.T) {
+	s := newMaze()
+	for _, a := range []Action{Right, Right, Down, Down, Left, Left} {
+		if err := s.Step(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if s.Outcome() != Escaped || s.Moves != 6 {
+		t.Errorf("expected the player out in 6 moves, got %v after %d", s.Outcome(), s.Moves)
+	}
+	if s.Legal() != nil || s.Step(Right) == nil {
+		t.Error("expected no moves once out")
+	}
+}
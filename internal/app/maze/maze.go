@@ -1,44 +1,83 @@
 package maze
 
 import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/app/maze/core"
 	"github.com/Kaamkiya/gg/internal/app/maze/mazegenerator"
+	"github.com/Kaamkiya/gg/internal/game"
+
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-type vector struct {
-	x int
-	y int
+const (
+	defaultWidth     = 25
+	defaultHeight    = 15
+	defaultAlgorithm = "prim"
+
+	// minSize is the smallest width or height a maze can be generated with.
+	minSize = 5
+
+	// chromeHeight is the number of rows of the view below the maze.
+	chromeHeight = 4
+)
+
+// bindings are the default keys of the player's actions.
+var bindings = []game.Binding{
+	{Action: "up", Keys: []string{"up", "k"}, Help: "move up"},
+	{Action: "down", Keys: []string{"down", "j"}, Help: "move down"},
+	{Action: "left", Keys: []string{"left", "h"}, Help: "move left"},
+	{Action: "right", Keys: []string{"right", "l"}, Help: "move right"},
+	{Action: "quit", Keys: []string{"q"}, Help: "quit"},
 }
 
 type model struct {
-	maze   [][]rune
-	pos    vector
-	endpos vector
+	opts game.Options
+
+	// width, height and algorithm are those the maze was asked for. Unless
+	// fixed is set, as it is for the daily challenge, the maze shrinks to
+	// fit the terminal until the first move.
+	width, height int
+	algorithm     string
+	fixed         bool
+
+	state core.State
+	keys  game.KeyMap
+}
+
+// actions are the moves of the player's actions.
+var actions = map[string]core.Action{
+	"up":    core.Up,
+	"down":  core.Down,
+	"left":  core.Left,
+	"right": core.Right,
 }
 
-func initialModel() tea.Model {
-	maze := mazegenerator.GenerateMaze(25, 15, "prim")
+func initialModel(opts game.Options) tea.Model {
+	return newModel(opts, defaultWidth, defaultHeight, defaultAlgorithm)
+}
 
-	startpos := vector{}
-	endpos := vector{}
+// dailyModel returns the maze of the daily challenge, which is the same for
+// every player whatever the size of their terminal.
+func dailyModel(opts game.Options) tea.Model {
+	m := newModel(opts, defaultWidth, defaultHeight, defaultAlgorithm)
+	m.fixed = true
+	return m
+}
 
-	for y := range maze.Grid {
-		for x := range maze.Grid[y] {
-			if maze.Get(x, y) == 'S' {
-				startpos.x = y
-				startpos.y = x
-			}
-			if maze.Get(x, y) == 'E' {
-				endpos.x = y
-				endpos.y = x
-			}
-		}
-	}
+func newModel(opts game.Options, width, height int, algorithm string) model {
+	maze := mazegenerator.GenerateMaze(width, height, algorithm, opts.Rand)
 
 	return model{
-		maze:   maze.Grid,
-		pos:    startpos,
-		endpos: endpos,
+		opts:      opts,
+		width:     width,
+		height:    height,
+		algorithm: algorithm,
+		state:     core.New(maze.Grid),
+		keys:      game.Keys("maze"),
 	}
 }
 
@@ -48,39 +87,60 @@ func (m model) Init() tea.Cmd {
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		if !m.fixed && m.state.Moves == 0 {
+			return m.fit(msg), nil
+		}
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		action := m.keys.Action(msg)
+		if action == "quit" {
 			return m, tea.Quit
-		case "up", "k":
-			m.MovePlayer("up")
-		case "down", "j":
-			m.MovePlayer("down")
-		case "left", "h":
-			m.MovePlayer("left")
-		case "right", "l":
-			m.MovePlayer("right")
+		}
 
+		// Moves into walls and after the end are refused.
+		a, ok := actions[action]
+		if !ok || m.state.Step(a) != nil {
+			return m, nil
 		}
-	}
 
-	if m.pos == m.endpos {
-		return m, tea.Quit
+		if m.state.Outcome() == core.Escaped {
+			moves := m.state.Moves
+			return m, game.Over(game.Result{
+				Outcome: game.Win,
+				Summary: fmt.Sprintf("out in %d moves", moves),
+				Stats:   []game.Stat{{Name: "moves", Value: float64(moves), LowerIsBetter: true}},
+			})
+		}
 	}
 
 	return m, nil
 }
 
+// fit generates the largest maze up to the size asked for that fits in a
+// terminal of the given size. It is the maze generated with the same seed at
+// that size.
+func (m model) fit(msg tea.WindowSizeMsg) model {
+	width := max(min(m.width, msg.Width), minSize)
+	height := max(min(m.height, msg.Height-chromeHeight), minSize)
+	if width == len(m.state.Grid[0]) && height == len(m.state.Grid) {
+		return m
+	}
+
+	fitted := newModel(game.Seeded(m.opts.Seed), width, height, m.algorithm)
+	fitted.width, fitted.height = m.width, m.height
+	return fitted
+}
+
 func (m model) View() string {
 	s := ""
 
-	for i, row := range m.maze {
-		for j := range m.maze[i] {
-			if i == m.pos.x && j == m.pos.y {
+	for i, row := range m.state.Grid {
+		for j := range row {
+			if (core.Vector{Row: i, Col: j}) == m.state.Pos {
 				s += "@"
-			} else if row[j] == 'E' {
+			} else if row[j] == core.End {
 				s += "X"
-			} else if row[j] == '#' {
+			} else if row[j] == core.Wall {
 				s += string(rune(9608))
 			} else {
 				s += " "
@@ -89,40 +149,46 @@ func (m model) View() string {
 		s += "\n"
 	}
 
-	s += "\n\nhjkl or arrows to move\n"
+	if m.state.Outcome() == core.Escaped {
+		s += fmt.Sprintf("\n\nYou made it out in %d moves! Press %s to quit.\nSeed: %d", m.state.Moves, m.keys.Keys("quit"), m.opts.Seed)
+		if width, height := len(m.state.Grid[0]), len(m.state.Grid); width != m.width || height != m.height {
+			s += fmt.Sprintf(" at %dx%d", width, height)
+		}
+		s += "\n"
+	} else {
+		s += "\n\n" + game.HelpKey + " for the keys\n"
+	}
 
 	return s
 }
 
-func (m *model) MovePlayer(dir string) {
-	switch dir {
-	case "left":
-		m.pos.y--
-		if m.maze[m.pos.x][m.pos.y] == '#' {
-			m.pos.y++
-		}
-	case "right":
-		m.pos.y++
-		if m.maze[m.pos.x][m.pos.y] == '#' {
-			m.pos.y--
-		}
-	case "up":
-		m.pos.x--
-		if m.maze[m.pos.x][m.pos.y] == '#' {
-			m.pos.x++
-		}
-	case "down":
-		m.pos.x++
-		if m.maze[m.pos.x][m.pos.y] == '#' {
-			m.pos.x--
-		}
-	}
+func init() {
+	game.Register(game.Game{
+		ID:          "maze",
+		Name:        "maze",
+		Players:     1,
+		Description: "Find your way from the start to the X.",
+		Keys:        bindings,
+		New:         initialModel,
+		Flags:       flags,
+		Daily:       dailyModel,
+	})
 }
 
-func Run() {
-	p := tea.NewProgram(initialModel())
+func flags(fs *flag.FlagSet) func(game.Options) (tea.Model, error) {
+	width := fs.Int("width", defaultWidth, "width of the maze")
+	height := fs.Int("height", defaultHeight, "height of the maze")
+	algorithm := fs.String("algorithm", defaultAlgorithm, "maze generation algorithm: "+strings.Join(mazegenerator.Algorithms, ", "))
+
+	return func(opts game.Options) (tea.Model, error) {
+		if *width < minSize || *height < minSize {
+			return nil, fmt.Errorf("the maze must be at least %dx%d", minSize, minSize)
+		}
+
+		if !slices.Contains(mazegenerator.Algorithms, *algorithm) {
+			return nil, fmt.Errorf("unknown maze algorithm %q", *algorithm)
+		}
 
-	if _, err := p.Run(); err != nil {
-		panic(err)
+		return newModel(opts, *width, *height, *algorithm), nil
 	}
 }
@@ -6,16 +6,21 @@ type MazeGenerator interface {
 	Generate(maze *Maze)
 }
 
-func NewMazeGenerator(generator string) MazeGenerator {
+// Algorithms lists the generator names understood by NewMazeGenerator.
+var Algorithms = []string{"prim"}
+
+func NewMazeGenerator(generator string, rng *rand.Rand) MazeGenerator {
 	switch generator {
 	case "prim":
-		return &PrimGenerator{}
+		return &PrimGenerator{rng}
 	default:
-		return &PrimGenerator{}
+		return &PrimGenerator{rng}
 	}
 }
 
-type PrimGenerator struct{}
+type PrimGenerator struct {
+	rng *rand.Rand
+}
 
 func (p *PrimGenerator) Generate(maze *Maze) {
 	startX, startY := maze.GetStartPos()
@@ -30,7 +35,7 @@ func (p *PrimGenerator) Generate(maze *Maze) {
 
 	for len(walls) > 0 {
 		// Pop random wall
-		randIdx := rand.IntN(len(walls))
+		randIdx := p.rng.IntN(len(walls))
 		wall := walls[randIdx]
 		walls = append(walls[:randIdx], walls[randIdx+1:]...)
 
@@ -42,7 +47,7 @@ func (p *PrimGenerator) Generate(maze *Maze) {
 		if len(paths) == 0 {
 			continue
 		}
-		path := paths[rand.IntN(len(paths))]
+		path := paths[p.rng.IntN(len(paths))]
 
 		// skip special case: last wall before boundary
 		if wall.Diff(path) != 1 {
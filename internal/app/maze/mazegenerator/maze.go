@@ -30,7 +30,7 @@ type Maze struct {
 	Grid          [][]rune
 }
 
-func NewMaze(width, height int) *Maze {
+func NewMaze(width, height int, rng *rand.Rand) *Maze {
 	grid := make([][]rune, height)
 
 	for i := range grid {
@@ -40,8 +40,8 @@ func NewMaze(width, height int) *Maze {
 		}
 	}
 
-	startX := rand.IntN(width/4) + 1
-	startY := rand.IntN(height/4) + 1
+	startX := rng.IntN(width/4) + 1
+	startY := rng.IntN(height/4) + 1
 
 	grid[startY][startX] = START
 
@@ -1,8 +1,12 @@
 package mazegenerator
 
-func GenerateMaze(width, height int, algorithm string) *Maze {
-	maze := NewMaze(width, height)
-	generator := NewMazeGenerator(algorithm)
+import "math/rand/v2"
+
+// GenerateMaze generates a maze with the given algorithm, drawing every random
+// number from rng.
+func GenerateMaze(width, height int, algorithm string, rng *rand.Rand) *Maze {
+	maze := NewMaze(width, height, rng)
+	generator := NewMazeGenerator(algorithm, rng)
 	generator.Generate(maze)
 
 	return maze
@@ -1,12 +1,22 @@
 package mazegenerator
 
 import (
+	"math/rand/v2"
+	"reflect"
 	"testing"
 )
 
+var testRng = rand.New(rand.NewPCG(1, 2))
+
+// testRand returns the random numbers shared by the tests, so that every maze
+// they generate is different but a test run can be repeated exactly.
+func testRand() *rand.Rand {
+	return testRng
+}
+
 func TestPathFinder(t *testing.T) {
 	t.Run("Testing path finder on blocked maze", func(t *testing.T) {
-		maze := NewMaze(25, 25)
+		maze := NewMaze(25, 25, testRand())
 
 		startX, startY := maze.GetStartPos()
 		endX, endY := 5, 5
@@ -20,7 +30,7 @@ func TestPathFinder(t *testing.T) {
 	t.Run("Testing path finder on valid maze", func(t *testing.T) {
 		for _, grid := range mazes {
 			width, height := len(grid[0]), len(grid)
-			maze := NewMaze(width, height)
+			maze := NewMaze(width, height, testRand())
 			for i := range grid {
 				for j := range grid[i] {
 					maze.Set(j, i, grid[i][j])
@@ -50,7 +60,7 @@ func TestPathFinder(t *testing.T) {
 	t.Run("Testing path finder on invalid maze", func(t *testing.T) {
 		for _, grid := range invalidMazes {
 			width, height := len(grid[0]), len(grid)
-			maze := NewMaze(width, height)
+			maze := NewMaze(width, height, testRand())
 			for i := range grid {
 				for j := range grid[i] {
 					maze.Set(j, i, grid[i][j])
@@ -77,7 +87,7 @@ func TestPathFinder(t *testing.T) {
 func TestMazePath(t *testing.T) {
 	for i := 0; i < 1000; i++ {
 		t.Run("Testing maze", func(t *testing.T) {
-			maze := GenerateMaze(25, 15, "prim")
+			maze := GenerateMaze(25, 15, "prim", testRand())
 
 			startX, startY := maze.GetStartPos()
 			endX, endY := maze.GetEndPos()
@@ -243,3 +253,12 @@ var invalidMazes = [][][]rune{
 		{'#', '#', '#', '#', '#', '#', '#', '#', '#', '#'},
 	},
 }
+
+func TestGenerateMazeIsSeeded(t *testing.T) {
+	a := GenerateMaze(25, 15, "prim", rand.New(rand.NewPCG(42, 42)))
+	b := GenerateMaze(25, 15, "prim", rand.New(rand.NewPCG(42, 42)))
+
+	if !reflect.DeepEqual(a, b) {
+		t.Error("The same seed should generate the same maze")
+	}
+}
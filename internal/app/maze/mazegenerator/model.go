@@ -2,12 +2,14 @@ package mazegenerator
 
 import (
 	"fmt"
+	"math/rand/v2"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 type MazeModel struct {
 	maze *Maze
+	rng  *rand.Rand
 }
 
 const (
@@ -16,11 +18,12 @@ const (
 	algo   = "prim"
 )
 
-func GetModel() tea.Model {
-	maze := GenerateMaze(width, height, algo)
+func GetModel(rng *rand.Rand) tea.Model {
+	maze := GenerateMaze(width, height, algo, rng)
 
 	return MazeModel{
 		maze,
+		rng,
 	}
 }
 
@@ -70,5 +73,5 @@ func (m MazeModel) View() string {
 }
 
 func (m *MazeModel) generate() {
-	m.maze = GenerateMaze(width, height, algo)
+	m.maze = GenerateMaze(width, height, algo, m.rng)
 }
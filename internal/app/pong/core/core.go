@@ -0,0 +1,154 @@
+// Package core holds the rules of pong, free of any rendering and timing, so
+// that they can be tested on their own and played by bots. The host decides
+// how often the ball moves.
+package core
+
+import "errors"
+
+// Vector is a square of the field, or a velocity. The paddles move along the
+// rows at the top and the bottom of the field.
+type Vector struct {
+	Row, Col int
+}
+
+// Action is something that can happen in the game: a paddle moving left or
+// right, or the ball moving one step.
+type Action int
+
+const (
+	Paddle1Left Action = iota
+	Paddle1Right
+	Paddle2Left
+	Paddle2Right
+	MoveBall
+)
+
+// Outcome tells whether the game is over.
+type Outcome int
+
+const (
+	Playing Outcome = iota
+
+	// Missed is the outcome once the ball has gone past a paddle.
+	Missed
+)
+
+// State is a position of the game.
+type State struct {
+	// Size is the number of rows and columns of the field.
+	Size Vector
+
+	// Paddle1 is the paddle at the top and Paddle2 the one at the bottom.
+	Paddle1 Vector
+	Paddle2 Vector
+
+	Ball     Vector
+	Velocity Vector
+
+	// Hits counts the times the ball bounced off a paddle.
+	Hits int
+
+	missed bool
+}
+
+// New returns the game on a field of the given size, with the paddles and
+// the ball in the middle.
+func New(size Vector) State {
+	return State{
+		Size:     size,
+		Paddle1:  Vector{1, size.Col/2 + 1},
+		Paddle2:  Vector{size.Row - 1, size.Col / 2},
+		Ball:     Vector{size.Row / 2, size.Col/2 + 1},
+		Velocity: Vector{1, 1},
+	}
+}
+
+// Legal returns the paddle moves that stay on the field, and moving the ball.
+// There are none once the game is over.
+func (s State) Legal() []Action {
+	if s.missed {
+		return nil
+	}
+
+	var actions []Action
+	for _, a := range []Action{Paddle1Left, Paddle1Right, Paddle2Left, Paddle2Right} {
+		if paddle, col := s.moved(a); paddle != nil && col >= 0 && col < s.Size.Col {
+			actions = append(actions, a)
+		}
+	}
+
+	return append(actions, MoveBall)
+}
+
+// Step moves a paddle or the ball. Moving a paddle off the field is refused.
+func (s *State) Step(a Action) error {
+	if s.missed {
+		return errors.New("pong: the game is over")
+	}
+
+	if a == MoveBall {
+		s.moveBall()
+		return nil
+	}
+
+	paddle, col := s.moved(a)
+	if paddle == nil {
+		return errors.New("pong: unknown action")
+	}
+	if col < 0 || col >= s.Size.Col {
+		return errors.New("pong: the paddle is at the edge of the field")
+	}
+
+	paddle.Col = col
+	return nil
+}
+
+// moved returns the paddle moved by a and the column it would move to.
+func (s *State) moved(a Action) (*Vector, int) {
+	switch a {
+	case Paddle1Left:
+		return &s.Paddle1, s.Paddle1.Col - 1
+	case Paddle1Right:
+		return &s.Paddle1, s.Paddle1.Col + 1
+	case Paddle2Left:
+		return &s.Paddle2, s.Paddle2.Col - 1
+	case Paddle2Right:
+		return &s.Paddle2, s.Paddle2.Col + 1
+	}
+
+	return nil, 0
+}
+
+// moveBall bounces the ball off the sides and the paddles, then moves it one
+// step. The game is over once the ball reaches the top or the bottom row.
+func (s *State) moveBall() {
+	if s.Ball.Col < 0 || s.Ball.Col >= s.Size.Col {
+		s.Velocity.Col *= -1
+	}
+
+	if s.Ball.Row < 0 || s.Ball.Row >= s.Size.Row {
+		s.Velocity.Row *= -1
+	}
+
+	if s.Ball == s.Paddle1 || s.Ball == s.Paddle2 {
+		s.Velocity.Row *= -1
+		s.Hits++
+	}
+
+	if s.Ball.Row == 0 || s.Ball.Row >= s.Size.Row {
+		s.missed = true
+		return
+	}
+
+	s.Ball.Row += s.Velocity.Row
+	s.Ball.Col += s.Velocity.Col
+}
+
+// Outcome returns Missed once the ball has gone past a paddle.
+func (s State) Outcome() Outcome {
+	if s.missed {
+		return Missed
+	}
+
+	return Playing
+}
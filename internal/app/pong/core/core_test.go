@@ -0,0 +1,53 @@
+package core
+
+import "testing"
+
+func TestPaddles(t *testing.T) {
+	s := New(Vector{10, 5})
+	s.Paddle1.Col = 0
+
+	if err := s.Step(Paddle1Left); err == nil {
+		t.Error("expected the paddle not to leave the field")
+	}
+	if err := s.Step(Paddle1Right); err != nil || s.Paddle1.Col != 1 {
+		t.Errorf("expected the paddle to move right, got %v at column %d", err, s.Paddle1.Col)
+	}
+
+	s.Paddle2.Col = 4
+	want := []Action{Paddle1Left, Paddle1Right, Paddle2Left, MoveBall}
+	if got := s.Legal(); len(got) != len(want) || got[2] != Paddle2Left {
+		t.Errorf("expected the legal actions %v, got %v", want, got)
+	}
+}
+
+func TestBounce(t *testing.T) {
+	s := New(Vector{10, 5})
+	s.Ball = Vector{7, 2}
+	s.Velocity = Vector{1, 1}
+	s.Paddle2 = Vector{8, 3}
+
+	s.Step(MoveBall)
+	s.Step(MoveBall)
+	if s.Hits != 1 || s.Velocity.Row != -1 {
+		t.Errorf("expected the ball to bounce off the paddle, got %+v", s)
+	}
+}
+
+func TestMiss(t *testing.T) {
+	s := New(Vector{10, 5})
+	s.Paddle2.Col = 0
+
+	for range 20 {
+		if s.Outcome() == Missed {
+			break
+		}
+		s.Step(MoveBall)
+	}
+
+	if s.Outcome() != Missed || s.Ball.Row != s.Size.Row {
+		t.Errorf("expected the ball to go past the bottom paddle, got %+v", s)
+	}
+	if s.Legal() != nil || s.Step(MoveBall) == nil {
+		t.Error("expected nothing to move after the miss")
+	}
+}
@@ -1,113 +1,171 @@
 package pong
 
 import (
+	"flag"
 	"fmt"
 	"time"
 
+	"github.com/Kaamkiya/gg/internal/app/pong/core"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/theme"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-type vector struct {
-	x int
-	y int
-}
+type moveBallMsg struct{}
 
-type ballBody struct {
-	pos vector
-	vel vector
+// bindings are the default keys of the players' actions. The first player has
+// the paddle at the top.
+var bindings = []game.Binding{
+	{Action: "player1-left", Keys: []string{"a"}, Help: "move the top paddle left"},
+	{Action: "player1-right", Keys: []string{"d"}, Help: "move the top paddle right"},
+	{Action: "player2-left", Keys: []string{"left"}, Help: "move the bottom paddle left"},
+	{Action: "player2-right", Keys: []string{"right"}, Help: "move the bottom paddle right"},
+	{Action: "pause", Keys: []string{game.PauseKey}, Help: "pause"},
+	{Action: "quit", Keys: []string{"q"}, Help: "quit"},
 }
 
-type moveBallMsg struct{}
+const (
+	// moveInterval is the time between two steps of the ball.
+	moveInterval = 300 * time.Millisecond
+
+	defaultWidth  = 15
+	defaultHeight = 30
+	minSize       = 5
+
+	// chromeWidth and chromeHeight are the columns and rows of the view
+	// around the field: its sides and the lines below it.
+	chromeWidth  = 2
+	chromeHeight = 4
+)
+
+func moveBall() tea.Cmd {
+	return game.Tick(moveInterval, moveBallMsg{})
+}
 
 type model struct {
-	hitCount int
+	state core.State
 
-	size vector
+	// want is the size of the field asked for. Until the ball first moves,
+	// the field shrinks to fit the terminal if it is too small.
+	want  core.Vector
+	moved bool
 
-	paddle1 vector
-	paddle2 vector
+	keys  game.KeyMap
+	pause game.Pause
 
-	ball ballBody
+	colors [2]lipgloss.Style
+}
 
-	colors []lipgloss.Style
+func initialModel(game.Options) tea.Model {
+	return newModel(defaultWidth, defaultHeight)
 }
 
-func initialModel() tea.Model {
-	size := vector{30, 15}
+// newModel creates a field that is width columns wide and height rows tall.
+func newModel(width, height int) model {
+	size := core.Vector{Row: height, Col: width}
+	keys := game.Keys("pong")
 
 	return model{
-		hitCount: 0,
-		size:     size,
-		paddle1:  vector{1, 8},
-		paddle2:  vector{size.x - 1, 7},
-		ball: ballBody{
-			pos: vector{int(15), int(8)},
-			vel: vector{1, 1},
-		},
-		colors: []lipgloss.Style{
-			lipgloss.NewStyle().Foreground(lipgloss.Color("#aaaaff")),
-			lipgloss.NewStyle().Foreground(lipgloss.Color("#ffaaaa")),
-		},
+		keys:   keys,
+		pause:  game.NewPause(keys),
+		state:  core.New(size),
+		want:   size,
+		colors: theme.Current().Players,
 	}
 }
 
+// paddleMoves are the paddle moves of the players' actions.
+var paddleMoves = map[string]core.Action{
+	"player1-left":  core.Paddle1Left,
+	"player1-right": core.Paddle1Right,
+	"player2-left":  core.Paddle2Left,
+	"player2-right": core.Paddle2Right,
+}
+
 func (m model) Init() tea.Cmd {
-	return nil
+	return moveBall()
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		if m.keys.Action(msg) == "quit" {
 			return m, tea.Quit
-		case "a":
-			m.MovePaddle(1, -1)
-		case "d":
-			m.MovePaddle(1, 1)
-		case "left":
-			m.MovePaddle(2, -1)
-		case "right":
-			m.MovePaddle(2, 1)
 		}
-	case moveBallMsg:
-		if m.ball.pos.y < 0 || m.ball.pos.y >= m.size.y {
-			m.ball.vel.y *= -1
+	}
+
+	over := m.state.Outcome() != core.Playing
+	if !over {
+		if cmd, handled, resumed := m.pause.Update(msg); handled {
+			if resumed {
+				return m, moveBall()
+			}
+			return m, cmd
 		}
+	}
 
-		if m.ball.pos.x < 0 || m.ball.pos.x >= m.size.x {
-			m.ball.vel.x *= -1
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		if !m.moved {
+			return m.fit(msg), nil
+		}
+	case tea.KeyMsg:
+		if over || !m.pause.Running() {
+			return m, nil
 		}
 
-		if m.ball.pos == m.paddle1 || m.ball.pos == m.paddle2 {
-			m.ball.vel.x *= -1
-			m.hitCount++
+		// Paddles don't move off the field.
+		if move, ok := paddleMoves[m.keys.Action(msg)]; ok {
+			m.state.Step(move)
+		}
+	case moveBallMsg:
+		// The ball starts moving again when the game resumes.
+		if over || !m.pause.Running() {
+			return m, nil
 		}
 
-		if m.ball.pos.x == 0 || m.ball.pos.x >= m.size.x {
-			return m, tea.Quit
+		m.moved = true
+		m.state.Step(core.MoveBall)
+
+		if m.state.Outcome() == core.Missed {
+			return m, game.Over(game.Result{Score: m.state.Hits})
 		}
 
-		m.ball.pos.x += m.ball.vel.x
-		m.ball.pos.y += m.ball.vel.y
+		return m, moveBall()
 	}
 	return m, nil
 }
 
+// fit starts the game over on the largest field up to the size asked for that
+// fits in a terminal of the given size.
+func (m model) fit(msg tea.WindowSizeMsg) model {
+	width := max(min(m.want.Col, msg.Width-chromeWidth), minSize)
+	height := max(min(m.want.Row, msg.Height-chromeHeight), minSize)
+	if (core.Vector{Row: height, Col: width}) == m.state.Size {
+		return m
+	}
+
+	fitted := newModel(width, height)
+	fitted.want = m.want
+	fitted.pause = m.pause
+	return fitted
+}
+
 func (m model) View() string {
 	s := ""
 
-	for i := 0; i < m.size.x; i++ {
+	for i := 0; i < m.state.Size.Row; i++ {
 		s += m.colors[i%2].Render(string(rune(9608)))
 
-		for j := 0; j < m.size.y; j++ {
-			switch (vector{i, j}) {
-			case m.ball.pos:
+		for j := 0; j < m.state.Size.Col; j++ {
+			switch (core.Vector{Row: i, Col: j}) {
+			case m.state.Ball:
 				s += "o"
-			case m.paddle1:
+			case m.state.Paddle1:
 				s += "-"
-			case m.paddle2:
+			case m.state.Paddle2:
 				s += "-"
 			default:
 				s += " "
@@ -118,36 +176,40 @@ func (m model) View() string {
 		s += "\n"
 	}
 
-	s += fmt.Sprintf("\nHit count: %d\n", m.hitCount)
+	s += fmt.Sprintf("\nHit count: %d\n", m.state.Hits)
+	if line := m.pause.View(); line != "" {
+		s += "\n" + line + "\n"
+	}
+	if m.state.Outcome() == core.Missed {
+		s += fmt.Sprintf("\nGame over! Press %s to quit.\n", m.keys.Keys("quit"))
+	}
 
 	return s
 }
 
-func (m *model) MovePaddle(num, amount int) {
-	if num == 1 {
-		m.paddle1.y += amount
-		if m.paddle1.y < 0 || m.paddle1.y >= m.size.y {
-			m.paddle1.y -= amount
-		}
-	} else {
-		m.paddle2.y += amount
-		if m.paddle2.y < 0 || m.paddle2.y >= m.size.y {
-			m.paddle2.y -= amount
-		}
-	}
+func init() {
+	game.Register(game.Game{
+		ID:          "pong",
+		Name:        "pong",
+		Players:     2,
+		Description: "Keep the ball in play with your paddles.",
+		HighScores:  true,
+		Recordable:  true,
+		Keys:        bindings,
+		New:         initialModel,
+		Flags:       flags,
+	})
 }
 
-func Run() {
-	p := tea.NewProgram(initialModel())
+func flags(fs *flag.FlagSet) func(game.Options) (tea.Model, error) {
+	width := fs.Int("width", defaultWidth, "width of the field")
+	height := fs.Int("height", defaultHeight, "height of the field")
 
-	go func() {
-		for {
-			time.Sleep(300 * time.Millisecond)
-			p.Send(moveBallMsg{})
+	return func(game.Options) (tea.Model, error) {
+		if *width < minSize || *height < minSize {
+			return nil, fmt.Errorf("the field must be at least %dx%d", minSize, minSize)
 		}
-	}()
 
-	if _, err := p.Run(); err != nil {
-		panic(err)
+		return newModel(*width, *height), nil
 	}
 }
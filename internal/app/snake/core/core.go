@@ -0,0 +1,172 @@
+// Package core holds the rules of snake, free of any rendering and timing, so
+// that they can be tested on their own and played by bots. The host decides
+// how often the snake moves forward.
+package core
+
+import (
+	"errors"
+	"math/rand/v2"
+)
+
+// Vector is a square of the board, or a direction.
+type Vector struct {
+	X, Y int
+}
+
+func (v Vector) add(other Vector) Vector {
+	return Vector{v.X + other.X, v.Y + other.Y}
+}
+
+// The directions the snake can go in.
+var (
+	Up    = Vector{0, -1}
+	Down  = Vector{0, 1}
+	Left  = Vector{-1, 0}
+	Right = Vector{1, 0}
+)
+
+// Action is something the snake can do: turn up, down, left or right, or move
+// forward one square.
+type Action int
+
+const (
+	TurnUp Action = iota
+	TurnDown
+	TurnLeft
+	TurnRight
+	Forward
+)
+
+// turns are the directions of the turning actions.
+var turns = map[Action]Vector{
+	TurnUp:    Up,
+	TurnDown:  Down,
+	TurnLeft:  Left,
+	TurnRight: Right,
+}
+
+// Outcome tells whether the game is over.
+type Outcome int
+
+const (
+	Playing Outcome = iota
+
+	// Crashed is the outcome once the snake has hit a wall or itself.
+	Crashed
+)
+
+// State is a position of the game. The food is placed with rand, so two games
+// played with generators in the same state get the same food.
+type State struct {
+	Size Vector
+
+	// Body holds the squares of the snake, its head first.
+	Body []Vector
+	Dir  Vector
+	Food Vector
+
+	crashed bool
+	rand    *rand.Rand
+}
+
+// New returns a one square snake going right on a board of the given size.
+func New(size Vector, r *rand.Rand) State {
+	s := State{
+		Size: size,
+		Body: []Vector{{min(6, size.X/2), min(6, size.Y/2)}},
+		Dir:  Right,
+		rand: r,
+	}
+	s.placeFood()
+
+	return s
+}
+
+// Legal returns the actions the snake can take: moving forward and turning in
+// any direction but back. There are none once the game is over.
+func (s State) Legal() []Action {
+	if s.crashed {
+		return nil
+	}
+
+	var actions []Action
+	for _, a := range []Action{TurnUp, TurnDown, TurnLeft, TurnRight} {
+		if turns[a] != s.back() {
+			actions = append(actions, a)
+		}
+	}
+
+	return append(actions, Forward)
+}
+
+// Step turns the snake or moves it forward. Turning back is refused. A snake
+// moving onto the food grows by a square and the food moves elsewhere.
+func (s *State) Step(a Action) error {
+	if s.crashed {
+		return errors.New("snake: the game is over")
+	}
+
+	if a != Forward {
+		dir, ok := turns[a]
+		if !ok {
+			return errors.New("snake: unknown action")
+		}
+		if dir == s.back() {
+			return errors.New("snake: can't turn back")
+		}
+
+		s.Dir = dir
+		return nil
+	}
+
+	head := s.Body[0].add(s.Dir)
+	body := append([]Vector{head}, s.Body...)
+	if head != s.Food {
+		body = body[:len(body)-1]
+	}
+	s.Body = body
+
+	if head.X < 0 || head.X >= s.Size.X || head.Y < 0 || head.Y >= s.Size.Y {
+		s.crashed = true
+		return nil
+	}
+
+	// The snake can't bite the square right behind its head.
+	for i, b := range s.Body {
+		if i >= 2 && b == head {
+			s.crashed = true
+			return nil
+		}
+	}
+
+	if head == s.Food {
+		s.placeFood()
+	}
+
+	return nil
+}
+
+// Outcome returns Crashed once the snake has hit a wall or itself.
+func (s State) Outcome() Outcome {
+	if s.crashed {
+		return Crashed
+	}
+
+	return Playing
+}
+
+// Score returns the length of the snake.
+func (s State) Score() int {
+	return len(s.Body)
+}
+
+func (s State) back() Vector {
+	return Vector{-s.Dir.X, -s.Dir.Y}
+}
+
+func (s *State) placeFood() {
+	s.Food = Vector{
+		X: s.rand.IntN(s.Size.X),
+		Y: s.rand.IntN(s.Size.Y),
+	}
+}
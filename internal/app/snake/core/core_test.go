@@ -0,0 +1,65 @@
+package core
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func newRand() *rand.Rand {
+	return rand.New(rand.NewPCG(1, 2))
+}
+
+func TestTurn(t *testing.T) {
+	s := New(Vector{10, 10}, newRand())
+
+	if err := s.Step(TurnLeft); err == nil {
+		t.Error("expected turning back to be refused")
+	}
+	if len(s.Legal()) != 4 {
+		t.Errorf("expected three turns and moving forward, got %v", s.Legal())
+	}
+
+	if err := s.Step(TurnUp); err != nil || s.Dir != Up {
+		t.Errorf("expected the snake to turn up, got %v and %v", err, s.Dir)
+	}
+}
+
+func TestCrash(t *testing.T) {
+	s := New(Vector{5, 5}, newRand())
+	s.Food = Vector{-1, -1}
+
+	for s.Outcome() == Playing {
+		if err := s.Step(Forward); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if s.Body[0] != (Vector{5, 2}) {
+		t.Errorf("expected the snake to crash into the right wall, got %v", s.Body[0])
+	}
+	if s.Legal() != nil || s.Step(Forward) == nil {
+		t.Error("expected no move after the crash")
+	}
+}
+
+func TestEat(t *testing.T) {
+	s := New(Vector{10, 10}, newRand())
+	s.Food = s.Body[0].add(Right)
+
+	s.Step(Forward)
+	if s.Score() != 2 || s.Food == s.Body[0] {
+		t.Errorf("expected the snake to grow and the food to move, got %v and %v", s.Body, s.Food)
+	}
+}
+
+func TestBite(t *testing.T) {
+	s := New(Vector{10, 10}, newRand())
+	s.Body = []Vector{{5, 5}, {4, 5}, {4, 6}, {5, 6}, {6, 6}}
+	s.Food = Vector{0, 0}
+
+	s.Step(TurnDown)
+	s.Step(Forward)
+	if s.Outcome() != Crashed {
+		t.Error("expected the snake to bite itself")
+	}
+}
@@ -1,151 +1,176 @@
 package snake
 
 import (
+	"flag"
 	"fmt"
-	"math/rand/v2"
+	"strings"
 	"time"
 
+	"github.com/Kaamkiya/gg/internal/app/snake/core"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/theme"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 type moveMsg struct{}
 
-type vector struct {
-	x int
-	y int
+// bindings are the default keys of the snake's actions.
+var bindings = []game.Binding{
+	{Action: "up", Keys: []string{"k", "up"}, Help: "move up"},
+	{Action: "down", Keys: []string{"j", "down"}, Help: "move down"},
+	{Action: "left", Keys: []string{"h", "left"}, Help: "move left"},
+	{Action: "right", Keys: []string{"l", "right"}, Help: "move right"},
+	{Action: "pause", Keys: []string{game.PauseKey}, Help: "pause"},
+	{Action: "quit", Keys: []string{"q"}, Help: "quit"},
 }
 
-func (v vector) add(other vector) vector {
-	return vector{
-		x: v.x + other.x,
-		y: v.y + other.y,
-	}
-}
+const (
+	// moveInterval is the time between two steps of the snake.
+	moveInterval = 200 * time.Millisecond
 
-func (v vector) equals(other vector) bool {
-	return v.x == other.x && v.y == other.y
-}
+	defaultSize = 20
+	minSize     = 5
 
-var (
-	dirUp    = vector{0, -1}
-	dirDown  = vector{0, 1}
-	dirLeft  = vector{-1, 0}
-	dirRight = vector{1, 0}
+	// chromeWidth and chromeHeight are the columns and rows of the view
+	// around the board: its border, the score and the lines below it.
+	chromeWidth  = 2
+	chromeHeight = 6
 )
 
-type player struct {
-	body  []vector
-	dir   vector
-	style lipgloss.Style
+func move() tea.Cmd {
+	return game.Tick(moveInterval, moveMsg{})
 }
 
-func (p *player) move(m model, foodPos vector) {
-	head := p.body[0].add(p.dir)
-	p.body = append([]vector{head}, p.body...)
-	if !head.equals(foodPos) {
-		p.body = p.body[:len(p.body)-1]
-	}
-}
-
-func (p player) headChar() rune {
-	if p.dir.equals(vector{0, -1}) {
+// headChar draws the head of a snake going in direction dir.
+func headChar(dir core.Vector) rune {
+	switch dir {
+	case core.Up:
 		return '^'
-	}
-	if p.dir.equals(vector{0, 1}) {
+	case core.Down:
 		return 'v'
-	}
-	if p.dir.equals(vector{1, 0}) {
+	case core.Right:
 		return '>'
 	}
 	return '<'
 }
 
-type model struct {
-	foodPos   vector
-	foodStyle lipgloss.Style
-	player    player
+// turns are the snake's actions of the player's actions.
+var turns = map[string]core.Action{
+	"up":    core.TurnUp,
+	"down":  core.TurnDown,
+	"left":  core.TurnLeft,
+	"right": core.TurnRight,
 }
 
-func (m *model) setRandomFoodPos() {
-	m.foodPos = vector{
-		x: rand.IntN(20),
-		y: rand.IntN(20),
-	}
+type model struct {
+	opts  game.Options
+	state core.State
+
+	// want is the size of the board asked for. Until the snake first
+	// moves, the board shrinks to fit the terminal if it is too small.
+	want  core.Vector
+	moved bool
+
+	foodStyle  lipgloss.Style
+	snakeStyle lipgloss.Style
+	keys       game.KeyMap
+	pause      game.Pause
 }
 
 func (m model) Init() tea.Cmd {
-	return nil
+	return move()
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	over := m.state.Outcome() != core.Playing
+	if !over {
+		if cmd, handled, resumed := m.pause.Update(msg); handled {
+			if resumed {
+				return m, move()
+			}
+			return m, cmd
+		}
+	}
+
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		if !m.moved {
+			return m.fit(msg), nil
+		}
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		action := m.keys.Action(msg)
+		if action == "quit" {
 			return m, tea.Quit
-		case "k", "up":
-			if m.player.dir != dirDown {
-				m.player.dir = dirUp
-			}
-		case "j", "down":
-			if m.player.dir != dirUp {
-				m.player.dir = dirDown
-			}
-		case "h", "left":
-			if m.player.dir != dirRight {
-				m.player.dir = dirLeft
-			}
-		case "l", "right":
-			if m.player.dir != dirLeft {
-				m.player.dir = dirRight
-			}
 		}
-	case moveMsg:
-		m.player.move(m, m.foodPos)
 
-		head := m.player.body[0]
-
-		if head.x >= 20 || head.x < 0 || head.y < 0 || head.y >= 20 {
-			return m, tea.Quit
+		if over || !m.pause.Running() {
+			return m, nil
 		}
 
-		for i, b := range m.player.body {
-			if i < 2 {
-				continue
-			}
-			if b.equals(head) {
-				return m, tea.Quit
-			}
+		// Turning back is refused.
+		if turn, ok := turns[action]; ok {
+			m.state.Step(turn)
+		}
+	case moveMsg:
+		// The snake starts moving again when the game resumes.
+		if over || !m.pause.Running() {
+			return m, nil
 		}
 
-		if head.x == m.foodPos.x && head.y == m.foodPos.y {
-			m.setRandomFoodPos()
+		m.moved = true
+		m.state.Step(core.Forward)
+
+		if m.state.Outcome() == core.Crashed {
+			return m, game.Over(game.Result{Score: m.state.Score()})
 		}
+
+		return m, move()
 	}
 
 	return m, nil
 }
 
+// fit starts the round over on the largest board up to the size asked for that
+// fits in a terminal of the given size. The round is the one played with the
+// same seed on a board of that size.
+func (m model) fit(msg tea.WindowSizeMsg) model {
+	size := core.Vector{
+		X: max(min(m.want.X, msg.Width-chromeWidth), minSize),
+		Y: max(min(m.want.Y, msg.Height-chromeHeight), minSize),
+	}
+	if size == m.state.Size {
+		return m
+	}
+
+	fitted := newModel(game.Seeded(m.opts.Seed), size)
+	fitted.want = m.want
+	fitted.pause = m.pause
+	return fitted
+}
+
 func (m model) View() string {
-	s := "----------------------\n"
+	size := m.state.Size
+	border := strings.Repeat("-", size.X+2) + "\n"
+	s := border
 
-	for y := 0; y < 20; y++ {
+	for y := 0; y < size.Y; y++ {
 		s += "|"
-		for x := 0; x < 20; x++ {
+		for x := 0; x < size.X; x++ {
 			drew := false
-			for i, b := range m.player.body {
-				if b.x == x && b.y == y {
+			for i, b := range m.state.Body {
+				if b.X == x && b.Y == y {
 					if i == 0 {
-						s += m.player.style.Render(string(m.player.headChar()))
+						s += m.snakeStyle.Render(string(headChar(m.state.Dir)))
 					} else {
-						s += m.player.style.Render("*")
+						s += m.snakeStyle.Render("*")
 					}
 					drew = true
 				}
 			}
 			if !drew {
-				if x == m.foodPos.x && y == m.foodPos.y {
+				if x == m.state.Food.X && y == m.state.Food.Y {
 					s += m.foodStyle.Render("0")
 					drew = true
 				}
@@ -157,37 +182,63 @@ func (m model) View() string {
 		s += "|\n"
 	}
 
-	s += "----------------------\n"
-	s += fmt.Sprintf("Score: %d\n", len(m.player.body))
+	s += border
+	s += fmt.Sprintf("Score: %d\n", m.state.Score())
+	if line := m.pause.View(); line != "" {
+		s += "\n" + line + "\n"
+	}
+	if m.state.Outcome() == core.Crashed {
+		s += fmt.Sprintf("\nGame over! Press %s to quit.\nSeed: %d", m.keys.Keys("quit"), m.opts.Seed)
+		if size != m.want {
+			s += fmt.Sprintf(" on a %dx%d board", size.X, size.Y)
+		}
+		s += "\n"
+	}
 	return s
 }
 
-func initialModel() tea.Model {
+func initialModel(opts game.Options) tea.Model {
+	return newModel(opts, core.Vector{X: defaultSize, Y: defaultSize})
+}
+
+func newModel(opts game.Options, size core.Vector) model {
+	keys := game.Keys("snake")
+	t := theme.Current()
+
 	return model{
-		foodPos: vector{
-			x: rand.IntN(20),
-			y: rand.IntN(20),
-		},
-		foodStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")),
-		player: player{
-			body:  []vector{{6, 6}},
-			dir:   dirRight,
-			style: lipgloss.NewStyle().Foreground(lipgloss.Color("32")),
-		},
+		keys:       keys,
+		pause:      game.NewPause(keys),
+		opts:       opts,
+		state:      core.New(size, opts.Rand),
+		want:       size,
+		foodStyle:  t.Accent,
+		snakeStyle: t.Players[0],
 	}
 }
 
-func Run() {
-	p := tea.NewProgram(initialModel())
+func init() {
+	game.Register(game.Game{
+		ID:          "snake",
+		Name:        "snake",
+		Players:     1,
+		Description: "Eat the food and grow without biting yourself.",
+		HighScores:  true,
+		Recordable:  true,
+		Keys:        bindings,
+		New:         initialModel,
+		Flags:       flags,
+	})
+}
+
+func flags(fs *flag.FlagSet) func(game.Options) (tea.Model, error) {
+	width := fs.Int("width", defaultSize, "width of the board")
+	height := fs.Int("height", defaultSize, "height of the board")
 
-	go func() {
-		for {
-			p.Send(moveMsg{})
-			time.Sleep(200 * time.Millisecond)
+	return func(opts game.Options) (tea.Model, error) {
+		if *width < minSize || *height < minSize {
+			return nil, fmt.Errorf("the board must be at least %dx%d", minSize, minSize)
 		}
-	}()
 
-	if _, err := p.Run(); err != nil {
-		panic(err)
+		return newModel(opts, core.Vector{X: *width, Y: *height}), nil
 	}
 }
@@ -0,0 +1,167 @@
+package sudoku
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/app/sudoku/core"
+	"github.com/Kaamkiya/gg/internal/theme"
+)
+
+// board draws the grid, each square on the given number of lines. Lines of
+// '|' and '-' mark the borders of the regions, and dotted ones those of the
+// cages, whose sums go on the border above their top left square.
+func (m model) board(lines int) string {
+	t := theme.Current()
+
+	var b strings.Builder
+	for i := -1; i < core.Size; i++ {
+		if i >= 0 {
+			for line := range lines {
+				for j, c := range m.state.Grid[i] {
+					if j != 0 {
+						b.WriteString(m.wall(i, j-1, i, j))
+					}
+
+					cell := m.cell(i, j, line, lines)
+
+					// Conflicts show as soon as they are made, and
+					// checking tells the numbers filled in right from the
+					// wrong ones.
+					given := m.state.Puzzle[i][j] != 0
+					switch {
+					case j == m.cursorx && i == m.cursory:
+						b.WriteString(t.Cursor.Render(cell))
+					case c == 0 && m.state.Notes[i][j] != 0:
+						b.WriteString(t.Muted.Render(cell))
+					case m.state.Conflicts(i, j) && !given, m.checking && m.wrong(i, j):
+						b.WriteString(t.Bad.Render(cell))
+					case m.checking && c != 0 && !given:
+						b.WriteString(t.Good.Render(cell))
+					default:
+						b.WriteString(cell)
+					}
+				}
+				b.WriteString("\n")
+			}
+		}
+
+		if i < core.Size-1 {
+			if rule, ok := m.rule(i); ok {
+				b.WriteString(rule + "\n")
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// wall returns what goes between two squares side by side: '|' if they are in
+// different regions, ':' if they are in different cages.
+func (m model) wall(row1, col1, row2, col2 int) string {
+	r := m.state.Rules
+	switch {
+	case r.Region(row1, col1) != r.Region(row2, col2):
+		return "|"
+	case r.Cage(row1, col1) != r.Cage(row2, col2):
+		return ":"
+	}
+
+	return " "
+}
+
+// edge returns what the border between the square at row and col and the one
+// below it is drawn with: '-' between regions and '.' between cages. Row -1
+// is above the grid.
+func (m model) edge(row, col int) string {
+	if row < 0 {
+		return " "
+	}
+
+	switch m.wall(row, col, row+1, col) {
+	case "|":
+		return "-"
+	case ":":
+		return "."
+	}
+	return " "
+}
+
+// rule returns the border below the given row, -1 for the one above the grid,
+// and reports whether there is anything to draw under the squares. Borders
+// that don't run under any square are left out, such as the one above the
+// grid when there are no cage sums to go on it.
+func (m model) rule(row int) (string, bool) {
+	var b strings.Builder
+	drawn := false
+	for col := range core.Size {
+		if col != 0 {
+			b.WriteString(m.junction(row, col-1))
+		}
+
+		label, edge := m.sum(row+1, col), m.edge(row, col)
+		b.WriteString(label + strings.Repeat(edge, 3-len(label)))
+		drawn = drawn || label != "" || edge != " "
+	}
+
+	return b.String(), drawn
+}
+
+// junction returns what goes where the borders around the square at row and
+// col and the one to its right meet the border below them.
+func (m model) junction(row, col int) string {
+	left, right := m.edge(row, col), m.edge(row, col+1)
+	up, down := " ", m.wall(row+1, col, row+1, col+1)
+	if row >= 0 {
+		up = m.wall(row, col, row, col+1)
+	}
+
+	across := left == "-" || right == "-"
+	through := up == "|" || down == "|"
+	switch {
+	case across && through:
+		return "+"
+	case through:
+		return "|"
+	case across:
+		return "-"
+	case left == "." || right == "." || up == ":" || down == ":":
+		return "."
+	}
+
+	return " "
+}
+
+// sum returns the sum of the cage whose top left square is at row and col, or
+// nothing if there is no such cage.
+func (m model) sum(row, col int) string {
+	c := m.state.Rules.Cage(row, col)
+	if c < 0 {
+		return ""
+	}
+
+	for _, sq := range m.state.Rules.Cages[c].Squares {
+		if sq.Row < row || sq.Row == row && sq.Col < col {
+			return ""
+		}
+	}
+	return strconv.Itoa(m.state.Rules.Cages[c].Sum)
+}
+
+// blank returns what shows in an empty square: the diagonals it is on, if
+// they must hold 1-9 too, or a dot.
+func (m model) blank(row, col int) string {
+	if r := m.state.Rules; r != nil && r.Diagonal {
+		main, anti := row == col, row+col == core.Size-1
+		switch {
+		case main && anti:
+			return "X"
+		case main:
+			return "\\"
+		case anti:
+			return "/"
+		}
+	}
+
+	return "."
+}
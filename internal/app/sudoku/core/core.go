@@ -0,0 +1,194 @@
+// Package core holds the rules of sudoku, free of any rendering, so that they
+// can be tested on their own and played by bots.
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Size is the number of rows, columns and regions of the grid.
+const Size = 9
+
+// Grid holds the numbers by row and column, 0 for an empty square.
+type Grid [Size][Size]int
+
+// Move fills in the square at Row and Col with Value, or clears it if Value
+// is 0.
+type Move struct {
+	Row, Col int
+	Value    int
+}
+
+// Notes are the numbers pencilled in a square as the ones that could go
+// there, one bit each.
+type Notes uint16
+
+// AllNotes holds every number from 1 to 9.
+const AllNotes Notes = 0b11_1111_1110
+
+// Has reports whether v is pencilled in.
+func (n Notes) Has(v int) bool {
+	return n&(1<<v) != 0
+}
+
+// Outcome tells whether the puzzle is solved.
+type Outcome int
+
+const (
+	Playing Outcome = iota
+	Solved
+)
+
+// State is a puzzle and the numbers filled in so far.
+type State struct {
+	// Puzzle holds the given numbers, which can't be changed.
+	Puzzle Grid
+
+	// Grid holds the given numbers along with those filled in.
+	Grid Grid
+
+	// Notes holds the numbers pencilled in each square. They are kept
+	// when the square is filled in, but only matter while it is empty.
+	Notes [Size][Size]Notes
+
+	// Rules are the constraints of the variant played, nil for classic
+	// sudoku. They don't change during a game.
+	Rules *Rules
+}
+
+// New returns the classic puzzle with nothing filled in yet.
+func New(puzzle Grid) State {
+	return State{Puzzle: puzzle, Grid: puzzle}
+}
+
+// NewVariant returns the puzzle of the variant with the given rules, with
+// nothing filled in yet.
+func NewVariant(puzzle Grid, rules *Rules) State {
+	return State{Puzzle: puzzle, Grid: puzzle, Rules: rules}
+}
+
+// Legal returns every move that changes a square that isn't given. There are
+// none once the puzzle is solved.
+func (s State) Legal() []Move {
+	if s.Outcome() == Solved {
+		return nil
+	}
+
+	var moves []Move
+	for row := range Size {
+		for col := range Size {
+			if s.Puzzle[row][col] != 0 {
+				continue
+			}
+
+			for n := 0; n <= Size; n++ {
+				if n != s.Grid[row][col] {
+					moves = append(moves, Move{row, col, n})
+				}
+			}
+		}
+	}
+
+	return moves
+}
+
+// Step plays the move. Given squares can't be changed, and nothing can once
+// the puzzle is solved.
+func (s *State) Step(m Move) error {
+	switch {
+	case s.Outcome() == Solved:
+		return errors.New("sudoku: the puzzle is solved")
+	case m.Row < 0 || m.Row >= Size || m.Col < 0 || m.Col >= Size:
+		return fmt.Errorf("sudoku: no square at row %d, column %d", m.Row+1, m.Col+1)
+	case m.Value < 0 || m.Value > Size:
+		return fmt.Errorf("sudoku: %d isn't a number from 1 to 9", m.Value)
+	case s.Puzzle[m.Row][m.Col] != 0:
+		return fmt.Errorf("sudoku: the square at row %d, column %d is given", m.Row+1, m.Col+1)
+	}
+
+	s.Grid[m.Row][m.Col] = m.Value
+	if m.Value != 0 {
+		for _, sq := range s.Rules.Peers(m.Row, m.Col) {
+			s.Notes[sq.Row][sq.Col] &^= 1 << m.Value
+		}
+	}
+	return nil
+}
+
+// Note pencils n in the empty square at row and col, or rubs it out if it is
+// there already.
+func (s *State) Note(row, col, n int) error {
+	switch {
+	case row < 0 || row >= Size || col < 0 || col >= Size:
+		return fmt.Errorf("sudoku: no square at row %d, column %d", row+1, col+1)
+	case n < 1 || n > Size:
+		return fmt.Errorf("sudoku: %d isn't a number from 1 to 9", n)
+	case s.Grid[row][col] != 0:
+		return fmt.Errorf("sudoku: the square at row %d, column %d is filled in", row+1, col+1)
+	}
+
+	s.Notes[row][col] ^= 1 << n
+	return nil
+}
+
+// FillNotes pencils in every empty square the numbers that aren't yet in its
+// row, column, region or any other group of squares it is in, replacing its
+// notes.
+func (s *State) FillNotes() {
+	for row := range Size {
+		for col := range Size {
+			if s.Grid[row][col] != 0 {
+				continue
+			}
+
+			notes := AllNotes
+			for _, sq := range s.Rules.Peers(row, col) {
+				notes &^= 1 << s.Grid[sq.Row][sq.Col]
+			}
+			s.Notes[row][col] = notes
+		}
+	}
+}
+
+// Conflicts reports whether the number at row and col is also in its row,
+// column, region or any other group of squares it is in, or whether it takes
+// the numbers of its cage over its sum, or short of it once the cage is full.
+func (s State) Conflicts(row, col int) bool {
+	n := s.Grid[row][col]
+	if n == 0 {
+		return false
+	}
+
+	for _, sq := range s.Rules.Peers(row, col) {
+		if s.Grid[sq.Row][sq.Col] == n {
+			return true
+		}
+	}
+
+	if c := s.Rules.Cage(row, col); c >= 0 {
+		cage := s.Rules.Cages[c]
+		sum, full := 0, true
+		for _, sq := range cage.Squares {
+			sum += s.Grid[sq.Row][sq.Col]
+			full = full && s.Grid[sq.Row][sq.Col] != 0
+		}
+		return sum > cage.Sum || full && sum != cage.Sum
+	}
+
+	return false
+}
+
+// Outcome returns Solved once every square is filled in without conflicts,
+// so that every row, column and region holds 1-9.
+func (s State) Outcome() Outcome {
+	for row := range Size {
+		for col := range Size {
+			if s.Grid[row][col] == 0 || s.Conflicts(row, col) {
+				return Playing
+			}
+		}
+	}
+
+	return Solved
+}
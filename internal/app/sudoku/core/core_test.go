@@ -0,0 +1,166 @@
+package core
+
+import "testing"
+
+// solution is a valid grid, shifting each row of 1-9 into place.
+func solution() Grid {
+	var g Grid
+	for i := range Size {
+		for j := range Size {
+			g[i][j] = (i*3+i/3+j)%9 + 1
+		}
+	}
+	return g
+}
+
+func TestOutcome(t *testing.T) {
+	g := solution()
+	if got := New(g).Outcome(); got != Solved {
+		t.Fatal("expected a valid grid to be solved")
+	}
+
+	g[0][0], g[0][1] = g[0][1], g[0][0]
+	if got := New(g).Outcome(); got != Playing {
+		t.Fatal("expected a grid with a repeated number in a column not to be solved")
+	}
+
+	g = solution()
+	g[4][4] = 0
+	if got := New(g).Outcome(); got != Playing {
+		t.Fatal("expected a grid with an empty square not to be solved")
+	}
+}
+
+func TestStep(t *testing.T) {
+	puzzle := solution()
+	want := puzzle[4][4]
+	puzzle[4][4] = 0
+	puzzle[8][8] = 0
+	s := New(puzzle)
+
+	if err := s.Step(Move{0, 0, 5}); err == nil {
+		t.Error("expected a given square to be refused")
+	}
+	if err := s.Step(Move{4, 4, 10}); err == nil {
+		t.Error("expected a number above 9 to be refused")
+	}
+	if got := len(s.Legal()); got != 2*9 {
+		t.Errorf("expected 9 moves for each of the 2 empty squares, got %d", got)
+	}
+
+	if err := s.Step(Move{4, 4, want}); err != nil {
+		t.Fatal(err)
+	}
+	if s.Grid[4][4] != want || s.Puzzle[4][4] != 0 {
+		t.Error("expected the number to be filled in, leaving the puzzle alone")
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	puzzle := solution()
+	puzzle[4][4] = 0
+	s := New(puzzle)
+
+	for n := 1; n <= Size; n++ {
+		s.Step(Move{4, 4, n})
+		if got, want := s.Conflicts(4, 4), n != solution()[4][4]; got != want {
+			t.Errorf("%d: expected a conflict to be %t, got %t", n, want, got)
+		}
+	}
+
+	s.Step(Move{4, 4, 0})
+	if s.Conflicts(4, 4) {
+		t.Error("expected an empty square not to conflict")
+	}
+}
+
+func TestNotes(t *testing.T) {
+	puzzle := solution()
+	want := puzzle[4][4]
+	puzzle[4][4], puzzle[4][5], puzzle[0][4] = 0, 0, 0
+	s := New(puzzle)
+
+	if err := s.Note(4, 4, want); err != nil || !s.Notes[4][4].Has(want) {
+		t.Fatalf("expected %d to be pencilled in, got %v", want, err)
+	}
+	if s.Note(4, 4, want); s.Notes[4][4].Has(want) {
+		t.Error("expected a second note to rub it out")
+	}
+	if err := s.Note(0, 0, 1); err == nil {
+		t.Error("expected a filled in square to be refused")
+	}
+
+	s.FillNotes()
+	if got := s.Notes[4][4]; got != 1<<want {
+		t.Errorf("expected only %d to be pencilled in, got %b", want, got)
+	}
+	if s.Notes[0][0] != 0 {
+		t.Error("expected the given squares to be left alone")
+	}
+
+	// Filling in a number rubs it out of the notes of its row, column and
+	// box.
+	s.Step(Move{4, 4, want})
+	if s.Notes[4][5].Has(want) || s.Notes[0][4].Has(want) {
+		t.Error("expected the number to be rubbed out of the notes around it")
+	}
+}
+
+func TestVariants(t *testing.T) {
+	g := solution()
+	diagonal := NewVariant(g, &Rules{Regions: Boxes(), Diagonal: true})
+	if New(g).Conflicts(1, 1) || !diagonal.Conflicts(1, 1) {
+		t.Error("expected the repeated numbers of the diagonal to conflict")
+	}
+
+	puzzle := g
+	puzzle[0][0], puzzle[0][1] = 0, 0
+	killer := NewVariant(puzzle, &Rules{
+		Regions: Boxes(),
+		Cages:   []Cage{{Squares: []Square{{0, 0}, {0, 1}}, Sum: g[0][0] + g[0][1]}},
+	})
+	killer.Step(Move{0, 0, g[0][0]})
+	if killer.Conflicts(0, 0) {
+		t.Error("expected a cage short of its sum not to conflict while it isn't full")
+	}
+	killer.Step(Move{0, 1, g[0][1]})
+	if killer.Conflicts(0, 1) || killer.Outcome() != Solved {
+		t.Error("expected the numbers of the cage to add up")
+	}
+
+	killer.Rules.Cages[0].Sum++
+	if !killer.Conflicts(0, 1) {
+		t.Error("expected a full cage at the wrong sum to conflict")
+	}
+
+	killer.Rules.Cages[0].Sum = g[0][0]
+	killer.Grid[0][0] = 0
+	if !killer.Conflicts(0, 1) {
+		t.Error("expected a cage over its sum to conflict")
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	if err := (&Rules{Regions: Boxes()}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	uneven := Boxes()
+	uneven[0][0] = 1
+	if err := (&Rules{Regions: uneven}).Validate(); err == nil {
+		t.Error("expected a region of 8 squares to be refused")
+	}
+
+	overlapping := &Rules{Regions: Boxes(), Cages: []Cage{
+		{Squares: []Square{{0, 0}, {0, 1}}, Sum: 3},
+		{Squares: []Square{{0, 1}}, Sum: 2},
+	}}
+	if err := overlapping.Validate(); err == nil {
+		t.Error("expected a square in two cages to be refused")
+	}
+
+	impossible := &Rules{Regions: Boxes(), Cages: []Cage{{Squares: []Square{{0, 0}, {0, 1}}, Sum: 18}}}
+	if err := impossible.Validate(); err == nil {
+		t.Error("expected two squares adding up to 18 to be refused")
+	}
+}
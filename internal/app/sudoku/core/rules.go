@@ -0,0 +1,168 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Square is the position of a square of the grid.
+type Square struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+// Cage is a group of squares of killer sudoku. Its numbers can't repeat and
+// must add up to Sum.
+type Cage struct {
+	Squares []Square `json:"squares"`
+	Sum     int      `json:"sum"`
+}
+
+// Rules are the constraints of a variant of sudoku on top of its rows and
+// columns. A nil *Rules is classic sudoku.
+type Rules struct {
+	// Regions numbers the region of every square from 0 to 8. Each region
+	// must hold 1-9, like the boxes of classic sudoku, which are the
+	// regions of every variant but jigsaw sudoku.
+	Regions [Size][Size]int `json:"regions"`
+
+	// Diagonal tells whether both diagonals must hold 1-9 too.
+	Diagonal bool `json:"diagonal,omitempty"`
+
+	// Cages are the cages of killer sudoku.
+	Cages []Cage `json:"cages,omitempty"`
+}
+
+// Boxes returns the regions of classic sudoku, its nine 3x3 boxes.
+func Boxes() [Size][Size]int {
+	var regions [Size][Size]int
+	for row := range Size {
+		for col := range Size {
+			regions[row][col] = row/3*3 + col/3
+		}
+	}
+	return regions
+}
+
+// Region returns the region of the square at row and col.
+func (r *Rules) Region(row, col int) int {
+	if r == nil {
+		return row/3*3 + col/3
+	}
+	return r.Regions[row][col]
+}
+
+// Cage returns the index of the cage holding the square at row and col, or -1
+// if it isn't in one.
+func (r *Rules) Cage(row, col int) int {
+	if r == nil {
+		return -1
+	}
+
+	for i, cage := range r.Cages {
+		for _, sq := range cage.Squares {
+			if sq.Row == row && sq.Col == col {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Peers returns the other squares of the row, column, region, diagonals and
+// cage of the square at row and col, which can't hold the same number. Some
+// squares come more than once.
+func (r *Rules) Peers(row, col int) []Square {
+	var peers []Square
+	for k := range Size {
+		if k != col {
+			peers = append(peers, Square{row, k})
+		}
+		if k != row {
+			peers = append(peers, Square{k, col})
+		}
+	}
+
+	region := r.Region(row, col)
+	for i := range Size {
+		for j := range Size {
+			if (i != row || j != col) && r.Region(i, j) == region {
+				peers = append(peers, Square{i, j})
+			}
+		}
+	}
+
+	if r == nil {
+		return peers
+	}
+
+	if r.Diagonal {
+		for k := range Size {
+			if row == col && k != row {
+				peers = append(peers, Square{k, k})
+			}
+			if row+col == Size-1 && k != row {
+				peers = append(peers, Square{k, Size - 1 - k})
+			}
+		}
+	}
+
+	if c := r.Cage(row, col); c >= 0 {
+		for _, sq := range r.Cages[c].Squares {
+			if sq.Row != row || sq.Col != col {
+				peers = append(peers, sq)
+			}
+		}
+	}
+
+	return peers
+}
+
+// Validate checks that every region has nine squares and that the cages are
+// made of different squares of the grid, each in one cage at most, with sums
+// their numbers can add up to.
+func (r *Rules) Validate() error {
+	if r == nil {
+		return nil
+	}
+
+	var sizes [Size]int
+	for _, row := range r.Regions {
+		for _, region := range row {
+			if region < 0 || region >= Size {
+				return fmt.Errorf("sudoku: no region %d", region+1)
+			}
+			sizes[region]++
+		}
+	}
+	for region, size := range sizes {
+		if size != Size {
+			return fmt.Errorf("sudoku: region %d has %d squares, want %d", region+1, size, Size)
+		}
+	}
+
+	var caged [Size][Size]bool
+	for _, cage := range r.Cages {
+		if len(cage.Squares) == 0 || len(cage.Squares) > Size {
+			return errors.New("sudoku: a cage must have 1 to 9 squares")
+		}
+
+		// The smallest and largest sums of as many different numbers.
+		n := len(cage.Squares)
+		if low, high := n*(n+1)/2, n*(2*Size-n+1)/2; cage.Sum < low || cage.Sum > high {
+			return fmt.Errorf("sudoku: %d squares can't add up to %d", n, cage.Sum)
+		}
+
+		for _, sq := range cage.Squares {
+			if sq.Row < 0 || sq.Row >= Size || sq.Col < 0 || sq.Col >= Size {
+				return fmt.Errorf("sudoku: no square at row %d, column %d", sq.Row+1, sq.Col+1)
+			}
+			if caged[sq.Row][sq.Col] {
+				return fmt.Errorf("sudoku: the square at row %d, column %d is in two cages", sq.Row+1, sq.Col+1)
+			}
+			caged[sq.Row][sq.Col] = true
+		}
+	}
+
+	return nil
+}
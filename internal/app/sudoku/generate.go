@@ -0,0 +1,84 @@
+package sudoku
+
+import (
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/app/sudoku/solver"
+	"github.com/Kaamkiya/gg/internal/app/sudoku/sudokugenerator"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// generatingModel generates a puzzle away from the UI, since the hardest ones
+// take seconds, and starts it once it is ready.
+type generatingModel struct {
+	keys game.KeyMap
+
+	// what is the puzzle being generated, e.g. "a hard killer puzzle".
+	what     string
+	generate func() model
+
+	// export is the path to write the puzzle to, if any.
+	export string
+}
+
+// generating generates a puzzle of variant v and difficulty d with the given
+// undo policy, and writes it to the file at export, if it is set.
+func generating(opts game.Options, v sudokugenerator.Variant, d solver.Difficulty, policy game.UndoPolicy, export string) generatingModel {
+	what := d.String()
+	if v != sudokugenerator.Classic {
+		what += " " + v.String()
+	}
+	if strings.ContainsRune("aeiou", rune(what[0])) {
+		what = "an " + what + " puzzle"
+	} else {
+		what = "a " + what + " puzzle"
+	}
+
+	return generatingModel{
+		keys: game.Keys("sudoku"),
+		what: what,
+		generate: func() model {
+			return newModel(opts, v, d, policy)
+		},
+		export: export,
+	}
+}
+
+// generatedMsg carries the puzzle once it is generated.
+type generatedMsg struct{ m model }
+
+func (m generatingModel) Init() tea.Cmd {
+	return func() tea.Msg {
+		return generatedMsg{m.generate()}
+	}
+}
+
+func (m generatingModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case generatedMsg:
+		puzzle := msg.m
+		switch {
+		case m.export == "":
+		case puzzle.variant != sudokugenerator.Classic:
+			puzzle.hint = "Only classic puzzles can be exported."
+		default:
+			if err := exportPuzzle(m.export, puzzle.state.Puzzle); err != nil {
+				puzzle.hint = "The puzzle couldn't be exported: " + err.Error()
+			}
+		}
+		return puzzle, puzzle.Init()
+	case tea.KeyMsg:
+		if m.keys.Action(msg) == "quit" {
+			return m, tea.Quit
+		}
+	}
+
+	return m, nil
+}
+
+func (m generatingModel) View() string {
+	return theme.Current().Muted.Render("generating "+m.what+"…") + "\n"
+}
@@ -0,0 +1,42 @@
+package sudoku
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/sudoku/solver"
+	"github.com/Kaamkiya/gg/internal/app/sudoku/sudokugenerator"
+	"github.com/Kaamkiya/gg/internal/game"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// generated waits for the puzzle m is generating and starts it.
+func generated(m tea.Model) model {
+	next, _ := m.Update(m.Init()())
+	return next.(model)
+}
+
+func TestGenerating(t *testing.T) {
+	g := generating(game.Seeded(1), sudokugenerator.Killer, solver.Easy, game.UnlimitedUndo, "")
+	if !strings.Contains(g.View(), "generating an easy killer puzzle") {
+		t.Errorf("expected the view to tell what is being generated, got %q", g.View())
+	}
+
+	m := generated(g)
+	if m.variant != sudokugenerator.Killer || m.difficulty != solver.Easy {
+		t.Errorf("expected an easy killer puzzle, got a %v %v one", m.difficulty, m.variant)
+	}
+	if daily := generated(dailyModel(game.Seeded(1))); daily.assisted {
+		t.Error("expected no help in the daily challenge")
+	}
+
+	export := filepath.Join(t.TempDir(), "puzzle.sdk")
+	if m := generated(generating(game.Seeded(1), sudokugenerator.Jigsaw, solver.Easy, game.UnlimitedUndo, export)); m.hint == "" {
+		t.Error("expected a jigsaw puzzle not to be exported")
+	}
+	if m := generated(generating(game.Seeded(1), sudokugenerator.Classic, solver.Easy, game.UnlimitedUndo, export)); m.hint != "" {
+		t.Errorf("expected the puzzle to be exported, got %q", m.hint)
+	}
+}
@@ -0,0 +1,185 @@
+package sudoku
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/app/sudoku/core"
+	"github.com/Kaamkiya/gg/internal/app/sudoku/solver"
+	"github.com/Kaamkiya/gg/internal/app/sudoku/sudokufile"
+	"github.com/Kaamkiya/gg/internal/app/sudoku/sudokugenerator"
+	"github.com/Kaamkiya/gg/internal/game"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+func flags(fs *flag.FlagSet) func(game.Options) (tea.Model, error) {
+	difficulty := fs.String("difficulty", "", "difficulty of the puzzle: "+strings.Join(solver.Difficulties, ", ")+" (asks if not set)")
+	file := fs.String("file", "", "play a puzzle from a file of puzzles of 81 squares a line, or an SDK file")
+	index := fs.Int("index", 1, "number of the puzzle to play in the -file, from 1")
+	export := fs.String("export", "", "write the puzzle to a file: an SDK or SDM file by its extension, or a line of 81 squares")
+	variant := fs.String("variant", "", "kind of sudoku: "+strings.Join(sudokugenerator.Variants, ", ")+" (classic if not set)")
+
+	return func(opts game.Options) (tea.Model, error) {
+		v := sudokugenerator.Classic
+		if *variant != "" {
+			var err error
+			if v, err = sudokugenerator.ParseVariant(*variant); err != nil {
+				return nil, err
+			}
+		}
+		if *export != "" && v != sudokugenerator.Classic {
+			return nil, errors.New("only classic puzzles can be exported")
+		}
+
+		switch {
+		case *file != "":
+			if *difficulty != "" {
+				return nil, errors.New("-difficulty can't be used with -file")
+			}
+			if *variant != "" {
+				return nil, errors.New("-variant can't be used with -file")
+			}
+
+			m, err := loadModel(opts, *file, *index)
+			if err != nil {
+				return nil, err
+			}
+			if *export != "" {
+				if err := exportPuzzle(*export, m.state.Puzzle); err != nil {
+					return nil, err
+				}
+			}
+			return m, nil
+		case *difficulty == "":
+			return setup(opts, v, *export), nil
+		}
+
+		d, err := solver.ParseDifficulty(*difficulty)
+		if err != nil {
+			return nil, err
+		}
+
+		// Hard variants take a while to generate, so that is left to the
+		// program once it has started.
+		return generating(opts, v, d, game.UnlimitedUndo, *export), nil
+	}
+}
+
+// loadModel starts the puzzle with the given number, from 1, of the file at
+// path.
+func loadModel(opts game.Options, path string, index int) (model, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return model{}, err
+	}
+	defer f.Close()
+
+	name := filepath.Base(path)
+	puzzles, err := sudokufile.Read(f)
+	if err != nil {
+		return model{}, fmt.Errorf("%s: %w", name, err)
+	}
+
+	if index < 1 || index > len(puzzles) {
+		return model{}, fmt.Errorf("%s has no puzzle %d: its puzzles are numbered from 1 to %d", name, index, len(puzzles))
+	}
+	puzzle := puzzles[index-1]
+	if err := sudokufile.Validate(puzzle); err != nil {
+		return model{}, fmt.Errorf("%s, puzzle %d: %w", name, index, err)
+	}
+
+	m := puzzleModel(opts, puzzle, nil, solver.Grade(puzzle, nil), game.UnlimitedUndo)
+	m.source = fmt.Sprintf("%s #%d", name, index)
+	return m, nil
+}
+
+// exportPuzzle writes the puzzle to the file at path, in the format of its
+// extension.
+func exportPuzzle(path string, puzzle core.Grid) error {
+	var b strings.Builder
+	sudokufile.Write(&b, puzzle, sudokufile.FormatOf(path))
+
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// setupModel asks for the variant and difficulty of the puzzle before
+// generating it.
+type setupModel struct {
+	opts       game.Options
+	form       *huh.Form
+	variant    *string
+	difficulty *string
+
+	// export is the path to write the puzzle to, if any.
+	export string
+}
+
+func newSetup(opts game.Options) tea.Model {
+	return setup(opts, sudokugenerator.Classic, "")
+}
+
+// setup asks for the variant of the puzzle, starting from v, and its
+// difficulty. The puzzle is written to the file at export once generated, if
+// it is set.
+func setup(opts game.Options, v sudokugenerator.Variant, export string) setupModel {
+	variant, difficulty := new(string), new(string)
+	*variant = v.String()
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Select a variant").
+				Options(huh.NewOptions(sudokugenerator.Variants...)...).
+				Value(variant),
+			huh.NewSelect[string]().
+				Title("Select a difficulty").
+				Options(huh.NewOptions(solver.Difficulties...)...).
+				Value(difficulty),
+		),
+	)
+
+	return setupModel{
+		opts:       opts,
+		form:       form,
+		variant:    variant,
+		difficulty: difficulty,
+		export:     export,
+	}
+}
+
+func (m setupModel) Init() tea.Cmd {
+	return m.form.Init()
+}
+
+func (m setupModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "esc" {
+		return m, tea.Quit
+	}
+
+	form, cmd := m.form.Update(msg)
+	m.form = form.(*huh.Form)
+
+	switch m.form.State {
+	case huh.StateAborted:
+		return m, tea.Quit
+	case huh.StateCompleted:
+		// The variant and difficulty were picked from lists, so they are
+		// known.
+		v, _ := sudokugenerator.ParseVariant(*m.variant)
+		d, _ := solver.ParseDifficulty(*m.difficulty)
+		g := generating(m.opts, v, d, game.UnlimitedUndo, m.export)
+		return g, g.Init()
+	}
+
+	return m, cmd
+}
+
+func (m setupModel) View() string {
+	return m.form.View()
+}
@@ -0,0 +1,44 @@
+package sudoku
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/sudoku/solver"
+	"github.com/Kaamkiya/gg/internal/game"
+)
+
+// euler is the first puzzle of Project Euler's problem 96.
+const euler = "003020600900305001001806400008102900700000008006708200002609500800203009005010300"
+
+func TestLoadAndExport(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "puzzles.txt")
+	os.WriteFile(path, []byte(strings.Repeat("0", 81)+"\n"+euler+"\n"), 0o644)
+
+	m, err := loadModel(game.Seeded(1), path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.source != "puzzles.txt #2" || m.difficulty != solver.Easy || m.state.Puzzle[0][2] != 3 {
+		t.Errorf("expected the second puzzle, got %q: %v", m.source, m.state.Puzzle)
+	}
+
+	if _, err := loadModel(game.Seeded(1), path, 1); err == nil || !strings.Contains(err.Error(), "puzzle 1: the puzzle has more than one solution") {
+		t.Errorf("expected an empty grid to be refused, got %v", err)
+	}
+	if _, err := loadModel(game.Seeded(1), path, 3); err == nil {
+		t.Error("expected a missing puzzle to be refused")
+	}
+
+	sdk := filepath.Join(dir, "euler.sdk")
+	if err := exportPuzzle(sdk, m.state.Puzzle); err != nil {
+		t.Fatal(err)
+	}
+	back, err := loadModel(game.Seeded(1), sdk, 1)
+	if err != nil || back.state.Puzzle != m.state.Puzzle {
+		t.Errorf("expected the exported puzzle to load back, got %v", err)
+	}
+}
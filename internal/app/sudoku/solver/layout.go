@@ -0,0 +1,111 @@
+package solver
+
+import (
+	"fmt"
+
+	"github.com/Kaamkiya/gg/internal/app/sudoku/core"
+)
+
+// square is the position of a square of the grid.
+type square struct {
+	row, col int
+}
+
+// unit is a group of squares that can't repeat a number: a row, a column, a
+// region, a diagonal or a cage.
+type unit struct {
+	name    string
+	squares []square
+
+	// house tells whether the unit holds every number, which cages of
+	// fewer than nine squares don't.
+	house bool
+
+	// sum is the sum of the numbers of a cage, 0 for the other units.
+	sum int
+}
+
+// layout is the units of a variant, worked out once for a whole search.
+type layout struct {
+	units []unit
+
+	// houses are the indices of the units holding every number.
+	houses []int
+
+	// unitsOf holds the units of every square, by their index.
+	unitsOf [size][size][]int
+
+	// sees tells whether two different squares share a unit.
+	sees [size][size][size][size]bool
+}
+
+// newLayout lays out the units of the variant with the given rules: the rows
+// first, then the columns, the regions, the diagonals and the cages.
+func newLayout(r *core.Rules) *layout {
+	l := &layout{}
+
+	regionName := "box"
+	if r != nil && r.Regions != core.Boxes() {
+		regionName = "region"
+	}
+
+	rows, cols, regions := make([]unit, size), make([]unit, size), make([]unit, size)
+	for i := range size {
+		rows[i] = unit{name: fmt.Sprintf("row %d", i+1), house: true}
+		cols[i] = unit{name: fmt.Sprintf("column %d", i+1), house: true}
+		regions[i] = unit{name: fmt.Sprintf("%s %d", regionName, i+1), house: true}
+	}
+	for row := range size {
+		for col := range size {
+			sq := square{row, col}
+			rows[row].squares = append(rows[row].squares, sq)
+			cols[col].squares = append(cols[col].squares, sq)
+			region := r.Region(row, col)
+			regions[region].squares = append(regions[region].squares, sq)
+		}
+	}
+	l.units = append(append(append(l.units, rows...), cols...), regions...)
+
+	if r != nil && r.Diagonal {
+		main := unit{name: "the main diagonal", house: true}
+		anti := unit{name: "the anti-diagonal", house: true}
+		for k := range size {
+			main.squares = append(main.squares, square{k, k})
+			anti.squares = append(anti.squares, square{k, size - 1 - k})
+		}
+		l.units = append(l.units, main, anti)
+	}
+
+	if r != nil {
+		for _, cage := range r.Cages {
+			u := unit{house: len(cage.Squares) == size, sum: cage.Sum}
+			for _, sq := range cage.Squares {
+				u.squares = append(u.squares, square{sq.Row, sq.Col})
+			}
+			first := u.squares[0]
+			for _, sq := range u.squares {
+				if sq.row < first.row || sq.row == first.row && sq.col < first.col {
+					first = sq
+				}
+			}
+			u.name = fmt.Sprintf("the cage of %d at row %d, column %d", cage.Sum, first.row+1, first.col+1)
+			l.units = append(l.units, u)
+		}
+	}
+
+	for i, u := range l.units {
+		if u.house {
+			l.houses = append(l.houses, i)
+		}
+		for _, a := range u.squares {
+			l.unitsOf[a.row][a.col] = append(l.unitsOf[a.row][a.col], i)
+			for _, b := range u.squares {
+				if a != b {
+					l.sees[a.row][a.col][b.row][b.col] = true
+				}
+			}
+		}
+	}
+
+	return l
+}
@@ -0,0 +1,204 @@
+package solver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/app/sudoku/core"
+)
+
+// Difficulty grades a puzzle by the hardest technique needed to solve it
+// without guessing.
+type Difficulty int
+
+const (
+	// Easy puzzles fall to singles.
+	Easy Difficulty = iota
+
+	// Medium puzzles need locked candidates and pairs.
+	Medium
+
+	// Hard puzzles need triples, fish, X-wings and swordfish, and XY-wings.
+	Hard
+
+	// Expert puzzles need more than the techniques of Hard ones.
+	Expert
+)
+
+// Difficulties are the difficulties from the easiest, by name.
+var Difficulties = []string{"easy", "medium", "hard", "expert"}
+
+func (d Difficulty) String() string {
+	return Difficulties[d]
+}
+
+// ParseDifficulty returns the difficulty with the given name.
+func ParseDifficulty(name string) (Difficulty, error) {
+	for d, n := range Difficulties {
+		if n == name {
+			return Difficulty(d), nil
+		}
+	}
+
+	return 0, fmt.Errorf("sudoku: unknown difficulty %q", name)
+}
+
+// Technique is a way of finding numbers by hand, from the simplest.
+type Technique int
+
+const (
+	// NakedSingle fills in a square with a single candidate left.
+	NakedSingle Technique = iota
+
+	// HiddenSingle fills in the only square of a row, column, region or
+	// diagonal where a number can go.
+	HiddenSingle
+
+	// CageSum rules out of the squares of a killer cage the numbers that
+	// can't add up to its sum with the others.
+	CageSum
+
+	// LockedCandidates rule a number out of a row or column when it can
+	// only go in the part of it that crosses a box, and out of a box when
+	// it can only go in the part of it that crosses a row or column.
+	LockedCandidates
+
+	// NakedPair rules two numbers out of a row, column or box when they are
+	// the only candidates of two of its squares, and HiddenPair rules the
+	// other candidates out of two squares when they are the only ones where
+	// two numbers can go.
+	NakedPair
+	HiddenPair
+
+	// NakedTriple and HiddenTriple do the same with three numbers.
+	NakedTriple
+	HiddenTriple
+
+	// XWing and Swordfish rule a number out of columns when it can only go
+	// in them in as many rows, and the other way around.
+	XWing
+	Swordfish
+
+	// XYWing rules a number out of the squares seeing two squares with it
+	// and one other candidate each, when those two candidates are the ones
+	// of a third square seeing both: whichever goes there, one of the two
+	// gets the number.
+	XYWing
+)
+
+var techniqueNames = [...]string{
+	NakedSingle:      "naked single",
+	HiddenSingle:     "hidden single",
+	CageSum:          "cage sum",
+	LockedCandidates: "locked candidates",
+	NakedPair:        "naked pair",
+	HiddenPair:       "hidden pair",
+	NakedTriple:      "naked triple",
+	HiddenTriple:     "hidden triple",
+	XWing:            "X-wing",
+	Swordfish:        "swordfish",
+	XYWing:           "XY-wing",
+}
+
+func (t Technique) String() string {
+	return techniqueNames[t]
+}
+
+// Difficulty returns the difficulty of the puzzles needing t.
+func (t Technique) Difficulty() Difficulty {
+	switch {
+	case t <= CageSum:
+		return Easy
+	case t <= HiddenPair:
+		return Medium
+	}
+
+	return Hard
+}
+
+// Step is a deduction made by hand: a number filled in, or candidates ruled
+// out.
+type Step struct {
+	Technique Technique
+
+	// Where names the rows, columns or boxes where the technique applies,
+	// e.g. "row 4" or "rows 2 and 7". It is empty for naked singles, which
+	// only concern their square.
+	Where string
+
+	// Move is the number filled in by singles. It has no Value for the
+	// other techniques.
+	Move core.Move
+
+	// Numbers are the numbers the other techniques rule out.
+	Numbers []int
+}
+
+// String explains the step, e.g. "hidden single in box 5: 7 goes at row 4,
+// column 6".
+func (s Step) String() string {
+	where := ""
+	if s.Where != "" {
+		where = " in " + s.Where
+	}
+
+	if s.Move.Value != 0 {
+		return fmt.Sprintf("%s%s: %d goes at row %d, column %d", s.Technique, where, s.Move.Value, s.Move.Row+1, s.Move.Col+1)
+	}
+
+	return fmt.Sprintf("%s of %s%s", s.Technique, list(s.Numbers), where)
+}
+
+// list joins the numbers, e.g. "1, 4 and 7".
+func list(ns []int) string {
+	words := make([]string, len(ns))
+	for i, n := range ns {
+		words[i] = strconv.Itoa(n)
+	}
+
+	if len(words) < 2 {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+}
+
+// Grade returns the difficulty of g, a puzzle with a single solution under the
+// rules r, by solving it the way a person would: always with the simplest
+// technique that gets somewhere.
+func Grade(g core.Grid, r *core.Rules) Difficulty {
+	p := newPencil(g, r)
+	hardest := Easy
+
+	for !p.solved() {
+		step, ok := p.deduce()
+		if !ok {
+			return Expert
+		}
+		hardest = max(hardest, step.Technique.Difficulty())
+	}
+
+	return hardest
+}
+
+// Hint returns the steps a person would take from g, under the rules r, up to
+// the next number they can fill in, which the last step fills in, and whether
+// they can get there without guessing. The numbers of g must all be right.
+func Hint(g core.Grid, r *core.Rules) ([]Step, bool) {
+	p := newPencil(g, r)
+
+	var steps []Step
+	for !p.solved() {
+		step, ok := p.deduce()
+		if !ok {
+			break
+		}
+
+		steps = append(steps, step)
+		if step.Move.Value != 0 {
+			return steps, true
+		}
+	}
+
+	return nil, false
+}
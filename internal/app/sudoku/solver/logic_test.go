@@ -0,0 +1,114 @@
+package solver
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/sudoku/core"
+)
+
+func TestGrade(t *testing.T) {
+	if d := Grade(euler, nil); d != Easy {
+		t.Errorf("expected a puzzle falling to singles to be easy, got %v", d)
+	}
+
+	if d := Grade(inkala, nil); d != Expert {
+		t.Errorf("expected Inkala's puzzle to be expert, got %v", d)
+	}
+}
+
+func TestTechniquesAreSound(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	base, _ := Solve(core.Grid{}, nil)
+
+	for round := range 50 {
+		// Relabel the numbers of a solved grid and empty it as far as it
+		// keeps a single solution.
+		solution := base
+		perm := rng.Perm(size)
+		for i := range size {
+			for j := range size {
+				solution[i][j] = perm[solution[i][j]-1] + 1
+			}
+		}
+
+		// Every other puzzle is a killer one.
+		var rules *core.Rules
+		if round%2 == 1 {
+			rules = dominoes(solution)
+		}
+
+		puzzle := solution
+		for _, id := range rng.Perm(size * size) {
+			n := puzzle[id/size][id%size]
+			puzzle[id/size][id%size] = 0
+			if Solutions(puzzle, rules, 2) != 1 {
+				puzzle[id/size][id%size] = n
+			}
+		}
+
+		// No technique may fill in a wrong number or rule the right one
+		// out.
+		p := newPencil(puzzle, rules)
+		for !p.solved() {
+			step, ok := p.deduce()
+			if !ok {
+				break
+			}
+
+			for i := range size {
+				for j := range size {
+					n := solution[i][j]
+					if p.grid[i][j] != 0 && p.grid[i][j] != n || p.grid[i][j] == 0 && p.cands[i][j]&(candidates(1)<<n) == 0 {
+						t.Fatalf("%v went wrong at row %d, column %d of %v", step, i+1, j+1, puzzle)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestHint(t *testing.T) {
+	solution, _ := Solve(euler, nil)
+	steps, ok := Hint(euler, nil)
+	if !ok || len(steps) == 0 {
+		t.Fatal("expected a hint for a puzzle falling to singles")
+	}
+
+	move := steps[len(steps)-1].Move
+	if move.Value == 0 || euler[move.Row][move.Col] != 0 || solution[move.Row][move.Col] != move.Value {
+		t.Errorf("expected the hint to fill in a right number, got %v", steps)
+	}
+
+	if _, ok := Hint(inkala, nil); ok {
+		t.Error("expected no hint for a puzzle singles and the rest can't start")
+	}
+}
+
+func TestStepString(t *testing.T) {
+	for _, test := range []struct {
+		step Step
+		want string
+	}{
+		{Step{Technique: NakedSingle, Move: core.Move{Row: 3, Col: 5, Value: 7}}, "naked single: 7 goes at row 4, column 6"},
+		{Step{Technique: HiddenSingle, Where: "box 5", Move: core.Move{Row: 3, Col: 5, Value: 7}}, "hidden single in box 5: 7 goes at row 4, column 6"},
+		{Step{Technique: NakedTriple, Where: "row 3", Numbers: []int{1, 4, 7}}, "naked triple of 1, 4 and 7 in row 3"},
+		{Step{Technique: XWing, Where: "rows 2 and 7", Numbers: []int{5}}, "X-wing of 5 in rows 2 and 7"},
+	} {
+		if got := test.step.String(); got != test.want {
+			t.Errorf("expected %q, got %q", test.want, got)
+		}
+	}
+}
+
+func TestParseDifficulty(t *testing.T) {
+	for _, name := range Difficulties {
+		if d, err := ParseDifficulty(name); err != nil || d.String() != name {
+			t.Errorf("expected %q to parse, got %v, %v", name, d, err)
+		}
+	}
+
+	if _, err := ParseDifficulty("impossible"); err == nil {
+		t.Error("expected an unknown difficulty to be refused")
+	}
+}
@@ -0,0 +1,278 @@
+// Package solver solves sudokus, both by trial and error, to tell whether a
+// puzzle has a single solution, and the way a person would, one explained
+// step at a time, to grade puzzles and give hints.
+package solver
+
+import (
+	"math/bits"
+
+	"github.com/Kaamkiya/gg/internal/app/sudoku/core"
+)
+
+// size is the number of rows, columns and regions of the grid.
+const size = core.Size
+
+// candidates is a set of numbers from 1 to 9, one bit each.
+type candidates uint16
+
+// allCandidates holds every number.
+const allCandidates candidates = 0b11_1111_1110
+
+// count returns the number of numbers in c.
+func (c candidates) count() int {
+	return bits.OnesCount16(uint16(c))
+}
+
+// numbers returns the numbers of c in increasing order.
+func (c candidates) numbers() []int {
+	var ns []int
+	for ; c != 0; c &= c - 1 {
+		ns = append(ns, bits.TrailingZeros16(uint16(c)))
+	}
+	return ns
+}
+
+// Solve returns the solution of g under the rules r, or the first one found if
+// it has several, and whether it has one.
+func Solve(g core.Grid, r *core.Rules) (core.Grid, bool) {
+	var solution core.Grid
+	found := false
+	search(g, r, func(s core.Grid) bool {
+		solution, found = s, true
+		return false
+	})
+
+	return solution, found
+}
+
+// SolveWithin is Solve giving up after trying limit numbers, since proving
+// that a grid has no solution can take long, and reports whether it found
+// one in time.
+func SolveWithin(g core.Grid, r *core.Rules, limit int) (core.Grid, bool) {
+	var solution core.Grid
+	found := false
+	s := newSearcher(g, r, func(s core.Grid) bool {
+		solution, found = s, true
+		return false
+	})
+	if s != nil {
+		s.budget = limit
+		s.solve()
+	}
+
+	return solution, found
+}
+
+// Solutions counts the solutions of g under the rules r, stopping at limit. A
+// puzzle must have exactly one, which Solutions(g, r, 2) tells without going
+// through all of them.
+func Solutions(g core.Grid, r *core.Rules, limit int) int {
+	n := 0
+	search(g, r, func(core.Grid) bool {
+		n++
+		return n < limit
+	})
+
+	return n
+}
+
+// searcher fills in a grid by trial and error, keeping the numbers used in
+// every unit, and their sum for the cages.
+type searcher struct {
+	layout *layout
+	grid   core.Grid
+
+	used  []candidates
+	sums  []int
+	empty []int
+
+	// found is called with every solution, and stops the search if it
+	// returns false.
+	found func(core.Grid) bool
+
+	// budget is the number of numbers left to try, if positive, after
+	// which the search stops.
+	budget int
+}
+
+// search calls found with the solutions of g until it returns false.
+func search(g core.Grid, r *core.Rules, found func(core.Grid) bool) {
+	if s := newSearcher(g, r, found); s != nil {
+		s.solve()
+	}
+}
+
+// newSearcher returns a searcher of the solutions of g, or nil if it has
+// none because it repeats a number in a unit or has a cage it can't add up.
+func newSearcher(g core.Grid, r *core.Rules, found func(core.Grid) bool) *searcher {
+	l := newLayout(r)
+	s := &searcher{
+		layout: l,
+		used:   make([]candidates, len(l.units)),
+		sums:   make([]int, len(l.units)),
+		empty:  make([]int, len(l.units)),
+		found:  found,
+	}
+	for i, u := range l.units {
+		s.empty[i] = len(u.squares)
+	}
+
+	for row := range size {
+		for col := range size {
+			n := g[row][col]
+			if n == 0 {
+				continue
+			}
+
+			for _, u := range l.unitsOf[row][col] {
+				if s.used[u]&(candidates(1)<<n) != 0 {
+					return nil
+				}
+			}
+			s.set(row, col, n)
+		}
+	}
+
+	for i, u := range l.units {
+		if u.sum != 0 && (s.sums[i] > u.sum || s.empty[i] == 0 && s.sums[i] != u.sum) {
+			return nil
+		}
+	}
+
+	return s
+}
+
+func (s *searcher) set(row, col, n int) {
+	s.grid[row][col] = n
+	for _, u := range s.layout.unitsOf[row][col] {
+		s.used[u] |= candidates(1) << n
+		s.sums[u] += n
+		s.empty[u]--
+	}
+}
+
+func (s *searcher) clear(row, col, n int) {
+	s.grid[row][col] = 0
+	for _, u := range s.layout.unitsOf[row][col] {
+		s.used[u] &^= candidates(1) << n
+		s.sums[u] -= n
+		s.empty[u]++
+	}
+}
+
+// candidates returns the numbers that can go in the empty square at row and
+// col.
+func (s *searcher) candidates(row, col int) candidates {
+	c := allCandidates
+	for _, u := range s.layout.unitsOf[row][col] {
+		c &^= s.used[u]
+	}
+	for _, u := range s.layout.unitsOf[row][col] {
+		if s.layout.units[u].sum != 0 {
+			c = s.fits(u, c)
+		}
+	}
+	return c
+}
+
+// fits returns the numbers of c that can go in an empty square of the cage u
+// and still let the squares left add up to its sum.
+func (s *searcher) fits(u int, c candidates) candidates {
+	left := s.layout.units[u].sum - s.sums[u]
+	rest := s.empty[u] - 1
+
+	var fit candidates
+	for ; c != 0; c &= c - 1 {
+		n := bits.TrailingZeros16(uint16(c))
+		low, high, ok := sums(allCandidates&^s.used[u]&^(candidates(1)<<n), rest)
+		if ok && low <= left-n && left-n <= high {
+			fit |= candidates(1) << n
+		}
+	}
+	return fit
+}
+
+// sums returns the smallest and largest sums of k different numbers of c, and
+// whether c has that many.
+func sums(c candidates, k int) (low, high int, ok bool) {
+	if c.count() < k {
+		return 0, 0, false
+	}
+
+	for lows, highs := c, c; k > 0; k-- {
+		n := bits.TrailingZeros16(uint16(lows))
+		low += n
+		lows &^= 1 << n
+
+		n = bits.Len16(uint16(highs)) - 1
+		high += n
+		highs &^= 1 << n
+	}
+	return low, high, true
+}
+
+// solve tries every number in the empty square with the fewest candidates,
+// or the number that can only go in one square of a house, and reports
+// whether the search should go on.
+func (s *searcher) solve() bool {
+	var cands [size][size]candidates
+	bestRow, bestCol := -1, -1
+	var best candidates
+	for row := range size {
+		for col := range size {
+			if s.grid[row][col] != 0 {
+				continue
+			}
+
+			c := s.candidates(row, col)
+			if c == 0 {
+				// Nothing can go there, so this branch is a dead
+				// end.
+				return true
+			}
+			cands[row][col] = c
+			if bestRow < 0 || c.count() < best.count() {
+				bestRow, bestCol, best = row, col, c
+			}
+		}
+	}
+
+	if bestRow < 0 {
+		return s.found(s.grid)
+	}
+
+	for _, u := range s.layout.houses {
+		for missing := allCandidates &^ s.used[u]; missing != 0; missing &= missing - 1 {
+			bit := missing & -missing
+			count := 0
+			var only square
+			for _, sq := range s.layout.units[u].squares {
+				if cands[sq.row][sq.col]&bit != 0 {
+					count++
+					only = sq
+				}
+			}
+
+			switch {
+			case count == 0:
+				return true
+			case count == 1 && best.count() > 1:
+				bestRow, bestCol, best = only.row, only.col, bit
+			}
+		}
+	}
+
+	for _, n := range best.numbers() {
+		if s.budget--; s.budget == 0 {
+			return false
+		}
+
+		s.set(bestRow, bestCol, n)
+		if !s.solve() {
+			return false
+		}
+		s.clear(bestRow, bestCol, n)
+	}
+
+	return true
+}
@@ -0,0 +1,95 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/sudoku/core"
+)
+
+// parse reads a grid from its 81 numbers, row by row, 0 for an empty square.
+func parse(s string) core.Grid {
+	var g core.Grid
+	for i, c := range s {
+		g[i/size][i%size] = int(c - '0')
+	}
+	return g
+}
+
+// euler is the first puzzle of Project Euler's problem 96, which falls to
+// singles.
+var euler = parse("003020600900305001001806400008102900700000008006708200002609500800203009005010300")
+
+// inkala is Arto Inkala's puzzle, said to be the hardest there is.
+var inkala = parse("800000000003600000070090200050007000000045700000100030001000068008500010090000400")
+
+func TestSolve(t *testing.T) {
+	for _, puzzle := range []core.Grid{euler, inkala} {
+		solution, ok := Solve(puzzle, nil)
+		if !ok || core.New(solution).Outcome() != core.Solved {
+			t.Fatalf("expected %v to be solved, got %v", puzzle, solution)
+		}
+
+		for i := range size {
+			for j := range size {
+				if n := puzzle[i][j]; n != 0 && solution[i][j] != n {
+					t.Fatalf("expected the solution to keep the given numbers, got %v", solution)
+				}
+			}
+		}
+	}
+}
+
+func TestSolutions(t *testing.T) {
+	if n := Solutions(euler, nil, 2); n != 1 {
+		t.Errorf("expected a puzzle to have one solution, got %d", n)
+	}
+
+	if n := Solutions(core.Grid{}, nil, 5); n != 5 {
+		t.Errorf("expected the count to stop at the limit, got %d", n)
+	}
+
+	g := euler
+	g[0][0] = g[0][2]
+	if n := Solutions(g, nil, 2); n != 0 {
+		t.Errorf("expected a grid repeating a number to have no solution, got %d", n)
+	}
+}
+
+func TestSolveVariants(t *testing.T) {
+	diagonal := &core.Rules{Regions: core.Boxes(), Diagonal: true}
+	solution, ok := Solve(core.Grid{}, diagonal)
+	if !ok || core.NewVariant(solution, diagonal).Outcome() != core.Solved {
+		t.Errorf("expected a diagonal grid, got %v", solution)
+	}
+
+	solution, _ = Solve(euler, nil)
+	killer := dominoes(solution)
+	if n := Solutions(solution, killer, 2); n != 1 {
+		t.Fatalf("expected the solution to follow the cages, got %d solutions", n)
+	}
+	wrong := solution
+	wrong[0][0], wrong[0][2] = wrong[0][2], wrong[0][0]
+	if n := Solutions(wrong, killer, 2); n != 0 {
+		t.Errorf("expected a grid breaking a cage sum to have no solution, got %d", n)
+	}
+	if n := Solutions(core.Grid{}, killer, 2); n == 0 {
+		t.Error("expected the cages to be solvable")
+	}
+}
+
+// dominoes returns the rules of a killer sudoku with cages of two squares side
+// by side, and of one at the end of the rows, adding up as in solution.
+func dominoes(solution core.Grid) *core.Rules {
+	r := &core.Rules{Regions: core.Boxes()}
+	for row := range size {
+		for col := 0; col < size; col += 2 {
+			cage := core.Cage{Squares: []core.Square{{Row: row, Col: col}}, Sum: solution[row][col]}
+			if col+1 < size {
+				cage.Squares = append(cage.Squares, core.Square{Row: row, Col: col + 1})
+				cage.Sum += solution[row][col+1]
+			}
+			r.Cages = append(r.Cages, cage)
+		}
+	}
+	return r
+}
@@ -0,0 +1,495 @@
+package solver
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/Kaamkiya/gg/internal/app/sudoku/core"
+)
+
+// techniques apply the techniques to a pencil grid, returning the step they
+// took if they found a number or ruled a candidate out.
+var techniques = [...]func(p *pencil) (Step, bool){
+	NakedSingle:      (*pencil).nakedSingle,
+	HiddenSingle:     (*pencil).hiddenSingle,
+	CageSum:          (*pencil).cageSum,
+	LockedCandidates: (*pencil).lockedCandidates,
+	NakedPair:        func(p *pencil) (Step, bool) { return p.nakedSubset(NakedPair, 2) },
+	HiddenPair:       func(p *pencil) (Step, bool) { return p.hiddenSubset(HiddenPair, 2) },
+	NakedTriple:      func(p *pencil) (Step, bool) { return p.nakedSubset(NakedTriple, 3) },
+	HiddenTriple:     func(p *pencil) (Step, bool) { return p.hiddenSubset(HiddenTriple, 3) },
+	XWing:            func(p *pencil) (Step, bool) { return p.fish(XWing, 2) },
+	Swordfish:        func(p *pencil) (Step, bool) { return p.fish(Swordfish, 3) },
+	XYWing:           (*pencil).xyWing,
+}
+
+// pencil is a grid being solved by hand, with the candidates left in its
+// empty squares pencilled in.
+type pencil struct {
+	layout *layout
+	grid   core.Grid
+	cands  [size][size]candidates
+}
+
+func newPencil(g core.Grid, r *core.Rules) *pencil {
+	p := &pencil{layout: newLayout(r), grid: g}
+	for row := range size {
+		for col := range size {
+			if g[row][col] == 0 {
+				p.cands[row][col] = allCandidates
+			}
+		}
+	}
+
+	for row := range size {
+		for col := range size {
+			if n := g[row][col]; n != 0 {
+				p.place(row, col, n)
+			}
+		}
+	}
+
+	return p
+}
+
+// place fills in n at row and col and rules it out of the other squares of
+// its units.
+func (p *pencil) place(row, col, n int) {
+	p.grid[row][col] = n
+	p.cands[row][col] = 0
+
+	for _, u := range p.layout.unitsOf[row][col] {
+		for _, sq := range p.layout.units[u].squares {
+			p.cands[sq.row][sq.col] &^= candidates(1) << n
+		}
+	}
+}
+
+// eliminate rules the numbers of c out of the square at sq and reports
+// whether it had any of them.
+func (p *pencil) eliminate(sq square, c candidates) bool {
+	if p.cands[sq.row][sq.col]&c == 0 {
+		return false
+	}
+
+	p.cands[sq.row][sq.col] &^= c
+	return true
+}
+
+func (p *pencil) solved() bool {
+	for _, row := range p.grid {
+		for _, n := range row {
+			if n == 0 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// deduce takes a step with the simplest technique that gets somewhere.
+func (p *pencil) deduce() (Step, bool) {
+	for _, apply := range techniques {
+		if step, ok := apply(p); ok {
+			return step, true
+		}
+	}
+
+	return Step{}, false
+}
+
+func (p *pencil) nakedSingle() (Step, bool) {
+	for row := range size {
+		for col := range size {
+			if c := p.cands[row][col]; c.count() == 1 {
+				n := c.numbers()[0]
+				p.place(row, col, n)
+				return Step{Technique: NakedSingle, Move: core.Move{Row: row, Col: col, Value: n}}, true
+			}
+		}
+	}
+
+	return Step{}, false
+}
+
+func (p *pencil) hiddenSingle() (Step, bool) {
+	for _, u := range p.layout.houses {
+		for n := 1; n <= size; n++ {
+			bit := candidates(1) << n
+			only, count := square{}, 0
+			for _, sq := range p.layout.units[u].squares {
+				if p.cands[sq.row][sq.col]&bit != 0 {
+					only = sq
+					count++
+				}
+			}
+
+			if count == 1 {
+				p.place(only.row, only.col, n)
+				return Step{
+					Technique: HiddenSingle,
+					Where:     p.layout.units[u].name,
+					Move:      core.Move{Row: only.row, Col: only.col, Value: n},
+				}, true
+			}
+		}
+	}
+
+	return Step{}, false
+}
+
+// cageSum rules out of the squares of a cage the numbers that aren't in any
+// set of different numbers adding up to its sum that could go in them.
+func (p *pencil) cageSum() (Step, bool) {
+	for _, u := range p.layout.units {
+		if u.sum == 0 {
+			continue
+		}
+
+		var filled, allowed candidates
+		var open []square
+		for _, sq := range u.squares {
+			if n := p.grid[sq.row][sq.col]; n != 0 {
+				filled |= candidates(1) << n
+			} else {
+				open = append(open, sq)
+			}
+		}
+
+		for _, combo := range combos[len(u.squares)][u.sum] {
+			if combo&filled != filled {
+				continue
+			}
+
+			// Every number left must go in one of the open squares,
+			// and every open square must take one of them.
+			left := combo &^ filled
+			var union candidates
+			fits := true
+			for _, sq := range open {
+				c := p.cands[sq.row][sq.col] & left
+				fits = fits && c != 0
+				union |= c
+			}
+			if fits && union == left {
+				allowed |= left
+			}
+		}
+
+		var ruled candidates
+		for _, sq := range open {
+			others := p.cands[sq.row][sq.col] &^ allowed
+			if p.eliminate(sq, others) {
+				ruled |= others
+			}
+		}
+		if ruled != 0 {
+			return Step{Technique: CageSum, Where: u.name, Numbers: ruled.numbers()}, true
+		}
+	}
+
+	return Step{}, false
+}
+
+// combos holds the sets of different numbers by how many there are and their
+// sum.
+var combos = func() [size + 1][size*(size+1)/2 + 1][]candidates {
+	var c [size + 1][size*(size+1)/2 + 1][]candidates
+	for set := candidates(1); set <= allCandidates>>1; set++ {
+		numbers := (set << 1).numbers()
+		sum := 0
+		for _, n := range numbers {
+			sum += n
+		}
+		c[len(numbers)][sum] = append(c[len(numbers)][sum], set<<1)
+	}
+	return c
+}()
+
+// lockedCandidates rules a number out of a house when it can only go in the
+// squares of another house that are in it: a box and a row, say.
+func (p *pencil) lockedCandidates() (Step, bool) {
+	houses := p.layout.houses
+	for _, a := range houses {
+		for n := 1; n <= size; n++ {
+			bit := candidates(1) << n
+			var where []square
+			for _, sq := range p.layout.units[a].squares {
+				if p.cands[sq.row][sq.col]&bit != 0 {
+					where = append(where, sq)
+				}
+			}
+			if len(where) < 2 {
+				continue
+			}
+
+			for _, b := range houses {
+				if a == b || !p.within(where, b) {
+					continue
+				}
+
+				progress := false
+				for _, sq := range p.layout.units[b].squares {
+					if !contains(p.layout.units[a].squares, sq) {
+						progress = p.eliminate(sq, bit) || progress
+					}
+				}
+				if progress {
+					return Step{Technique: LockedCandidates, Where: p.layout.units[a].name, Numbers: []int{n}}, true
+				}
+			}
+		}
+	}
+
+	return Step{}, false
+}
+
+// within reports whether all the squares are in the unit u.
+func (p *pencil) within(squares []square, u int) bool {
+	for _, sq := range squares {
+		if !contains(p.layout.units[u].squares, sq) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// nakedSubset looks for k squares of a unit holding k candidates between
+// them.
+func (p *pencil) nakedSubset(t Technique, k int) (Step, bool) {
+	for _, u := range p.layout.units {
+		unit := u.squares
+		var open []square
+		for _, sq := range unit {
+			if c := p.cands[sq.row][sq.col].count(); c >= 2 && c <= k {
+				open = append(open, sq)
+			}
+		}
+
+		var ruled candidates
+		combinations(len(open), k, func(picked []int) bool {
+			var union candidates
+			subset := make([]square, 0, k)
+			for _, i := range picked {
+				union |= p.cands[open[i].row][open[i].col]
+				subset = append(subset, open[i])
+			}
+			if union.count() != k {
+				return true
+			}
+
+			for _, sq := range unit {
+				if !contains(subset, sq) && p.eliminate(sq, union) {
+					ruled = union
+				}
+			}
+			return ruled == 0
+		})
+
+		if ruled != 0 {
+			return Step{Technique: t, Where: u.name, Numbers: ruled.numbers()}, true
+		}
+	}
+
+	return Step{}, false
+}
+
+// hiddenSubset looks for k numbers of a unit which can only go in k squares
+// between them.
+func (p *pencil) hiddenSubset(t Technique, k int) (Step, bool) {
+	for _, u := range p.layout.houses {
+		unit := p.layout.units[u].squares
+		// where holds the squares of the unit each number can go in, by
+		// their index in the unit.
+		var where [size + 1]uint16
+		var open []int
+		for n := 1; n <= size; n++ {
+			for i, sq := range unit {
+				if p.cands[sq.row][sq.col]&(candidates(1)<<n) != 0 {
+					where[n] |= 1 << i
+				}
+			}
+			if c := bits.OnesCount16(where[n]); c >= 2 && c <= k {
+				open = append(open, n)
+			}
+		}
+
+		var ruled candidates
+		combinations(len(open), k, func(picked []int) bool {
+			var squares uint16
+			var numbers candidates
+			for _, i := range picked {
+				squares |= where[open[i]]
+				numbers |= candidates(1) << open[i]
+			}
+			if bits.OnesCount16(squares) != k {
+				return true
+			}
+
+			for i, sq := range unit {
+				others := p.cands[sq.row][sq.col] &^ numbers
+				if squares&(1<<i) != 0 && p.eliminate(sq, others) {
+					ruled |= others
+				}
+			}
+			return ruled == 0
+		})
+
+		if ruled != 0 {
+			return Step{Technique: t, Where: p.layout.units[u].name, Numbers: ruled.numbers()}, true
+		}
+	}
+
+	return Step{}, false
+}
+
+// fish looks for a number that can only go in k columns in k rows, which
+// rules it out of the other rows of the columns, and the same with the rows
+// and the columns swapped.
+func (p *pencil) fish(t Technique, k int) (Step, bool) {
+	for dir, lines := range [][]unit{p.layout.units[:size], p.layout.units[size : 2*size]} {
+		for n := 1; n <= size; n++ {
+			bit := candidates(1) << n
+
+			// where holds the positions along each line where n can
+			// go.
+			var where [size]uint16
+			var open []int
+			for i, line := range lines {
+				for j, sq := range line.squares {
+					if p.cands[sq.row][sq.col]&bit != 0 {
+						where[i] |= 1 << j
+					}
+				}
+				if c := bits.OnesCount16(where[i]); c >= 2 && c <= k {
+					open = append(open, i)
+				}
+			}
+
+			var base []int
+			combinations(len(open), k, func(picked []int) bool {
+				var cross uint16
+				lineOf := map[int]bool{}
+				for _, i := range picked {
+					cross |= where[open[i]]
+					lineOf[open[i]] = true
+				}
+				if bits.OnesCount16(cross) != k {
+					return true
+				}
+
+				progress := false
+				for i, line := range lines {
+					if lineOf[i] {
+						continue
+					}
+					for j, sq := range line.squares {
+						if cross&(1<<j) != 0 {
+							progress = p.eliminate(sq, bit) || progress
+						}
+					}
+				}
+				if progress {
+					for _, i := range picked {
+						base = append(base, open[i]+1)
+					}
+				}
+				return !progress
+			})
+
+			if base != nil {
+				where := "rows "
+				if dir == 1 {
+					where = "columns "
+				}
+				return Step{Technique: t, Where: where + list(base), Numbers: []int{n}}, true
+			}
+		}
+	}
+
+	return Step{}, false
+}
+
+func (p *pencil) xyWing() (Step, bool) {
+	var pairs []square
+	for row := range size {
+		for col := range size {
+			if p.cands[row][col].count() == 2 {
+				pairs = append(pairs, square{row, col})
+			}
+		}
+	}
+
+	for _, pivot := range pairs {
+		xy := p.cands[pivot.row][pivot.col]
+		for i, a := range pairs {
+			for _, b := range pairs[i+1:] {
+				xz, yz := p.cands[a.row][a.col], p.cands[b.row][b.col]
+				z := xz & yz
+				if !p.sees(pivot, a) || !p.sees(pivot, b) || z.count() != 1 || xz|yz != xy|z || xy&z != 0 || xz == yz {
+					continue
+				}
+
+				progress := false
+				for row := range size {
+					for col := range size {
+						sq := square{row, col}
+						if sq != a && sq != b && p.sees(sq, a) && p.sees(sq, b) {
+							progress = p.eliminate(sq, z) || progress
+						}
+					}
+				}
+				if progress {
+					return Step{
+						Technique: XYWing,
+						Where:     fmt.Sprintf("row %d, column %d", pivot.row+1, pivot.col+1),
+						Numbers:   z.numbers(),
+					}, true
+				}
+			}
+		}
+	}
+
+	return Step{}, false
+}
+
+// sees tells whether a and b are different squares of the same unit.
+func (p *pencil) sees(a, b square) bool {
+	return p.layout.sees[a.row][a.col][b.row][b.col]
+}
+
+// combinations calls fn with every set of k indices below n, in increasing
+// order, until it returns false.
+func combinations(n, k int, fn func([]int) bool) {
+	picked := make([]int, 0, k)
+
+	var pick func(from int) bool
+	pick = func(from int) bool {
+		if len(picked) == k {
+			return fn(picked)
+		}
+
+		for i := from; i <= n-(k-len(picked)); i++ {
+			picked = append(picked, i)
+			if !pick(i + 1) {
+				return false
+			}
+			picked = picked[:len(picked)-1]
+		}
+		return true
+	}
+
+	pick(0)
+}
+
+func contains(squares []square, sq square) bool {
+	for _, s := range squares {
+		if s == sq {
+			return true
+		}
+	}
+
+	return false
+}
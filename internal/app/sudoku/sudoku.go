@@ -3,123 +3,444 @@ package sudoku
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Kaamkiya/gg/internal/app/sudoku/core"
+	"github.com/Kaamkiya/gg/internal/app/sudoku/solver"
 	"github.com/Kaamkiya/gg/internal/app/sudoku/sudokugenerator"
+	"github.com/Kaamkiya/gg/internal/game"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
+// setPrefix starts the names of the actions filling in a number, which end
+// with the number.
+const setPrefix = "set-"
+
+// bindings returns the default keys of the player's actions.
+func bindings() []game.Binding {
+	b := []game.Binding{
+		{Action: "up", Keys: []string{"up", "k"}, Help: "move up"},
+		{Action: "down", Keys: []string{"down", "j"}, Help: "move down"},
+		{Action: "left", Keys: []string{"left", "h"}, Help: "move left"},
+		{Action: "right", Keys: []string{"right", "l"}, Help: "move right"},
+	}
+	for n := 1; n <= 9; n++ {
+		s := strconv.Itoa(n)
+		b = append(b, game.Binding{Action: setPrefix + s, Keys: []string{s}, Help: "fill in " + s})
+	}
+	b = append(b,
+		game.Binding{Action: "clear", Keys: []string{"0"}, Help: "clear"},
+		game.Binding{Action: "notes", Keys: []string{"n"}, Help: "switch between numbers and notes"},
+		game.Binding{Action: "fill-notes", Keys: []string{"N"}, Help: "pencil in every number that could go"},
+		game.Binding{Action: "hint", Keys: []string{"H"}, Help: "show the next logical step"},
+		game.Binding{Action: "check", Keys: []string{"c"}, Help: "check the numbers against the solution"},
+	)
+	b = append(b, game.UndoBindings...)
+	return append(b, game.Binding{Action: "quit", Keys: []string{"q"}, Help: "quit"})
+}
+
 type model struct {
-	origGrid [][]int
-	grid     [][]int
+	opts       game.Options
+	keys       game.KeyMap
+	state      core.State
+	difficulty solver.Difficulty
+	variant    sudokugenerator.Variant
+
+	// solution is the only solution of the puzzle.
+	solution core.Grid
+
+	// source names the file and number of the puzzle if it wasn't
+	// generated, e.g. "puzzles.txt #3", and is shown instead of the seed.
+	source string
 
 	cursorx int
 	cursory int
+
+	// started is when the puzzle was started or continued, and elapsed is
+	// the time spent on it before that.
+	started time.Time
+	elapsed time.Duration
+	solved  bool
+
+	// mistakes counts the numbers filled in that weren't the ones of the
+	// solution. Undoing them doesn't take them back.
+	mistakes int
+
+	history game.History[core.State]
+
+	// noting tells whether the number keys pencil in notes rather than
+	// fill in numbers.
+	noting bool
+
+	// assisted tells whether hints and checking are allowed, which they
+	// aren't in the daily challenge. checking tells whether the numbers are
+	// being checked against the solution, and checked whether they ever
+	// were. hint explains the last hint until the next key, and hints
+	// counts them.
+	assisted bool
+	checking bool
+	checked  bool
+	hint     string
+	hints    int
+}
+
+// clockMsg redraws the time spent on the puzzle every second.
+type clockMsg struct{}
+
+func tick() tea.Cmd {
+	return game.Tick(time.Second, clockMsg{})
 }
 
 func (m model) Init() tea.Cmd {
-	return nil
+	return tick()
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case clockMsg:
+		// The clock stops once the puzzle is solved.
+		if m.solved {
+			return m, nil
+		}
+		return m, tick()
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
+		action := m.keys.Action(msg)
+		if action == "quit" {
 			return m, tea.Quit
-		case "up", "k":
+		}
+
+		// The grid can't be changed once it is solved.
+		if m.solved {
+			return m, nil
+		}
+		m.hint = ""
+
+		if n, ok := strings.CutPrefix(action, setPrefix); ok {
+			value, _ := strconv.Atoi(n)
+			if m.noting {
+				m.play(func(s *core.State) error { return s.Note(m.cursory, m.cursorx, value) })
+				return m, nil
+			}
+			if !m.fill(value) {
+				return m, nil
+			}
+			if m.wrong(m.cursory, m.cursorx) {
+				m.mistakes++
+			}
+
+			return m.checkSolved()
+		}
+
+		switch action {
+		case "notes":
+			m.noting = !m.noting
+		case "fill-notes":
+			m.play(func(s *core.State) error {
+				s.FillNotes()
+				return nil
+			})
+		case "hint":
+			if m.assisted {
+				m.showHint()
+			}
+		case "check":
+			m.checking = m.assisted && !m.checking
+			m.checked = m.checked || m.checking
+		case "undo":
+			m.state, _ = m.history.Undo(m.state)
+		case "redo":
+			m.state, _ = m.history.Redo(m.state)
+		case "up":
 			if m.cursory > 0 {
 				m.cursory--
 			}
-		case "down", "j":
+		case "down":
 			if m.cursory < 8 {
 				m.cursory++
 			}
-		case "left", "h":
+		case "left":
 			if m.cursorx > 0 {
 				m.cursorx--
 			}
-		case "right", "l":
+		case "right":
 			if m.cursorx < 8 {
 				m.cursorx++
 			}
-		case "1", "2", "3", "4", "5", "6", "7", "8", "9", "0":
-			m.setSquare(msg.String())
+		case "clear":
+			m.fill(0)
 		}
 	}
 
 	return m, nil
 }
 
+// fill puts value in the square under the cursor, or clears it if value is 0,
+// and reports whether that changed the grid. Given squares can't be changed.
+func (m *model) fill(value int) bool {
+	return m.play(func(s *core.State) error {
+		return s.Step(core.Move{Row: m.cursory, Col: m.cursorx, Value: value})
+	})
+}
+
+// play makes a change to the state, recording it so that it can be undone,
+// and reports whether it changed anything.
+func (m *model) play(change func(s *core.State) error) bool {
+	before := m.state
+	if err := change(&m.state); err != nil || m.state == before {
+		return false
+	}
+
+	m.history.Record(before)
+	return true
+}
+
+// wrong reports whether the number filled in at row and col isn't the one of
+// the solution.
+func (m model) wrong(row, col int) bool {
+	n := m.state.Grid[row][col]
+	return n != 0 && n != m.solution[row][col]
+}
+
+// showHint explains the next number the player can fill in and moves the
+// cursor to it. A wrong number comes first, since nothing can be deduced from
+// it, and the solution gives the number when there is no logical step left.
+func (m *model) showHint() {
+	m.hints++
+
+	for row := range core.Size {
+		for col := range core.Size {
+			if m.wrong(row, col) {
+				m.cursory, m.cursorx = row, col
+				m.hint = fmt.Sprintf("The %d at row %d, column %d is wrong.", m.state.Grid[row][col], row+1, col+1)
+				return
+			}
+		}
+	}
+
+	if steps, ok := solver.Hint(m.state.Grid, m.state.Rules); ok {
+		lines := make([]string, len(steps))
+		for i, step := range steps {
+			lines[i] = strings.ToUpper(step.String()[:1]) + step.String()[1:] + "."
+		}
+
+		move := steps[len(steps)-1].Move
+		m.cursory, m.cursorx = move.Row, move.Col
+		m.hint = strings.Join(lines, "\n")
+		return
+	}
+
+	for row := range core.Size {
+		for col := range core.Size {
+			if m.state.Grid[row][col] == 0 {
+				m.cursory, m.cursorx = row, col
+				m.hint = fmt.Sprintf("No logical step is left: %d goes at row %d, column %d.", m.solution[row][col], row+1, col+1)
+				return
+			}
+		}
+	}
+}
+
+// checkSolved ends the round once the puzzle is solved.
+func (m model) checkSolved() (tea.Model, tea.Cmd) {
+	if m.state.Outcome() != core.Solved {
+		return m, nil
+	}
+
+	m.solved = true
+	m.elapsed += time.Since(m.started)
+
+	summary := "solved in " + formatDuration(m.elapsed)
+	var with []string
+	if m.mistakes != 0 {
+		with = append(with, plural(m.mistakes, "mistake"))
+	}
+	if m.hints != 0 {
+		with = append(with, plural(m.hints, "hint"))
+	}
+	if len(with) != 0 {
+		summary += " with " + strings.Join(with, " and ")
+	}
+
+	// A flawless solve takes no wrong number and no help.
+	var flawless tea.Cmd
+	if m.mistakes == 0 && m.hints == 0 && !m.checked {
+		flawless = game.Publish("flawless", 0)
+	}
+	return m, tea.Batch(flawless, game.Over(game.Result{
+		Outcome: game.Win,
+		Summary: summary,
+		Stats: []game.Stat{{
+			Name:          m.statName("solve time"),
+			Value:         m.elapsed.Seconds(),
+			LowerIsBetter: true,
+			Seconds:       true,
+		}, {
+			Name:          "mistakes",
+			Value:         float64(m.mistakes),
+			LowerIsBetter: true,
+		}},
+		Undos: m.history.Undos(),
+	}))
+}
+
 func (m model) View() string {
 	s := ""
 
-	for i, r := range m.grid {
+	// Once there are notes, every square takes three lines to show them in
+	// a grid of their own.
+	lines := 1
+	if m.hasNotes() {
+		lines = 3
+	}
+	s += m.board(lines)
+
+	elapsed := m.elapsed
+	if !m.solved {
+		elapsed += time.Since(m.started)
+	}
+	s += fmt.Sprintf("\nTime: %s   Mistakes: %d\n", formatDuration(elapsed), m.mistakes)
+
+	if m.hint != "" {
+		s += "\n" + m.hint + "\n"
+	}
+
+	if m.noting {
+		s += "\nPencilling in notes.\n"
+	}
+
+	if m.checking {
+		s += "\nChecking the numbers against the solution.\n"
+	}
+
+	if m.solved {
+		s += fmt.Sprintf("\nSolved in %s! Press %s to quit.\n", formatDuration(m.elapsed), m.keys.Keys("quit"))
+	}
+
+	if m.source != "" {
+		s += fmt.Sprintf("\nDifficulty: %s\nPuzzle: %s\n", m.difficulty, m.source)
+	} else {
+		s += fmt.Sprintf("\nDifficulty: %s\nSeed: %d\n", m.difficulty, m.opts.Seed)
+	}
+	if m.variant != sudokugenerator.Classic {
+		s += "Variant: " + m.variant.String() + "\n"
+	}
+
+	return s
+}
+
+// hasNotes reports whether any empty square has notes.
+func (m model) hasNotes() bool {
+	for i, r := range m.state.Grid {
 		for j, c := range r {
-			if j%3 == 0 && j != 0 {
-				s += " | "
+			if c == 0 && m.state.Notes[i][j] != 0 {
+				return true
 			}
+		}
+	}
+
+	return false
+}
 
-			if j == m.cursorx && i == m.cursory {
-				col := lipgloss.NewStyle().Background(lipgloss.Color("#0000ff")).Render
-				if c == 0 {
-					s += col(" . ")
-				} else {
-					s += col(fmt.Sprintf(" %d ", c))
-				}
+// cell returns the given line of the square at row and col, drawn on as many
+// lines. Numbers go on the middle line, and notes in a grid of 1-9.
+func (m model) cell(row, col, line, lines int) string {
+	n, notes := m.state.Grid[row][col], m.state.Notes[row][col]
+	switch {
+	case n == 0 && notes != 0:
+		cell := ""
+		for v := line*3 + 1; v <= line*3+3; v++ {
+			if notes.Has(v) {
+				cell += strconv.Itoa(v)
 			} else {
-				if c == 0 {
-					s += " . "
-				} else {
-					s += fmt.Sprintf(" %d ", c)
-				}
+				cell += " "
 			}
 		}
+		return cell
+	case line != lines/2:
+		return "   "
+	case n == 0:
+		return " " + m.blank(row, col) + " "
+	}
 
-		s += "\n"
+	return fmt.Sprintf(" %d ", n)
+}
 
-		if i == 2 || i == 5 {
-			s += "--------------------------------\n"
-		}
+// statName names a stat kept apart for each difficulty and variant, since
+// their puzzles take very different times, e.g. "solve time (hard)" or "solve
+// time (killer, hard)".
+func (m model) statName(name string) string {
+	if m.variant != sudokugenerator.Classic {
+		return fmt.Sprintf("%s (%s, %s)", name, m.variant, m.difficulty)
 	}
+	return fmt.Sprintf("%s (%s)", name, m.difficulty)
+}
 
-	return s
+// plural returns n things, e.g. "1 hint" or "2 hints".
+func plural(n int, thing string) string {
+	if n == 1 {
+		return "1 " + thing
+	}
+	return fmt.Sprintf("%d %ss", n, thing)
 }
 
-func (m *model) setSquare(button string) {
-	if m.origGrid[m.cursory][m.cursorx] == 0 {
-		m.grid[m.cursory][m.cursorx], _ = strconv.Atoi(button)
+// formatDuration formats d as minutes and seconds, e.g. 4:05.
+func formatDuration(d time.Duration) string {
+	seconds := int(d.Seconds())
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// dailyModel starts the daily challenge, a medium classic puzzle in which no
+// number can be taken back, with no hints and no checking.
+func dailyModel(opts game.Options) tea.Model {
+	g := generating(opts, sudokugenerator.Classic, solver.Medium, game.NoUndo, "")
+	g.generate = func() model {
+		m := newModel(opts, sudokugenerator.Classic, solver.Medium, game.NoUndo)
+		m.assisted = false
+		return m
 	}
+	return g
 }
 
-func initialModel() tea.Model {
+// newModel generates a puzzle of variant v and difficulty d with the given
+// undo policy.
+func newModel(opts game.Options, v sudokugenerator.Variant, d solver.Difficulty, policy game.UndoPolicy) model {
 	g := sudokugenerator.Model{}
-	g.Init()
-
-	grid := make([][]int, 9)
-	orig := make([][]int, 9)
+	g.Init(opts.Rand, v, d)
 
-	for i := range 9 {
-		grid[i] = make([]int, 9)
-		orig[i] = make([]int, 9)
+	m := puzzleModel(opts, gridOf(g.Grid), g.Rules, d, policy)
+	m.variant = v
+	return m
+}
 
-		for j := range 9 {
-			grid[i][j] = g.Grid[j][i]
-			orig[i][j] = g.Grid[j][i]
-		}
-	}
+// puzzleModel starts the given puzzle, which must have a single solution under
+// the rules, of difficulty d.
+func puzzleModel(opts game.Options, puzzle core.Grid, rules *core.Rules, d solver.Difficulty, policy game.UndoPolicy) model {
+	solution, _ := solver.Solve(puzzle, rules)
 
 	return model{
-		grid:     grid,
-		origGrid: orig,
+		opts:       opts,
+		keys:       game.Keys("sudoku"),
+		state:      core.NewVariant(puzzle, rules),
+		difficulty: d,
+		solution:   solution,
+		started:    time.Now(),
+		history:    game.NewHistory[core.State](policy),
+		assisted:   true,
 	}
 }
 
-func Run() {
-	p := tea.NewProgram(initialModel())
-
-	if _, err := p.Run(); err != nil {
-		panic(err)
-	}
+func init() {
+	game.Register(game.Game{
+		ID:          "sudoku",
+		Name:        "sudoku",
+		Players:     1,
+		Description: "Fill the grid so every row, column and box holds 1-9.",
+		Keys:        bindings(),
+		New:         newSetup,
+		Flags:       flags,
+		Resume:      resume,
+		Daily:       dailyModel,
+	})
 }
@@ -0,0 +1,293 @@
+package sudoku
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/sudoku/core"
+	"github.com/Kaamkiya/gg/internal/app/sudoku/solver"
+	"github.com/Kaamkiya/gg/internal/app/sudoku/sudokugenerator"
+	"github.com/Kaamkiya/gg/internal/game"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestMistakesAreCounted(t *testing.T) {
+	m := newModel(game.Seeded(1), sudokugenerator.Classic, solver.Easy, game.UnlimitedUndo)
+
+	// Find an empty square and a number already in its row.
+	var row, col, given int
+find:
+	for i, r := range m.state.Puzzle {
+		for j, c := range r {
+			if c == 0 {
+				row, col = i, j
+				break find
+			}
+		}
+	}
+	for _, c := range m.state.Puzzle[row] {
+		if c != 0 {
+			given = c
+			break
+		}
+	}
+	m.cursory, m.cursorx = row, col
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{rune('0' + given)}})
+	if got := next.(model).mistakes; got != 1 {
+		t.Errorf("expected filling in %d twice in a row to be a mistake, got %d mistakes", given, got)
+	}
+
+	// Clearing the square doesn't count.
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("0")})
+	if got := next.(model).mistakes; got != 1 {
+		t.Errorf("expected clearing not to count, got %d mistakes", got)
+	}
+
+	// Nor does it take for a wrong number to clash with another.
+	var wrong int
+	for n := 1; n <= 9 && wrong == 0; n++ {
+		s := m.state
+		s.Step(core.Move{Row: row, Col: col, Value: n})
+		if n != m.solution[row][col] && !s.Conflicts(row, col) {
+			wrong = n
+		}
+	}
+	if wrong == 0 {
+		t.Fatal("expected a wrong number that clashes with nothing")
+	}
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{rune('0' + wrong)}})
+	if got := next.(model).mistakes; got != 2 {
+		t.Errorf("expected filling in a wrong %d to be a mistake, got %d mistakes", wrong, got)
+	}
+	if !strings.Contains(next.View(), "Mistakes: 2") {
+		t.Error("expected the view to show the mistakes")
+	}
+}
+
+func TestUndo(t *testing.T) {
+	m := newModel(game.Seeded(1), sudokugenerator.Classic, solver.Easy, game.UnlimitedUndo)
+
+find:
+	for i, r := range m.state.Puzzle {
+		for j, c := range r {
+			if c == 0 {
+				m.cursory, m.cursorx = i, j
+				break find
+			}
+		}
+	}
+	puzzle := m.state
+
+	var next tea.Model = m
+	for _, key := range []string{"4", "5", "u", "u"} {
+		next, _ = next.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+	if got := next.(model); got.state != puzzle || got.history.Undos() != 2 {
+		t.Fatalf("expected both numbers to be undone, got %d undos", got.history.Undos())
+	}
+
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if got := next.(model).state.Grid[m.cursory][m.cursorx]; got != 4 {
+		t.Errorf("expected the 4 to be redone, got %d", got)
+	}
+
+	daily := generated(dailyModel(game.Seeded(1)))
+	daily.cursory, daily.cursorx = m.cursory, m.cursorx
+	next = daily
+	for _, key := range []string{"4", "u"} {
+		next, _ = next.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+	if got := next.(model).state.Grid[m.cursory][m.cursorx]; got != 4 {
+		t.Error("expected no undo in the daily challenge")
+	}
+}
+
+func TestHint(t *testing.T) {
+	m := newModel(game.Seeded(1), sudokugenerator.Classic, solver.Easy, game.UnlimitedUndo)
+	hint := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("H")}
+
+	next, _ := m.Update(hint)
+	got := next.(model)
+	row, col := got.cursory, got.cursorx
+	if got.state.Puzzle[row][col] != 0 || !strings.Contains(got.hint, fmt.Sprintf("%d goes at row %d, column %d", got.solution[row][col], row+1, col+1)) {
+		t.Fatalf("expected the hint to point at an empty square, got %q", got.hint)
+	}
+
+	// A wrong number comes before anything else.
+	wrong := got.solution[row][col]%9 + 1
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{rune('0' + wrong)}})
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	next, _ = next.Update(hint)
+	got = next.(model)
+	if got.cursory != row || got.cursorx != col || !strings.Contains(got.hint, "is wrong") {
+		t.Errorf("expected the hint to point at the wrong number, got %q", got.hint)
+	}
+	if got.hints != 2 {
+		t.Errorf("expected 2 hints, got %d", got.hints)
+	}
+
+	var daily tea.Model = generated(dailyModel(game.Seeded(1)))
+	if next, _ = daily.Update(hint); next.(model).hint != "" {
+		t.Error("expected no hints in the daily challenge")
+	}
+}
+
+func TestNotes(t *testing.T) {
+	m := newModel(game.Seeded(1), sudokugenerator.Classic, solver.Easy, game.UnlimitedUndo)
+
+	// Find two empty squares of the same row.
+	row, cols := 0, []int{}
+	for i, r := range m.state.Puzzle {
+		cols = cols[:0]
+		for j, c := range r {
+			if c == 0 {
+				cols = append(cols, j)
+			}
+		}
+		if len(cols) >= 2 {
+			row = i
+			break
+		}
+	}
+	m.cursory, m.cursorx = row, cols[0]
+
+	var next tea.Model = m
+	for _, key := range []string{"n", "3", "n"} {
+		next, _ = next.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+	got := next.(model)
+	if !got.state.Notes[row][cols[0]].Has(3) || got.state.Grid[row][cols[0]] != 0 {
+		t.Fatal("expected 3 to be pencilled in")
+	}
+
+	// Filling in a 3 in the same row rubs the note out.
+	got.cursorx = cols[1]
+	next, _ = got.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3")})
+	if next.(model).state.Notes[row][cols[0]].Has(3) {
+		t.Error("expected the note to be rubbed out")
+	}
+
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if !next.(model).state.Notes[row][cols[0]].Has(3) {
+		t.Error("expected undoing the number to bring the note back")
+	}
+
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	got = next.(model)
+	solution := got.solution[row][cols[0]]
+	if !got.state.Notes[row][cols[0]].Has(solution) {
+		t.Errorf("expected the filled in notes to hold %d, got %b", solution, got.state.Notes[row][cols[0]])
+	}
+}
+
+func TestBoard(t *testing.T) {
+	m := newModel(game.Seeded(1), sudokugenerator.Killer, solver.Easy, game.UnlimitedUndo)
+
+	lines := strings.Split(strings.TrimSuffix(m.board(1), "\n"), "\n")
+	for _, line := range lines {
+		if len(line) != len(lines[0]) {
+			t.Fatalf("expected the lines of the board to line up, got\n%s", strings.Join(lines, "\n"))
+		}
+	}
+
+	// The square at the top left is the top left of its cage, whose sum
+	// goes above it.
+	sum := strconv.Itoa(m.state.Rules.Cages[m.state.Rules.Cage(0, 0)].Sum)
+	if !strings.HasPrefix(lines[0], sum) {
+		t.Errorf("expected the board to start with the sum %s, got %q", sum, lines[0])
+	}
+	if !strings.Contains(m.View(), "Variant: killer") {
+		t.Error("expected the view to name the variant")
+	}
+}
+
+func TestFlawless(t *testing.T) {
+	// solve fills in the solution, after pressing keys, and reports
+	// whether solving was flawless.
+	solve := func(keys ...string) bool {
+		m := newModel(game.Seeded(1), sudokugenerator.Classic, solver.Easy, game.UnlimitedUndo)
+
+		var next tea.Model = m
+		for _, key := range keys {
+			next, _ = next.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		}
+		m = next.(model)
+		for i, r := range m.state.Puzzle {
+			for j, c := range r {
+				if c == 0 && (i != m.cursory || j != m.cursorx) {
+					m.state.Grid[i][j] = m.solution[i][j]
+				}
+			}
+		}
+		m.state.Grid[m.cursory][m.cursorx] = 0
+
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{rune('0' + m.solution[m.cursory][m.cursorx])}})
+		if cmd == nil {
+			t.Fatal("expected the puzzle to be solved")
+		}
+		for _, msg := range messages(cmd) {
+			if msg, ok := msg.(game.EventMsg); ok && msg.Event.Name == "flawless" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !solve() {
+		t.Error("expected a solve without help to be flawless")
+	}
+	if solve("H") {
+		t.Error("expected a solve with a hint not to be flawless")
+	}
+	if solve("c", "c") {
+		t.Error("expected a solve with the numbers checked not to be flawless")
+	}
+}
+
+// messages runs cmd and returns its messages, those of every command of a
+// batch.
+func messages(cmd tea.Cmd) []tea.Msg {
+	msg := cmd()
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok {
+		return []tea.Msg{msg}
+	}
+
+	var msgs []tea.Msg
+	for _, cmd := range batch {
+		if cmd != nil {
+			msgs = append(msgs, messages(cmd)...)
+		}
+	}
+	return msgs
+}
+
+func TestSolveTimeByDifficulty(t *testing.T) {
+	for _, test := range []struct {
+		variant sudokugenerator.Variant
+		want    string
+	}{
+		{sudokugenerator.Classic, "solve time (easy)"},
+		{sudokugenerator.Diagonal, "solve time (diagonal, easy)"},
+	} {
+		m := newModel(game.Seeded(1), test.variant, solver.Easy, game.UnlimitedUndo)
+		m.state.Grid = m.solution
+
+		_, cmd := m.checkSolved()
+		var names []string
+		for _, msg := range messages(cmd) {
+			if over, ok := msg.(game.OverMsg); ok {
+				for _, stat := range over.Result.Stats {
+					names = append(names, stat.Name)
+				}
+			}
+		}
+		if len(names) == 0 || names[0] != test.want {
+			t.Errorf("expected the stats to start with %q, got %q", test.want, names)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Package sudokufile reads and writes sudoku puzzles in the formats they are
+// shared in: one puzzle of 81 squares a line, with '.' or '0' for the empty
+// ones, which is also the format of SDM files, and SDK files, which hold a
+// single puzzle as 9 lines of 9 squares.
+package sudokufile
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/app/sudoku/core"
+	"github.com/Kaamkiya/gg/internal/app/sudoku/solver"
+)
+
+// Format is a way of writing puzzles down.
+type Format int
+
+const (
+	// Line writes a puzzle on a line of 81 squares, '.' for the empty ones.
+	Line Format = iota
+
+	// SDM writes a puzzle on a line of 81 digits, '0' for the empty ones.
+	SDM
+
+	// SDK writes a puzzle on 9 lines of 9 squares, '.' for the empty ones.
+	SDK
+)
+
+// FormatOf returns the format of the file at path, by its extension.
+func FormatOf(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".sdk":
+		return SDK
+	case ".sdm":
+		return SDM
+	}
+
+	return Line
+}
+
+// Read reads the puzzles of r, which holds either an SDK grid or a puzzle a
+// line. Lines starting with '#' are comments, and whatever follows the 81
+// squares of a line after a space, such as its rating, is ignored. The
+// puzzles aren't validated.
+func Read(r io.Reader) ([]core.Grid, error) {
+	type line struct {
+		number int
+		text   string
+	}
+
+	var lines []line
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		lines = append(lines, line{n, text})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(lines) == 0 {
+		return nil, errors.New("no puzzle found")
+	}
+
+	// An SDK grid starts with a row of 9 squares rather than a whole
+	// puzzle.
+	if len(lines[0].text) == core.Size {
+		if len(lines) != core.Size {
+			return nil, fmt.Errorf("the grid has %d rows, want %d", len(lines), core.Size)
+		}
+
+		var g core.Grid
+		for row, l := range lines {
+			if len(l.text) != core.Size {
+				return nil, fmt.Errorf("line %d: %d squares, want %d", l.number, len(l.text), core.Size)
+			}
+			if err := parseSquares(g[row][:], l.text); err != nil {
+				return nil, fmt.Errorf("line %d, %w", l.number, err)
+			}
+		}
+		return []core.Grid{g}, nil
+	}
+
+	puzzles := make([]core.Grid, 0, len(lines))
+	for _, l := range lines {
+		squares, _, _ := strings.Cut(l.text, " ")
+		if len(squares) != core.Size*core.Size {
+			return nil, fmt.Errorf("line %d: %d squares, want %d", l.number, len(squares), core.Size*core.Size)
+		}
+
+		var g core.Grid
+		for row := range core.Size {
+			if err := parseSquares(g[row][:], squares[row*core.Size:(row+1)*core.Size]); err != nil {
+				return nil, fmt.Errorf("line %d, %w", l.number, err)
+			}
+		}
+		puzzles = append(puzzles, g)
+	}
+
+	return puzzles, nil
+}
+
+// parseSquares reads a row of squares into row. Its errors name the column of
+// the square they are about.
+func parseSquares(row []int, squares string) error {
+	for col, c := range []byte(squares) {
+		switch {
+		case c == '.' || c == '0':
+			row[col] = 0
+		case c >= '1' && c <= '9':
+			row[col] = int(c - '0')
+		default:
+			return fmt.Errorf("column %d: %q isn't a number or a blank", col+1, c)
+		}
+	}
+
+	return nil
+}
+
+// Write writes g in the given format.
+func Write(w io.Writer, g core.Grid, f Format) error {
+	blank := byte('.')
+	if f == SDM {
+		blank = '0'
+	}
+
+	var b strings.Builder
+	for _, row := range g {
+		for _, n := range row {
+			if n == 0 {
+				b.WriteByte(blank)
+			} else {
+				b.WriteByte(byte('0' + n))
+			}
+		}
+		if f == SDK {
+			b.WriteByte('\n')
+		}
+	}
+	if f != SDK {
+		b.WriteByte('\n')
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Validate checks that g is a puzzle that can be played: no row, column or
+// box repeats a number, and it has a single solution.
+func Validate(g core.Grid) error {
+	units := []struct {
+		name   string
+		square func(i, j int) int
+	}{
+		{"row", func(i, j int) int { return g[i][j] }},
+		{"column", func(i, j int) int { return g[j][i] }},
+		{"box", func(i, j int) int { return g[i/3*3+j/3][i%3*3+j%3] }},
+	}
+	for _, unit := range units {
+		for i := range core.Size {
+			var seen [core.Size + 1]bool
+			for j := range core.Size {
+				n := unit.square(i, j)
+				if n != 0 && seen[n] {
+					return fmt.Errorf("%s %d holds %d twice", unit.name, i+1, n)
+				}
+				seen[n] = true
+			}
+		}
+	}
+
+	switch solver.Solutions(g, nil, 2) {
+	case 0:
+		return errors.New("the puzzle has no solution")
+	case 2:
+		return errors.New("the puzzle has more than one solution")
+	}
+
+	return nil
+}
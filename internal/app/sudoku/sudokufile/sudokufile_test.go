@@ -0,0 +1,90 @@
+package sudokufile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/sudoku/core"
+)
+
+// euler is the first puzzle of Project Euler's problem 96.
+const euler = "003020600900305001001806400008102900700000008006708200002609500800203009005010300"
+
+func TestRead(t *testing.T) {
+	dotted := strings.ReplaceAll(euler, "0", ".")
+	puzzles, err := Read(strings.NewReader("# two puzzles\n" + euler + "\n\n" + dotted + " rated 1.2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(puzzles) != 2 || puzzles[0] != puzzles[1] || puzzles[0][0] != [core.Size]int{0, 0, 3, 0, 2, 0, 6, 0, 0} {
+		t.Fatalf("expected the same puzzle twice, got %v", puzzles)
+	}
+
+	var sdk strings.Builder
+	Write(&sdk, puzzles[0], SDK)
+	grid, err := Read(strings.NewReader("#Aeuler\n" + sdk.String()))
+	if err != nil || len(grid) != 1 || grid[0] != puzzles[0] {
+		t.Errorf("expected the SDK grid to read back, got %v, %v", grid, err)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	for _, test := range []struct {
+		input, want string
+	}{
+		{"", "no puzzle found"},
+		{"# just a comment\n" + euler[:80], "line 2: 80 squares, want 81"},
+		{euler + "\n" + euler[:40] + "x" + euler[41:], `line 2, column 5: 'x' isn't a number or a blank`},
+		{"003020600\n900305001\n", "the grid has 2 rows, want 9"},
+	} {
+		_, err := Read(strings.NewReader(test.input))
+		if err == nil || err.Error() != test.want {
+			t.Errorf("expected %q, got %v", test.want, err)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	puzzles, _ := Read(strings.NewReader(euler))
+
+	for format, want := range map[Format]string{
+		Line: strings.ReplaceAll(euler, "0", ".") + "\n",
+		SDM:  euler + "\n",
+	} {
+		var b strings.Builder
+		if err := Write(&b, puzzles[0], format); err != nil || b.String() != want {
+			t.Errorf("expected %q, got %q", want, b.String())
+		}
+	}
+
+	if FormatOf("puzzles.SDK") != SDK || FormatOf("puzzles.sdm") != SDM || FormatOf("puzzles.txt") != Line {
+		t.Error("expected the format to follow the extension")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	puzzles, _ := Read(strings.NewReader(euler))
+	g := puzzles[0]
+	if err := Validate(g); err != nil {
+		t.Fatal(err)
+	}
+
+	repeated := g
+	repeated[1][1] = 9
+	if err := Validate(repeated); err == nil || err.Error() != "row 2 holds 9 twice" {
+		t.Errorf("expected the repeated 9 to be found, got %v", err)
+	}
+
+	// 3 can't go at the top left of the grid once the rest of its row is
+	// full, since its column holds one.
+	contradictory := core.Grid{}
+	contradictory[0] = [core.Size]int{0, 1, 2, 4, 5, 6, 7, 8, 9}
+	contradictory[1][0] = 3
+	if err := Validate(contradictory); err == nil || err.Error() != "the puzzle has no solution" {
+		t.Errorf("expected no solution, got %v", err)
+	}
+
+	if err := Validate(core.Grid{}); err == nil || err.Error() != "the puzzle has more than one solution" {
+		t.Errorf("expected several solutions, got %v", err)
+	}
+}
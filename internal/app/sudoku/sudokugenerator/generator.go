@@ -1,109 +1,320 @@
 package sudokugenerator
 
 import (
+	"fmt"
 	"math/rand/v2"
-	"slices"
+
+	"github.com/Kaamkiya/gg/internal/app/sudoku/core"
+	"github.com/Kaamkiya/gg/internal/app/sudoku/solver"
+)
+
+// Variant is a kind of sudoku, with rules of its own.
+type Variant int
+
+const (
+	// Classic sudoku has rows, columns and 3x3 boxes which must hold 1-9.
+	Classic Variant = iota
+
+	// Diagonal sudoku also needs both diagonals to hold 1-9.
+	Diagonal
+
+	// Jigsaw sudoku has irregular regions instead of boxes.
+	Jigsaw
+
+	// Killer sudoku adds cages of squares whose numbers add up to a given
+	// sum, and gives few numbers or none.
+	Killer
 )
 
+// Variants are the variants by name.
+var Variants = []string{"classic", "diagonal", "jigsaw", "killer"}
+
+func (v Variant) String() string {
+	return Variants[v]
+}
+
+// ParseVariant returns the variant with the given name.
+func ParseVariant(name string) (Variant, error) {
+	for v, n := range Variants {
+		if n == name {
+			return Variant(v), nil
+		}
+	}
+
+	return 0, fmt.Errorf("sudoku: unknown variant %q", name)
+}
+
 type Model struct {
 	Grid [][]int
+
+	// Rules are the rules of the puzzle, nil for classic sudoku.
+	Rules *core.Rules
+
+	rng *rand.Rand
 }
 
-func (m *Model) unusedInBox(row, col, n int) bool {
-	for i := range 3 {
-		for j := range 3 {
-			if m.Grid[row+i][col+j] == n {
-				return false
-			}
+func (m *Model) isSafe(row, col, n int) bool {
+	for _, sq := range m.Rules.Peers(row, col) {
+		if m.Grid[sq.Row][sq.Col] == n {
+			return false
 		}
 	}
 
 	return true
 }
 
-func (m *Model) fillBox(row, col int) {
-	var n int
-	for i := range 3 {
-		for j := range 3 {
-			for !m.unusedInBox(row, col, n) {
-				n = rand.IntN(9) + 1
+// clues is the number of squares filled in at random before the solver fills
+// in the rest of the grid. Few enough of them leave the grid a solution most of
+// the time.
+const clues = 11
+
+// fillLimit is the number of numbers the solver tries when filling in the
+// grid before starting again with other clues. Most grids take far fewer, but
+// a few take ages.
+const fillLimit = 1000
+
+// fill fills in the grid at random, and reports whether it could: a jigsaw
+// grid may have no solution at all.
+func (m *Model) fill() bool {
+	for range 10 {
+		var g core.Grid
+		m.Grid = rowsOf(g)
+		for range clues {
+			row, col, n := m.rng.IntN(9), m.rng.IntN(9), m.rng.IntN(9)+1
+			if m.Grid[row][col] == 0 && m.isSafe(row, col, n) {
+				m.Grid[row][col] = n
 			}
-			m.Grid[row+i][col+j] = n
+		}
+
+		if solution, ok := solver.SolveWithin(gridOf(m.Grid), m.Rules, fillLimit); ok {
+			m.Grid = rowsOf(solution)
+			return true
 		}
 	}
+
+	return false
 }
 
-func (m *Model) unusedInCol(col, n int) bool {
-	for j := range 9 {
-		if m.Grid[j][col] == n {
-			return false
+// swaps is the number of squares swapped between neighbouring regions to turn
+// the boxes into jigsaw regions.
+const swaps = 60
+
+// jigsaw returns irregular regions of nine squares each, made by swapping
+// squares between neighbouring boxes as long as every region stays in one
+// piece.
+func (m *Model) jigsaw() [core.Size][core.Size]int {
+	regions := core.Boxes()
+	for done := 0; done < swaps; {
+		// A square of region a next to region b...
+		row, col := m.rng.IntN(9), m.rng.IntN(9)
+		d := neighbours[m.rng.IntN(len(neighbours))]
+		if !inGrid(row+d[0], col+d[1]) {
+			continue
+		}
+		a, b := regions[row][col], regions[row+d[0]][col+d[1]]
+		if a == b {
+			continue
+		}
+
+		// ...goes to b while a square of b next to a goes to a.
+		var others [][2]int
+		for r := range core.Size {
+			for c := range core.Size {
+				if regions[r][c] == b && borders(regions, r, c, a) {
+					others = append(others, [2]int{r, c})
+				}
+			}
+		}
+		other := others[m.rng.IntN(len(others))]
+
+		regions[row][col], regions[other[0]][other[1]] = b, a
+		if connected(regions, a) && connected(regions, b) {
+			done++
+		} else {
+			regions[row][col], regions[other[0]][other[1]] = a, b
 		}
 	}
 
-	return true
+	return regions
 }
 
-func (m *Model) unusedInRow(row, n int) bool {
-	return !slices.Contains(m.Grid[row], n)
+// neighbours are the directions of the squares next to a square.
+var neighbours = [][2]int{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+
+// borders reports whether the square at row and col is next to a square of
+// the region.
+func borders(regions [core.Size][core.Size]int, row, col, region int) bool {
+	for _, d := range neighbours {
+		if r, c := row+d[0], col+d[1]; inGrid(r, c) && regions[r][c] == region {
+			return true
+		}
+	}
+
+	return false
 }
 
-func (m *Model) isSafe(row, col, n int) bool {
-	return m.unusedInBox(row-row%3, col-col%3, n) && m.unusedInCol(col, n) && m.unusedInRow(row, n)
+func inGrid(row, col int) bool {
+	return row >= 0 && row < core.Size && col >= 0 && col < core.Size
 }
 
-func (m *Model) fillRemaining(row, col int) bool {
-	if row == 9 {
-		return true
+// connected reports whether the squares of the region are all in one piece.
+func connected(regions [core.Size][core.Size]int, region int) bool {
+	var seen [core.Size][core.Size]bool
+	var stack [][2]int
+	for row := range core.Size {
+		for col := range core.Size {
+			if regions[row][col] == region && stack == nil {
+				stack = append(stack, [2]int{row, col})
+				seen[row][col] = true
+			}
+		}
 	}
 
-	if col == 9 {
-		return m.fillRemaining(row+1, 0)
-	}
+	reached := 0
+	for len(stack) > 0 {
+		sq := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		reached++
 
-	if m.Grid[row][col] != 0 {
-		return m.fillRemaining(row, col+1)
+		for _, d := range neighbours {
+			r, c := sq[0]+d[0], sq[1]+d[1]
+			if inGrid(r, c) && !seen[r][c] && regions[r][c] == region {
+				seen[r][c] = true
+				stack = append(stack, [2]int{r, c})
+			}
+		}
 	}
 
-	for n := 1; n < 10; n++ {
-		if m.isSafe(row, col, n) {
-			m.Grid[row][col] = n
-			if m.fillRemaining(row, col+1) {
-				return true
+	return reached == core.Size
+}
+
+// cages splits the solved grid into cages of two to four squares, and a few of
+// one where a square is left with no room to grow.
+func (m *Model) cages() []core.Cage {
+	var caged [core.Size][core.Size]bool
+	var cages []core.Cage
+
+	for _, id := range m.rng.Perm(core.Size * core.Size) {
+		row, col := id/core.Size, id%core.Size
+		if caged[row][col] {
+			continue
+		}
+
+		cage := core.Cage{Squares: []core.Square{{Row: row, Col: col}}, Sum: m.Grid[row][col]}
+		caged[row][col] = true
+		used := 1 << m.Grid[row][col]
+
+		for size := 2 + m.rng.IntN(3); len(cage.Squares) < size; {
+			var next []core.Square
+			for _, sq := range cage.Squares {
+				for _, d := range neighbours {
+					r, c := sq.Row+d[0], sq.Col+d[1]
+					if inGrid(r, c) && !caged[r][c] && used&(1<<m.Grid[r][c]) == 0 {
+						next = append(next, core.Square{Row: r, Col: c})
+					}
+				}
+			}
+			if len(next) == 0 {
+				break
 			}
-			m.Grid[row][col] = 0
+
+			sq := next[m.rng.IntN(len(next))]
+			cage.Squares = append(cage.Squares, sq)
+			cage.Sum += m.Grid[sq.Row][sq.Col]
+			caged[sq.Row][sq.Col] = true
+			used |= 1 << m.Grid[sq.Row][sq.Col]
+		}
+
+		cages = append(cages, cage)
+	}
+
+	return cages
+}
+
+// attempts is the number of solved grids dug into before settling for the
+// puzzle closest to the difficulty asked for. Hard puzzles are the rarest.
+const attempts = 200
+
+// dig empties the squares of the solved grid in a random order, skipping those
+// that would give the puzzle another solution or make it harder than d, and
+// returns the puzzle left.
+func (m *Model) dig(d solver.Difficulty) core.Grid {
+	puzzle := gridOf(m.Grid)
+
+	for _, id := range m.rng.Perm(core.Size * core.Size) {
+		i, j := id/core.Size, id%core.Size
+
+		n := puzzle[i][j]
+		puzzle[i][j] = 0
+		if solver.Solutions(puzzle, m.Rules, 2) != 1 || solver.Grade(puzzle, m.Rules) > d {
+			puzzle[i][j] = n
 		}
 	}
 
-	return false
+	return puzzle
 }
 
-func (m *Model) emptyCells(amount int) {
-	for amount > 0 {
-		id := rand.IntN(81)
-		i := id / 9
-		j := id % 9
+// generate lays out the rules of the variant and fills in a grid following
+// them.
+func (m *Model) generate(v Variant) {
+	for {
+		switch v {
+		case Classic:
+			m.Rules = nil
+		case Diagonal:
+			m.Rules = &core.Rules{Regions: core.Boxes(), Diagonal: true}
+		case Jigsaw:
+			m.Rules = &core.Rules{Regions: m.jigsaw()}
+		case Killer:
+			m.Rules = &core.Rules{Regions: core.Boxes()}
+		}
 
-		if m.Grid[i][j] != 0 {
-			m.Grid[i][j] = 0
-			amount--
+		if m.fill() {
+			break
 		}
 	}
+
+	if v == Killer {
+		m.Rules.Cages = m.cages()
+	}
 }
 
-func (m *Model) generate() {
-	for i := 0; i < 9; i += 3 {
-		m.fillBox(i, i)
+// Init generates a new puzzle of the variant with a single solution and the
+// given difficulty, drawing every random number from rng.
+func (m *Model) Init(rng *rand.Rand, v Variant, d solver.Difficulty) {
+	m.rng = rng
+
+	var best core.Grid
+	var bestRules *core.Rules
+	bestGap := -1
+	for range attempts {
+		m.generate(v)
+
+		puzzle := m.dig(d)
+		gap := int(d - solver.Grade(puzzle, m.Rules))
+		if bestGap < 0 || gap < bestGap {
+			best, bestRules, bestGap = puzzle, m.Rules, gap
+		}
+		if gap == 0 {
+			break
+		}
 	}
 
-	m.fillRemaining(0, 0)
+	m.Grid, m.Rules = rowsOf(best), bestRules
 }
 
-func (m *Model) Init() {
-	m.Grid = make([][]int, 9)
-	for i := range m.Grid {
-		m.Grid[i] = make([]int, 9)
+func rowsOf(g core.Grid) [][]int {
+	rows := make([][]int, core.Size)
+	for i := range g {
+		rows[i] = g[i][:]
 	}
+	return rows
+}
 
-	m.generate()
-	m.emptyCells(54)
+func gridOf(rows [][]int) core.Grid {
+	var g core.Grid
+	for i := range g {
+		copy(g[i][:], rows[i])
+	}
+	return g
 }
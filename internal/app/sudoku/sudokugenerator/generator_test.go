@@ -1,43 +1,97 @@
 package sudokugenerator
 
-import "testing"
+import (
+	"math/rand/v2"
+	"reflect"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/sudoku/core"
+	"github.com/Kaamkiya/gg/internal/app/sudoku/solver"
+)
 
 func TestGen(t *testing.T) {
-	m := Model{}
-	m.Init()
+	for v := Classic; v <= Killer; v++ {
+		m := Model{rng: rand.New(rand.NewPCG(1, 2))}
+		m.generate(v)
 
-	m.Grid = make([][]int, 9)
-	for i := range m.Grid {
-		m.Grid[i] = make([]int, 9)
-	}
-	m.generate()
+		if err := m.Rules.Validate(); err != nil {
+			t.Fatalf("%v: %v", v, err)
+		}
+		if got := core.NewVariant(gridOf(m.Grid), m.Rules).Outcome(); got != core.Solved {
+			t.Fatalf("%v: expected every row, column and region to hold 1-9", v)
+		}
 
-	for i := range 9 {
-		digit := i + 1
-		for r := range 9 {
-			if m.unusedInCol(r, digit) {
-				t.Fatalf("Digit %d is not present in column %d", digit, r)
-			}
-			if m.unusedInRow(r, digit) {
-				t.Fatalf("Digit %d is not present in row %d", digit, r)
-			}
-			if m.unusedInBox(r-r%3, r-r%3, digit) {
-				t.Fatalf("Digit %d is not present in box starting at (%d, %d)", digit, r-r%3, r-r%3)
+		solution := m.Grid
+		puzzle := m.dig(solver.Easy)
+		for i := range 9 {
+			for j := range 9 {
+				if n := puzzle[i][j]; n != 0 && n != solution[i][j] {
+					t.Fatalf("%v: the puzzle doesn't match the grid at (%d, %d)", v, i, j)
+				}
 			}
 		}
 	}
+}
 
-	m.emptyCells(20)
-	c := 0
-	for _, r := range m.Grid {
-		for _, n := range r {
-			if n == 0 {
-				c++
-			}
+func TestGenIsSeeded(t *testing.T) {
+	a, b := Model{}, Model{}
+	a.Init(rand.New(rand.NewPCG(42, 42)), Jigsaw, solver.Medium)
+	b.Init(rand.New(rand.NewPCG(42, 42)), Jigsaw, solver.Medium)
+
+	if !reflect.DeepEqual(a.Grid, b.Grid) || !reflect.DeepEqual(a.Rules, b.Rules) {
+		t.Fatal("The same seed should generate the same puzzle")
+	}
+}
+
+func TestPuzzlesHaveOneSolution(t *testing.T) {
+	check := func(v Variant, d solver.Difficulty, seed uint64) {
+		m := Model{}
+		m.Init(rand.New(rand.NewPCG(seed, seed)), v, d)
+
+		puzzle := gridOf(m.Grid)
+		if n := solver.Solutions(puzzle, m.Rules, 2); n != 1 {
+			t.Fatalf("expected the %v %v puzzle of seed %d to have one solution, got %d", d, v, seed, n)
+		}
+		if got := solver.Grade(puzzle, m.Rules); got != d {
+			t.Errorf("expected the %v puzzle of seed %d to be %v, got %v", v, seed, d, got)
+		}
+	}
+
+	for d := solver.Easy; d <= solver.Expert; d++ {
+		for seed := range uint64(3) {
+			check(Classic, d, seed)
+		}
+	}
+	for v := Diagonal; v <= Killer; v++ {
+		check(v, solver.Medium, 1)
+	}
+}
+
+func TestJigsawRegions(t *testing.T) {
+	m := Model{rng: rand.New(rand.NewPCG(3, 4))}
+	regions := m.jigsaw()
+
+	if err := (&core.Rules{Regions: regions}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for region := range core.Size {
+		if !connected(regions, region) {
+			t.Errorf("expected region %d to be in one piece", region+1)
+		}
+	}
+	if regions == core.Boxes() {
+		t.Error("expected the regions not to be the boxes")
+	}
+}
+
+func TestParseVariant(t *testing.T) {
+	for _, name := range Variants {
+		if v, err := ParseVariant(name); err != nil || v.String() != name {
+			t.Errorf("expected %q to parse, got %v, %v", name, v, err)
 		}
 	}
 
-	if c != 20 {
-		t.Fatalf("Not enough empty cells: wanted=20 got=%d", c)
+	if _, err := ParseVariant("samurai"); err == nil {
+		t.Error("expected an unknown variant to be refused")
 	}
 }
@@ -0,0 +1,213 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/app/sudoku/core"
+	"github.com/Kaamkiya/gg/internal/app/sudoku/solver"
+	"github.com/Kaamkiya/gg/internal/app/sudoku/sudokugenerator"
+	"github.com/Kaamkiya/gg/internal/game"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// snapshot is the saved state of a suspended puzzle.
+type snapshot struct {
+	Puzzle  [][]int `json:"puzzle"`
+	Grid    [][]int `json:"grid"`
+	CursorX int     `json:"cursor_x"`
+	CursorY int     `json:"cursor_y"`
+
+	// Elapsed is the time spent on the puzzle so far.
+	Elapsed time.Duration `json:"elapsed"`
+
+	Mistakes int  `json:"mistakes,omitempty"`
+	Undos    int  `json:"undos,omitempty"`
+	Hints    int  `json:"hints,omitempty"`
+	Checking bool `json:"checking,omitempty"`
+	Checked  bool `json:"checked,omitempty"`
+
+	// Notes holds the numbers pencilled in each square, one bit each, and
+	// Noting whether the number keys pencil them in.
+	Notes  [][]core.Notes `json:"notes,omitempty"`
+	Noting bool           `json:"noting,omitempty"`
+
+	// Difficulty is missing from the puzzles suspended before they were
+	// graded.
+	Difficulty string `json:"difficulty,omitempty"`
+
+	Source string `json:"source,omitempty"`
+
+	// Variant and Rules are missing from classic puzzles.
+	Variant string      `json:"variant,omitempty"`
+	Rules   *core.Rules `json:"rules,omitempty"`
+
+	Options game.Options `json:"options"`
+}
+
+func (m model) Suspend() any {
+	if m.solved {
+		return nil
+	}
+
+	var variant string
+	if m.variant != sudokugenerator.Classic {
+		variant = m.variant.String()
+	}
+
+	return snapshot{
+		Puzzle:     rowsOf(m.state.Puzzle),
+		Grid:       rowsOf(m.state.Grid),
+		CursorX:    m.cursorx,
+		CursorY:    m.cursory,
+		Elapsed:    m.elapsed + time.Since(m.started),
+		Mistakes:   m.mistakes,
+		Undos:      m.history.Undos(),
+		Hints:      m.hints,
+		Checking:   m.checking,
+		Checked:    m.checked,
+		Notes:      notesOf(m.state.Notes),
+		Noting:     m.noting,
+		Difficulty: m.difficulty.String(),
+		Source:     m.source,
+		Variant:    variant,
+		Rules:      m.state.Rules,
+		Options:    m.opts,
+	}
+}
+
+func resume(data []byte) (tea.Model, error) {
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+
+	if len(s.Puzzle) != 9 || len(s.Grid) != 9 {
+		return nil, errors.New("the grid isn't 9x9")
+	}
+
+	for i := range 9 {
+		if len(s.Puzzle[i]) != 9 || len(s.Grid[i]) != 9 {
+			return nil, errors.New("the grid isn't 9x9")
+		}
+
+		for j := range 9 {
+			if s.Grid[i][j] < 0 || s.Grid[i][j] > 9 {
+				return nil, errors.New("the grid holds a number outside 0-9")
+			}
+			if s.Puzzle[i][j] != 0 && s.Puzzle[i][j] != s.Grid[i][j] {
+				return nil, errors.New("a given number has been changed")
+			}
+		}
+	}
+
+	if len(s.Notes) != 0 {
+		if len(s.Notes) != 9 {
+			return nil, errors.New("the notes aren't 9x9")
+		}
+		for _, row := range s.Notes {
+			if len(row) != 9 {
+				return nil, errors.New("the notes aren't 9x9")
+			}
+			for _, notes := range row {
+				if notes&^core.AllNotes != 0 {
+					return nil, errors.New("the notes hold a number outside 1-9")
+				}
+			}
+		}
+	}
+
+	if s.CursorX < 0 || s.CursorX > 8 || s.CursorY < 0 || s.CursorY > 8 {
+		return nil, errors.New("the cursor is off the grid")
+	}
+
+	variant := sudokugenerator.Classic
+	if s.Variant != "" {
+		var err error
+		if variant, err = sudokugenerator.ParseVariant(s.Variant); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.Rules.Validate(); err != nil {
+		return nil, err
+	}
+
+	state := core.NewVariant(gridOf(s.Puzzle), s.Rules)
+	state.Grid = gridOf(s.Grid)
+	for i, row := range s.Notes {
+		copy(state.Notes[i][:], row)
+	}
+
+	solution, ok := solver.Solve(state.Puzzle, state.Rules)
+	if !ok {
+		return nil, errors.New("the puzzle has no solution")
+	}
+
+	difficulty := solver.Grade(state.Puzzle, state.Rules)
+	if s.Difficulty != "" {
+		var err error
+		if difficulty, err = solver.ParseDifficulty(s.Difficulty); err != nil {
+			return nil, err
+		}
+	}
+
+	// Daily challenges aren't suspended, so the puzzle can be undone and
+	// hints are allowed.
+	history := game.NewHistory[core.State](game.UnlimitedUndo)
+	history.Resume(s.Undos)
+
+	return model{
+		opts:       s.Options,
+		keys:       game.Keys("sudoku"),
+		state:      state,
+		difficulty: difficulty,
+		variant:    variant,
+		solution:   solution,
+		source:     s.Source,
+		cursorx:    s.CursorX,
+		cursory:    s.CursorY,
+		started:    time.Now(),
+		elapsed:    s.Elapsed,
+		mistakes:   s.Mistakes,
+		history:    history,
+		assisted:   true,
+		checking:   s.Checking,
+		checked:    s.Checked || s.Checking,
+		noting:     s.Noting,
+		hints:      s.Hints,
+	}, nil
+}
+
+// rowsOf and gridOf convert between grids and the rows of the snapshot, which
+// are slices so that a snapshot of the wrong size can be told apart.
+func rowsOf(g core.Grid) [][]int {
+	rows := make([][]int, core.Size)
+	for i := range g {
+		rows[i] = g[i][:]
+	}
+	return rows
+}
+
+func gridOf(rows [][]int) core.Grid {
+	var g core.Grid
+	for i := range g {
+		copy(g[i][:], rows[i])
+	}
+	return g
+}
+
+// notesOf returns the rows of notes of the snapshot, or none if there aren't
+// any, to keep the snapshots of puzzles without notes small.
+func notesOf(notes [core.Size][core.Size]core.Notes) [][]core.Notes {
+	if notes == ([core.Size][core.Size]core.Notes{}) {
+		return nil
+	}
+
+	rows := make([][]core.Notes, core.Size)
+	for i := range notes {
+		rows[i] = notes[i][:]
+	}
+	return rows
+}
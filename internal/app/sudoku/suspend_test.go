@@ -0,0 +1,84 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/sudoku/core"
+	"github.com/Kaamkiya/gg/internal/app/sudoku/solver"
+	"github.com/Kaamkiya/gg/internal/app/sudoku/sudokugenerator"
+	"github.com/Kaamkiya/gg/internal/game"
+)
+
+func TestSuspendAndResume(t *testing.T) {
+	m := newModel(game.Seeded(1), sudokugenerator.Classic, solver.Easy, game.UnlimitedUndo)
+	m.cursorx, m.cursory = 4, 7
+
+	m.state.Step(core.Move{Row: 7, Col: 4, Value: 5})
+	m.mistakes = 2
+	m.state.FillNotes()
+	m.noting = true
+
+	data, err := json.Marshal(m.Suspend())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resumed, err := resume(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The clock keeps running, so only the board is compared.
+	got := resumed.(model)
+	got.started, got.elapsed = m.started, m.elapsed
+	if !reflect.DeepEqual(got, m) {
+		t.Errorf("expected %+v, got %+v", m, got)
+	}
+}
+
+func TestResumeRejectsChangedGivens(t *testing.T) {
+	m := newModel(game.Seeded(1), sudokugenerator.Classic, solver.Easy, game.UnlimitedUndo)
+
+	for i, row := range m.state.Puzzle {
+		for j, c := range row {
+			if c != 0 {
+				m.state.Grid[i][j] = c%9 + 1
+				break
+			}
+		}
+	}
+
+	data, _ := json.Marshal(m.Suspend())
+	if _, err := resume(data); err == nil {
+		t.Error("expected a changed given to be rejected")
+	}
+}
+
+func TestSuspendAndResumeVariant(t *testing.T) {
+	m := newModel(game.Seeded(1), sudokugenerator.Jigsaw, solver.Easy, game.UnlimitedUndo)
+
+	data, err := json.Marshal(m.Suspend())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resumed, err := resume(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := resumed.(model)
+	if got.variant != sudokugenerator.Jigsaw || !reflect.DeepEqual(got.state.Rules, m.state.Rules) || got.solution != m.solution {
+		t.Error("expected the jigsaw regions to be resumed")
+	}
+
+	// Regions of the wrong size are refused.
+	var s snapshot
+	json.Unmarshal(data, &s)
+	s.Rules.Regions[0][0] = (s.Rules.Regions[0][0] + 1) % 9
+	data, _ = json.Marshal(s)
+	if _, err := resume(data); err == nil {
+		t.Error("expected uneven regions to be rejected")
+	}
+}
@@ -0,0 +1,398 @@
+// Package core holds the rules of tetris, free of any rendering and timing,
+// so that they can be tested on their own and played by bots. The host
+// decides how often the pieces fall, which gets faster as the level rises.
+package core
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/Kaamkiya/gg/internal/app/tetris/color"
+	"github.com/Kaamkiya/gg/internal/app/tetris/shape"
+)
+
+const (
+	// Height is the game area height counted in Tetris squares
+	Height = 20
+	// Width is the game area width counted in Tetris squares
+	Width = 10
+
+	// countdown is the number of pieces that trigger a difficulty increase
+	countdown = 10
+	// initialLevel is the factor that increases scoring and decreases the
+	// time between falls. Increased by 0.1 on difficulty increase.
+	initialLevel = 1.0
+)
+
+// Grid is the game area, the color of each box, where a box of color None
+// is empty. The falling piece is part of it.
+type Grid [Height][Width]color.Color
+
+// Action is something that can happen in the game: the player moving,
+// rotating or dropping the falling piece, the piece falling one line, or the
+// full lines being cleared.
+type Action int
+
+const (
+	Left Action = iota
+	Right
+	RotateLeft
+	RotateRight
+	Drop
+	Fall
+	Clear
+)
+
+// Outcome tells whether the game is over.
+type Outcome int
+
+const (
+	Playing Outcome = iota
+
+	// ToppedOut is the outcome once a piece has landed at the top.
+	ToppedOut
+)
+
+// DropStatus tells how far the falling piece is through a drop.
+type DropStatus int
+
+const (
+	// DropStarted is the status of a piece just dropped, which the player
+	// has another fall to arrange.
+	DropStarted DropStatus = iota
+	DropInProgress
+	DropFinished
+)
+
+// State is a position of the game. The shapes are picked with rand, so two
+// games played with generators in the same state get the same shapes.
+type State struct {
+	Grid    Grid
+	Current *shape.Shape
+	Next    *shape.Shape
+
+	Score uint
+
+	// Level is the factor of the points scored, and Countdown the number of
+	// pieces left to land before it rises.
+	Level     float32
+	Countdown int
+
+	Drop DropStatus
+
+	// Full holds the lines completed by the piece that landed last, until
+	// they are cleared.
+	Full []int
+
+	over       bool
+	randomizer *shape.Randomizer
+}
+
+// New returns an empty game starting at the given level, where level 1 is
+// the easiest. Each level above 1 is one difficulty increase.
+func New(level int, r *rand.Rand) State {
+	return State{
+		Level:      initialLevel + 0.1*float32(level-1),
+		Countdown:  countdown,
+		Drop:       DropFinished,
+		randomizer: shape.NewRandomizer(r),
+	}
+}
+
+// Load returns a game continued from s, whose shapes were picked last as in
+// history, which History returns. The lines of the grid that are full wait
+// to be cleared, as they would once a piece has landed.
+func Load(s State, history []int, r *rand.Rand) (State, error) {
+	randomizer, err := shape.RestoreRandomizer(r, history)
+	if err != nil {
+		return State{}, err
+	}
+	if s.Level < initialLevel || s.Countdown < 1 {
+		return State{}, errors.New("tetris: the difficulty is out of range")
+	}
+
+	s.randomizer = randomizer
+	s.Full = s.completeLines(0, Height-1)
+	return s, nil
+}
+
+// History returns the shapes picked last, which steer the next picks.
+func (s State) History() []int {
+	return s.randomizer.History()
+}
+
+// Legal returns the actions that can be taken: only Clear while there are
+// full lines, only Fall while there is no piece falling, and every other
+// action otherwise. There are none once the game is over.
+func (s State) Legal() []Action {
+	switch {
+	case s.over:
+		return nil
+	case len(s.Full) != 0:
+		return []Action{Clear}
+	case s.Current == nil:
+		return []Action{Fall}
+	}
+
+	return []Action{Left, Right, RotateLeft, RotateRight, Drop, Fall}
+}
+
+// Step takes an action. The falling piece stays where it is if it can't move
+// or rotate. A dropped piece goes to the bottom at once, but only lands on
+// the second fall after, unless it is dropped again.
+func (s *State) Step(a Action) error {
+	switch {
+	case s.over:
+		return errors.New("tetris: the game is over")
+	case a == Clear:
+		if len(s.Full) == 0 {
+			return errors.New("tetris: there are no full lines")
+		}
+		s.clear()
+		return nil
+	case a < Left || a > Fall:
+		return errors.New("tetris: unknown action")
+	case len(s.Full) != 0:
+		return errors.New("tetris: the full lines must be cleared first")
+	case s.Current == nil && a != Fall:
+		return errors.New("tetris: there is no falling piece")
+	}
+
+	switch a {
+	case Left:
+		s.move(s.Current.MoveLeft)
+	case Right:
+		s.move(s.Current.MoveRight)
+	case RotateLeft:
+		s.move(s.Current.RotateLeft)
+	case RotateRight:
+		s.move(s.Current.RotateRight)
+	case Drop:
+		s.drop()
+	case Fall:
+		s.fall()
+	}
+
+	return nil
+}
+
+// Outcome returns ToppedOut once a piece has landed at the top.
+func (s State) Outcome() Outcome {
+	if s.over {
+		return ToppedOut
+	}
+
+	return Playing
+}
+
+// fall brings in the next piece if none is falling, or moves the falling one
+// down a line. Once it can't go further, it lands and the lines it completed
+// are full.
+func (s *State) fall() {
+	middleX := (Width / 2) - 1
+	if s.Next == nil {
+		newShape := shape.CreateNew(middleX, 0, s.randomizer)
+		s.Next = &newShape
+	}
+
+	if s.Current == nil {
+		newShape := shape.CreateNew(middleX, 0, s.randomizer)
+		s.Current = s.Next
+		s.Next = &newShape
+		s.addShapeToGrid(s.Current)
+		return
+	}
+
+	// Give the player a full fall to arrange the piece.
+	if s.Drop == DropStarted {
+		s.Drop = DropInProgress
+		return
+	}
+
+	if !s.move(s.Current.MoveDown) {
+		s.adjustDifficulty()
+		_, posY := s.Current.GetPosition()
+		completedLines := s.completeLines(posY, posY+s.Current.GetHeight()-1)
+
+		s.Current = nil
+		s.Drop = DropFinished
+
+		if len(completedLines) != 0 {
+			s.Full = completedLines
+			return
+		} else if posY == 0 {
+			s.over = true
+			return
+		}
+	}
+
+	s.addStillLivingScore()
+}
+
+// drop moves the piece to the bottom at once, but the drop is not finished
+// yet: players have another fall to arrange the piece. If the piece is at
+// the bottom already, dropping it again makes it land at once.
+func (s *State) drop() {
+	pieceMoved := false
+	for s.move(s.Current.MoveDown) {
+		pieceMoved = true
+		s.addLivingDangerouslyScore()
+	}
+
+	if pieceMoved {
+		s.Drop = DropStarted
+		return
+	}
+
+	s.Drop = DropFinished
+	s.fall()
+}
+
+// move replaces the falling piece by its transformation, if that is valid,
+// and reports whether it did.
+func (s *State) move(transformation func() shape.Shape) bool {
+	newShape := transformation()
+
+	s.deleteShapeFromGrid(s.Current)
+
+	if s.isShapeValid(newShape) {
+		s.Current = &newShape
+		s.addShapeToGrid(s.Current)
+
+		return true
+	}
+
+	s.addShapeToGrid(s.Current)
+	return false
+}
+
+// isShapeValid checks if a shape is valid by checking:
+//   - If the shape is inside the grid
+//   - If the shape does not overlap with any occupied box.
+func (s *State) isShapeValid(shape shape.Shape) bool {
+	shapeGrid := shape.GetGrid()
+	posX, posY := shape.GetPosition()
+
+	if posX < 0 {
+		return false
+	}
+
+	if posX+len(shapeGrid[0]) > Width || posY+len(shapeGrid) > Height {
+		return false
+	}
+
+	for i := range shapeGrid {
+		for j := range shapeGrid[i] {
+			if shapeGrid[i][j] {
+				if s.Grid[posY+i][posX+j] != color.None {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+func (s *State) addShapeToGrid(shape *shape.Shape) {
+	s.colorShape(shape, shape.GetColor())
+}
+
+func (s *State) deleteShapeFromGrid(shape *shape.Shape) {
+	s.colorShape(shape, color.None)
+}
+
+func (s *State) colorShape(shape *shape.Shape, color color.Color) {
+	shapeGrid := shape.GetGrid()
+	posX, posY := shape.GetPosition()
+
+	for i := range shapeGrid {
+		for j := range shapeGrid[i] {
+			if shapeGrid[i][j] {
+				s.Grid[posY+i][posX+j] = color
+			}
+		}
+	}
+}
+
+// clear removes the full lines, scoring them, and moves the lines above them
+// down.
+func (s *State) clear() {
+	completedLines := slices.Clone(s.Full)
+	s.Full = nil
+
+	s.addLineScore(len(completedLines))
+	slices.Sort(completedLines)
+	slices.Reverse(completedLines)
+
+	// lines are removed with a single pass from bottom to top.The completedLines array
+	// is sorted in descending order and the first completed line is replaced by the one
+	// above it. If another completed line is encountered during replacing, the distanceToCopyFrom
+	// is increased to start copying from two places above and so on. The distanceToCopyFrom variable
+	// specifies both the lines to skip when replacing and the index of the next completed line in the
+	// completedLines array.
+	distanceToCopyFrom := 1
+
+	for i := completedLines[0]; i >= 0; i-- {
+		if i-distanceToCopyFrom < 0 {
+			return
+		}
+
+		if s.isLineEmpty(i) {
+			return
+		}
+
+		for distanceToCopyFrom < len(completedLines) && completedLines[distanceToCopyFrom] == i-distanceToCopyFrom {
+			distanceToCopyFrom++
+		}
+
+		for j := range Width {
+			s.Grid[i][j] = s.Grid[i-distanceToCopyFrom][j]
+		}
+	}
+}
+
+// completeLines returns the full lines from line from to line to, from the
+// bottom up.
+func (s *State) completeLines(from, to int) []int {
+	var completedLines []int
+	for i := to; i >= from; i-- {
+		if s.isLineCompleted(i) {
+			completedLines = append(completedLines, i)
+		}
+	}
+
+	return completedLines
+}
+
+func (s *State) isLineCompleted(line int) bool {
+	for i := range Width {
+		if s.Grid[line][i] == color.None {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *State) isLineEmpty(line int) bool {
+	for i := range Width {
+		if s.Grid[line][i] != color.None {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *State) adjustDifficulty() {
+	if s.Countdown <= 1 {
+		s.Countdown = countdown
+		s.Level += 0.1
+
+		return
+	}
+
+	s.Countdown--
+}
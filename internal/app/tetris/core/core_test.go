@@ -0,0 +1,143 @@
+package core
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/tetris/color"
+)
+
+func newRand() *rand.Rand {
+	return rand.New(rand.NewPCG(1, 2))
+}
+
+func TestASingleLineIsRemoved(t *testing.T) {
+	s := New(1, newRand())
+	for i := range Width {
+		s.Grid[Height-1][i] = color.Blue
+	}
+
+	s.Full = s.completeLines(19, 19)
+	if err := s.Step(Clear); err != nil {
+		t.Fatal(err)
+	}
+
+	if !s.isLineEmpty(19) {
+		t.Fatal("Completed single line not removed")
+	}
+	if s.Score != 100 {
+		t.Fatalf("expected 100 points for a line, got %d", s.Score)
+	}
+}
+
+func TestMultipleLinesAreRemoved(t *testing.T) {
+	s := New(1, newRand())
+	for i := range Width {
+		s.Grid[Height-1][i] = color.Blue
+		s.Grid[Height-3][i] = color.Blue
+		s.Grid[Height-4][i] = color.Blue
+	}
+
+	s.Grid[Height-2][0] = color.Blue
+	s.Grid[Height-5][0] = color.Blue
+
+	s.Full = s.completeLines(16, 19)
+	s.Step(Clear)
+
+	if s.Grid[Height-1][0] != color.Blue && s.Grid[Height-1][1] != color.None {
+		t.Fatal("Second to last line didn't drop when last line was completed")
+	}
+
+	if s.Grid[Height-2][0] != color.Blue && s.Grid[Height-2][1] != color.None {
+		t.Fatal("Fifth to last line didn't drop when third to last line was completed")
+	}
+
+	if !s.isLineEmpty(Height - 3) {
+		t.Fatal("Lines didn't move correctly when lines where completed")
+	}
+}
+
+func TestDrop(t *testing.T) {
+	s := New(1, newRand())
+	if len(s.Legal()) != 1 || s.Step(Left) == nil {
+		t.Fatal("expected only a fall before there is a piece")
+	}
+
+	s.Step(Fall)
+	if s.Current == nil || s.Next == nil {
+		t.Fatal("expected a piece to fall and another to come next")
+	}
+
+	s.Step(Drop)
+	if _, y := s.Current.GetPosition(); y+s.Current.GetHeight() != Height {
+		t.Fatalf("expected the piece at the bottom, got it at %d", y)
+	}
+	if s.Score == 0 {
+		t.Error("expected points for dropping the piece")
+	}
+
+	// The player has another fall to arrange the piece before it lands.
+	s.Step(Fall)
+	if s.Current == nil {
+		t.Fatal("expected the piece to land on the second fall")
+	}
+	s.Step(Fall)
+	if s.Current != nil || s.Countdown != countdown-1 {
+		t.Fatal("expected the piece to land")
+	}
+}
+
+func TestDropTwice(t *testing.T) {
+	s := New(1, newRand())
+	s.Step(Fall)
+	s.Step(Drop)
+	s.Step(Drop)
+
+	if s.Current != nil {
+		t.Fatal("expected a piece dropped twice to land at once")
+	}
+}
+
+func TestToppedOut(t *testing.T) {
+	s := New(1, newRand())
+	for s.Outcome() == Playing {
+		if err := s.Step(Fall); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if s.Legal() != nil || s.Step(Fall) == nil {
+		t.Error("expected no action once the game is over")
+	}
+}
+
+func TestFullLinesWaitToBeCleared(t *testing.T) {
+	s := New(1, newRand())
+	for i := range Width {
+		s.Grid[Height-1][i] = color.Blue
+	}
+
+	s, err := Load(s, s.History(), newRand())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Full) != 1 || s.Step(Fall) == nil {
+		t.Fatal("expected the full line to be cleared first")
+	}
+	if err := s.Step(Clear); err != nil || s.Full != nil {
+		t.Fatalf("expected the full line to be cleared, got %v", err)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	s := New(3, newRand())
+	if s.Level < 1.19 || s.Level > 1.21 {
+		t.Fatalf("expected level 3 to score 1.2 times the points, got %v", s.Level)
+	}
+
+	s.Countdown = 1
+	s.adjustDifficulty()
+	if s.Level < 1.29 || s.Countdown != countdown {
+		t.Fatalf("expected the level to rise, got %v", s.Level)
+	}
+}
@@ -0,0 +1,26 @@
+package core
+
+func (s *State) addLineScore(completedLinesNum int) {
+	switch completedLinesNum {
+	case 4:
+		s.scorePoints(800)
+	case 3:
+		s.scorePoints(500)
+	case 2:
+		s.scorePoints(300)
+	default:
+		s.scorePoints(100)
+	}
+}
+
+func (s *State) addStillLivingScore() {
+	s.scorePoints(1)
+}
+
+func (s *State) addLivingDangerouslyScore() {
+	s.scorePoints(2)
+}
+
+func (s *State) scorePoints(points uint) {
+	s.Score += uint(float32(points) * s.Level)
+}
@@ -1,38 +1,43 @@
 package tetris
 
 import (
+	"fmt"
+	"slices"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/Kaamkiya/gg/internal/app/tetris/color"
-	"github.com/Kaamkiya/gg/internal/app/tetris/shape"
+	"github.com/Kaamkiya/gg/internal/app/tetris/core"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/theme"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 type gameProgressTick struct{}
 
-func initialModel() gameState {
+func initialModel(level int, opts game.Options) gameState {
 	return gameState{
-		nil,
-		nil,
-		newGameboard(color.Colors),
-		shape.NewRandomizer(),
-		0,
-		&difficulty{
-			initialDifficulyCountDown,
-			initialDifficulyLevel,
-			initialGameProgressTickDelay,
-		},
-		false,
-		pieceDrop{
-			dropFinished,
-			false,
-		},
+		state:  core.New(level, opts.Rand),
+		colors: styles(),
+		opts:   opts,
+		keys:   game.Keys("tetris"),
 	}
 }
 
+// styles returns the style of each color in the current theme. The colors are
+// named after those of the dark theme; in other themes, they are the pieces of
+// the theme in the same order.
+func styles() map[color.Color]lipgloss.Style {
+	t := theme.Current()
+
+	styles := map[color.Color]lipgloss.Style{color.None: t.Text}
+	for c := color.Blue; c <= color.Beige; c++ {
+		styles[c] = t.Pieces[c-color.Blue]
+	}
+	return styles
+}
+
 func (gs *gameState) Init() tea.Cmd {
 	return func() tea.Msg {
 		return gameProgressTick{}
@@ -47,38 +52,45 @@ func (gs *gameState) Init() tea.Cmd {
 func (gs *gameState) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		if msg.String() == "ctrl+c" || msg.String() == "q" || msg.String() == "Q" {
+		action := gs.keys.Action(msg)
+		if action == "quit" {
 			return gs, tea.Quit
+		} else if gs.state.Outcome() != core.Playing {
+			return gs, nil
 		} else if !gs.isPaused {
-			switch msg.String() {
-			case "h", "H", "left":
-				gs.handleLeft()
-			case "l", "L", "right":
-				gs.handleRight()
-			case "j", "J", "down":
+			switch action {
+			case "left":
+				gs.state.Step(core.Left)
+			case "right":
+				gs.state.Step(core.Right)
+			case "drop":
 				return gs, gs.handleDrop()
-			case "z", "Z":
-				gs.handleLeftRotate()
-			case "x", "X":
-				gs.handleRightRotate()
-			case "p", "P":
+			case "rotate-left":
+				gs.state.Step(core.RotateLeft)
+			case "rotate-right":
+				gs.state.Step(core.RotateRight)
+			case "pause":
 				gs.isPaused = true
 				return gs, nil
 			}
 		} else {
-			if msg.String() == "p" || msg.String() == "P" {
+			if action == "pause" {
 				gs.isPaused = false
-				return gs, tea.Tick(gs.currentDifficulty.gameProgressTickDelay, func(time.Time) tea.Msg { return gameProgressTick{} })
+				return gs, game.Tick(gs.gameProgressTickDelay(), gameProgressTick{})
 			}
 		}
+	case tea.BlurMsg:
+		if gs.state.Outcome() == core.Playing {
+			gs.isPaused = true
+		}
 	case gameProgressTick:
 		if gs.isPaused {
 			return gs, nil
 		}
 
 		// Skip a tick as droping a force scheduled a new one
-		if gs.pieceDrop.dropForced {
-			gs.pieceDrop.dropForced = false
+		if gs.dropForced {
+			gs.dropForced = false
 			return gs, nil
 		}
 		return gs, gs.handleGameProgressTick()
@@ -95,11 +107,11 @@ func (gs *gameState) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // line is appended.
 func (gs *gameState) View() string {
 	boardBuilder := strings.Builder{}
-	boardBuilder.Grow((height+2)*(width+2)*8 + 22*14)
+	boardBuilder.Grow((height+2)*(width+2)*8 + 22*15)
 
 	borderStyle := lipgloss.NewStyle().
 		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.AdaptiveColor{Light: "#200C0C", Dark: "#BEC1C6"})
+		BorderForeground(theme.Current().Border)
 
 	gameGridLines := buildGameGrid(gs)
 	sideBarLines := buildSidebar(gs)
@@ -142,8 +154,16 @@ func buildGameGrid(gs *gameState) [height * 2]string {
 		lineBuilder := strings.Builder{}
 		lineBuilder.Grow(width * 4)
 
+		c := gs.state.Grid[i]
+		if gs.highlighted && slices.Contains(gs.state.Full, i) {
+			c = [width]color.Color{}
+			for j := range c {
+				c[j] = color.Beige
+			}
+		}
+
 		for j := range width {
-			nextChar := gs.gameBoard.Colors[gs.gameBoard.Grid[i][j]].Render("    ")
+			nextChar := gs.colors[c[j]].Render("    ")
 			lineBuilder.WriteString(nextChar)
 		}
 
@@ -155,13 +175,13 @@ func buildGameGrid(gs *gameState) [height * 2]string {
 	return gridLines
 }
 
-func buildSidebar(gs *gameState) [14]string {
-	sidebarLines := [14]string{}
+func buildSidebar(gs *gameState) [15]string {
+	sidebarLines := [15]string{}
 	sidebarLines[0] = "      Next Shape      "
 	sidebarLines[1] = "                      "
 
-	if gs.nextShape != nil {
-		grid := gs.nextShape.GetGrid()
+	if gs.state.Next != nil {
+		grid := gs.state.Next.GetGrid()
 
 		for i := range 4 {
 			if i >= len(grid) {
@@ -173,7 +193,7 @@ func buildSidebar(gs *gameState) [14]string {
 
 				for j := range grid[i] {
 					if grid[i][j] {
-						lineBuilder.WriteString(gs.gameBoard.Colors[gs.nextShape.GetColor()].Render("  "))
+						lineBuilder.WriteString(gs.colors[gs.state.Next.GetColor()].Render("  "))
 					} else {
 						lineBuilder.WriteString("  ")
 					}
@@ -185,15 +205,21 @@ func buildSidebar(gs *gameState) [14]string {
 		}
 	}
 
-	scoreStr := strconv.FormatUint(uint64(gs.score), 10)
+	scoreStr := strconv.FormatUint(uint64(gs.state.Score), 10)
 	sidebarLines[6] = "                      "
 	sidebarLines[7] = "   Your score is      "
 	sidebarLines[8] = strings.Repeat(" ", 22-len(scoreStr)) + scoreStr
 	sidebarLines[9] = "                      "
-	sidebarLines[10] = "  hjl/←↓→ to move    "
-	sidebarLines[11] = "  z,x to rotate      "
-	sidebarLines[12] = "  q/ctl+c to quit    "
-	sidebarLines[13] = "  p to pause         "
+	if gs.state.Outcome() != core.Playing {
+		sidebarLines[9] = "      GAME OVER       "
+	}
+	sidebarLines[10] = fmt.Sprintf("  %-20s", game.HelpKey+" for the keys")
+	sidebarLines[11] = fmt.Sprintf("  %-20s", gs.keys.Keys("pause")+" to pause")
+	sidebarLines[12] = fmt.Sprintf("  %-20s", gs.keys.Keys("quit")+" to quit")
+	sidebarLines[13] = "                      "
+	if gs.state.Outcome() != core.Playing {
+		sidebarLines[14] = fmt.Sprintf("  seed: %-13d", gs.opts.Seed)
+	}
 
 	return sidebarLines
 }
@@ -1,307 +1,86 @@
 package tetris
 
 import (
-	"slices"
+	"fmt"
 	"time"
 
 	"github.com/Kaamkiya/gg/internal/app/tetris/color"
-	"github.com/Kaamkiya/gg/internal/app/tetris/shape"
+	"github.com/Kaamkiya/gg/internal/app/tetris/core"
+	"github.com/Kaamkiya/gg/internal/game"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 const (
 	// height is the game area height counted in Tetris squares
-	height = 20
-	// width is the game area height counted in Tetris squares
-	width = 10
+	height = core.Height
+	// width is the game area width counted in Tetris squares
+	width = core.Width
 
-	// initialGameProgressTickDelay is the game loop interval
+	// initialGameProgressTickDelay is the game loop interval at level 1
 	initialGameProgressTickDelay time.Duration = 300 * time.Millisecond
 )
 
-// gameboard represents the Tetris game area. The Grid is a fixed-size array
-// where each box contains a Color. The color of each box is used both for displaying
-// and for calculating if a box is empty where if the box is of color Black, it is
-// considered empty.
-type gameboard struct {
-	Colors map[color.Color]lipgloss.Style
-	Grid   [height][width]color.Color
-}
-
 // gameState contains the application state.
-//   - nextShape is the shape that will be dropped after the current one.
-//   - currentShape is the shape that is being dropped currently.
-//   - gameboard is the playing area
-//   - shapeRandomizer is used to find which shape is going to be dropped next.
+//   - state is the game itself.
+//   - colors is the style each color is drawn with.
 //   - isPaused is a flag which is true when the game is paused.
+//   - dropForced is a flag which is true when dropping a piece made it land
+//     at once, and scheduled a new tick doing so.
+//   - highlighted is a flag which is true while the full lines flash.
+//   - opts holds the seed of the random numbers picking the shapes.
 type gameState struct {
-	nextShape         *shape.Shape
-	currentShape      *shape.Shape
-	gameBoard         *gameboard
-	shapeRandomizer   *shape.Randomizer
-	score             uint
-	currentDifficulty *difficulty
-	isPaused          bool
-	pieceDrop         pieceDrop
+	state       core.State
+	colors      map[color.Color]lipgloss.Style
+	isPaused    bool
+	dropForced  bool
+	highlighted bool
+	opts        game.Options
+	keys        game.KeyMap
 }
 
-const (
-	dropIniated dropStatus = iota
-	dropInProgress
-	dropFinished
-)
-
-type dropStatus int
-
-type pieceDrop struct {
-	dropStatus dropStatus
-	dropForced bool
+// gameProgressTickDelay is the time between two falls of the pieces, which
+// shortens as the level rises.
+func (gs *gameState) gameProgressTickDelay() time.Duration {
+	return time.Duration(float32(initialGameProgressTickDelay) / gs.state.Level)
 }
 
-func newGameboard(colors map[color.Color]lipgloss.Style) *gameboard {
-	grid := [height][width]color.Color{}
-
-	return &gameboard{colors, grid}
-}
-
-// handleGameProgressTick updates the game state to simulate the current shape
-// dropping a line. The basic flow is:
-//  1. Create new shapes if needed
-//  2. Drop the current shape one line
-//  3. Check if any lines are completed
-//  4. Start the line clearing animation if needed, otherwise schedule the
-//     the next tick.
+// handleGameProgressTick makes the current shape fall a line and schedules
+// what comes next.
 func (gs *gameState) handleGameProgressTick() tea.Cmd {
-	middleX := (width / 2) - 1
-	if gs.nextShape == nil {
-		newShape := shape.CreateNew(middleX, 0, gs.shapeRandomizer)
-		gs.nextShape = &newShape
-	}
-
-	nextCmd := tea.Tick(gs.currentDifficulty.gameProgressTickDelay, func(time.Time) tea.Msg {
-		return gameProgressTick{}
-	})
-
-	if gs.currentShape == nil {
-		newShape := shape.CreateNew(middleX, 0, gs.shapeRandomizer)
-		gs.currentShape = gs.nextShape
-		gs.nextShape = &newShape
-		gs.addShapeToGrid(gs.currentShape)
-		return nextCmd
-	}
-
-	// Give the player a full tick to arrange the piece.
-	if gs.pieceDrop.dropStatus == dropIniated {
-		gs.pieceDrop.dropStatus = dropInProgress
-		return nextCmd
-	}
-
-	if !gs.applyTransformation(gs.currentShape.MoveDown) {
-		gs.adjustDifficulty()
-		_, posY := gs.currentShape.GetPosition()
-		completedLines := gs.checkForCompleteLines(posY, posY+gs.currentShape.GetHeight()-1)
-
-		gs.currentShape = nil
-		gs.pieceDrop.dropStatus = dropFinished
-
-		if len(completedLines) != 0 {
-			lineAnimationMsg := gs.constructLineAnimationMsg(completedLines)
-			return gs.handleLineAnimationTick(lineAnimationMsg)
-		} else if posY == 0 {
-			return tea.Quit
-		}
-	}
-
-	gs.addStillLivingScore()
-
-	return nextCmd
-}
-
-func (gs *gameState) handleLeft() {
-	if gs.currentShape == nil {
-		return
-	}
-
-	gs.applyTransformation(gs.currentShape.MoveLeft)
-}
-
-func (gs *gameState) handleRight() {
-	if gs.currentShape == nil {
-		return
+	if gs.state.Step(core.Fall) != nil {
+		return nil
 	}
 
-	gs.applyTransformation(gs.currentShape.MoveRight)
+	return gs.afterFall()
 }
 
 // handleDrop moves immediately the piece to the bottom but the drop is not finished yet.
 // Players will have time equal to another tick to arrange the piece before it is dropped fully.
-// This is achieved with the dropStatus variable.
 // If players press down again they force a drop which causes the piece to drop fully immediately.
 // In that case a new Tick is scheduled to progress the game.
 func (gs *gameState) handleDrop() tea.Cmd {
-	if gs.currentShape == nil {
-		return nil
-	}
-
-	pieceMoved := false
-	for gs.applyTransformation(gs.currentShape.MoveDown) {
-		pieceMoved = true
-		gs.addLivingDangerouslyScore()
-	}
-
-	if pieceMoved {
-		gs.pieceDrop.dropStatus = dropIniated
+	if gs.state.Step(core.Drop) != nil || gs.state.Current != nil {
 		return nil
 	}
 
-	gs.pieceDrop.dropStatus = dropFinished
-	gs.pieceDrop.dropForced = true
-
-	return gs.handleGameProgressTick()
-}
-
-func (gs *gameState) handleLeftRotate() {
-	if gs.currentShape == nil {
-		return
-	}
-
-	gs.applyTransformation(gs.currentShape.RotateLeft)
-}
-
-func (gs *gameState) handleRightRotate() {
-	if gs.currentShape == nil {
-		return
-	}
-
-	gs.applyTransformation(gs.currentShape.RotateRight)
-}
-
-func (gs *gameState) applyTransformation(tranformation func() shape.Shape) bool {
-	newShape := tranformation()
-
-	gs.deleteShapeFromGrid(gs.currentShape)
-
-	if gs.isShapeValid(newShape) {
-		gs.currentShape = &newShape
-		gs.addShapeToGrid(gs.currentShape)
-
-		return true
-	} else {
-		gs.addShapeToGrid(gs.currentShape)
-	}
-
-	return false
-}
-
-// isShapeValid checks if a shape is valid by checking:
-//   - If the shape is inside the gameBoard
-//   - If the shape does not overlap with any occupied box.
-func (gs *gameState) isShapeValid(shape shape.Shape) bool {
-	shapeGrid := shape.GetGrid()
-	posX, posY := shape.GetPosition()
-
-	if posX < 0 {
-		return false
-	}
-
-	if posX+len(shapeGrid[0]) > width || posY+len(shapeGrid) > height {
-		return false
-	}
-
-	for i := range shapeGrid {
-		for j := range shapeGrid[i] {
-			if shapeGrid[i][j] {
-				if gs.gameBoard.Grid[posY+i][posX+j] != color.None {
-					return false
-				}
-			}
-		}
-	}
-
-	return true
-}
-
-func (gs *gameState) addShapeToGrid(shape *shape.Shape) {
-	gs.modidfyColorGridFromShape(shape, shape.GetColor())
-}
-
-func (gs *gameState) deleteShapeFromGrid(shape *shape.Shape) {
-	gs.modidfyColorGridFromShape(shape, color.None)
-}
-
-func (gs *gameState) modidfyColorGridFromShape(shape *shape.Shape, color color.Color) {
-	shapeGrid := shape.GetGrid()
-	posX, posY := shape.GetPosition()
-
-	for i := range shapeGrid {
-		for j := range shapeGrid[i] {
-			if shapeGrid[i][j] {
-				gs.gameBoard.Grid[posY+i][posX+j] = color
-			}
-		}
-	}
-}
-
-func (gs *gameState) removeCompletedLines(completedLines []int) {
-	gs.addLineScore(len(completedLines))
-	slices.Sort(completedLines)
-	slices.Reverse(completedLines)
-
-	// lines are removed with a single pass from bottom to top.The completedLines array
-	// is sorted in descending order and the first completed line is replaced by the one
-	// above it. If another completed line is encountered during replacing, the distanceToCopyFrom
-	// is increased to start copying from two places above and so on. The distanceToCopyFrom variable
-	// specifies both the lines to skip when replacing and the index of the next completed line in the
-	// completedLines array.
-	distanceToCopyFrom := 1
-
-	for i := completedLines[0]; i >= 0; i-- {
-		if i-distanceToCopyFrom < 0 {
-			return
-		}
-
-		if gs.isLineEmpty(i) {
-			return
-		}
-
-		for distanceToCopyFrom < len(completedLines) && completedLines[distanceToCopyFrom] == i-distanceToCopyFrom {
-			distanceToCopyFrom++
-		}
-
-		for j := range width {
-			gs.gameBoard.Grid[i][j] = gs.gameBoard.Grid[i-distanceToCopyFrom][j]
-		}
-	}
-}
-
-func (gs *gameState) checkForCompleteLines(from, to int) []int {
-	completedLines := make([]int, 0, 4)
-	for i := to; i >= from; i-- {
-		if gs.isLineCompleted(i) {
-			completedLines = append(completedLines, i)
-		}
-	}
-
-	return completedLines
-}
-
-func (gs *gameState) isLineCompleted(line int) bool {
-	for i := range width {
-		if gs.gameBoard.Grid[line][i] == color.None {
-			return false
-		}
-	}
-
-	return true
+	gs.dropForced = true
+	return gs.afterFall()
 }
 
-func (gs *gameState) isLineEmpty(line int) bool {
-	for i := range width {
-		if gs.gameBoard.Grid[line][i] != color.None {
-			return false
-		}
+// afterFall schedules what comes after the current shape fell: the line
+// clearing animation if it landed and completed lines, the end of the game
+// if it landed at the top, or the next tick.
+func (gs *gameState) afterFall() tea.Cmd {
+	switch {
+	case len(gs.state.Full) != 0:
+		return gs.handleLineAnimationTick(gs.constructLineAnimationMsg(len(gs.state.Full)))
+	case gs.state.Outcome() == core.ToppedOut:
+		return game.Over(game.Result{
+			Score:   int(gs.state.Score),
+			Summary: fmt.Sprintf("%d points", gs.state.Score),
+		})
 	}
 
-	return true
+	return game.Tick(gs.gameProgressTickDelay(), gameProgressTick{})
 }
@@ -1,76 +1,59 @@
 package tetris
 
 import (
-	"maps"
-	"slices"
 	"time"
 
-	"github.com/Kaamkiya/gg/internal/app/tetris/color"
+	"github.com/Kaamkiya/gg/internal/app/tetris/core"
+	"github.com/Kaamkiya/gg/internal/game"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 // lineAnimationInterval is the animation refresh interval
 const lineAnimationInterval time.Duration = 100 * time.Millisecond
 
-// lineAnimationTick is a tea.Msg that contains the lines to change in a map
-// where the key is the line index and the value is the colors to apply.
-// Additionally, it holds how many animations (color changes) are left for the
-// animation to complete.
+// lineAnimationTick is a tea.Msg that holds how many animations (color
+// changes) of the full lines are left for the animation to complete.
 type lineAnimationTick struct {
-	linesToUpdate      map[int][width]color.Color
 	animationCountDown int
 }
 
-func (gs *gameState) constructLineAnimationMsg(completedLines []int) lineAnimationTick {
-	completedLineMap := make(map[int][width]color.Color, len(completedLines))
+func (gs *gameState) constructLineAnimationMsg(completedLines int) lineAnimationTick {
 	animationCountdown := 2
 
-	if len(completedLines) == 3 {
+	if completedLines == 3 {
 		animationCountdown = 4
 	}
 
-	if len(completedLines) == 4 {
+	if completedLines == 4 {
 		animationCountdown = 6
 	}
 
-	highlightedLine := [width]color.Color{}
-	for i := range width {
-		highlightedLine[i] = color.Beige
-	}
-
-	for _, v := range completedLines {
-		completedLineMap[v] = highlightedLine
-
-	}
-
-	return lineAnimationTick{
-		completedLineMap,
-		animationCountdown,
-	}
+	return lineAnimationTick{animationCountdown}
 }
 
-// handleLineAnimationTick performs the grid updates for the flashing animation when
-// lines are completed. If the animation is complete (animationCountDown set to 0) it
-// resumes the game. Otherwse it swaps the lines color and continues with the animation.
+// handleLineAnimationTick flashes the full lines when lines are completed. If
+// the animation is complete (animationCountDown set to 0) it clears them and
+// resumes the game. Otherwse it swaps the lines color and continues with the
+// animation.
 func (gs *gameState) handleLineAnimationTick(animationTick lineAnimationTick) tea.Cmd {
 	if animationTick.animationCountDown == 0 {
-		gs.removeCompletedLines(slices.Collect(maps.Keys(animationTick.linesToUpdate)))
-		return func() tea.Msg {
-			return gameProgressTick{}
+		lines := len(gs.state.Full)
+		gs.highlighted = false
+		if gs.state.Step(core.Clear) != nil {
+			return nil
 		}
-	}
 
-	animationTick.animationCountDown--
-	newLinesToUpdateMap := make(map[int][width]color.Color, len(animationTick.linesToUpdate))
-	for k, v := range animationTick.linesToUpdate {
-		newLinesToUpdateMap[k] = gs.gameBoard.Grid[k]
-		gs.gameBoard.Grid[k] = v
+		return tea.Batch(
+			game.Publish("lines", lines),
+			func() tea.Msg {
+				return gameProgressTick{}
+			},
+		)
 	}
 
-	return tea.Tick(lineAnimationInterval, func(time.Time) tea.Msg {
-		return lineAnimationTick{
-			newLinesToUpdateMap,
-			animationTick.animationCountDown,
-		}
+	gs.highlighted = !gs.highlighted
+
+	return game.Tick(lineAnimationInterval, lineAnimationTick{
+		animationTick.animationCountDown - 1,
 	})
 }
@@ -0,0 +1,41 @@
+package shape
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/Kaamkiya/gg/internal/app/tetris/color"
+)
+
+// jsonShape is the encoding of a Shape, which keeps its fields unexported so
+// that it can't be modified outside the package.
+type jsonShape struct {
+	X     int         `json:"x"`
+	Y     int         `json:"y"`
+	Grid  [][]bool    `json:"grid"`
+	Color color.Color `json:"color"`
+}
+
+func (s Shape) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonShape{s.posX, s.posY, s.grid, s.color})
+}
+
+func (s *Shape) UnmarshalJSON(data []byte) error {
+	var js jsonShape
+	if err := json.Unmarshal(data, &js); err != nil {
+		return err
+	}
+
+	if len(js.Grid) == 0 || len(js.Grid[0]) == 0 {
+		return errors.New("shape: empty grid")
+	}
+
+	for _, line := range js.Grid {
+		if len(line) != len(js.Grid[0]) {
+			return errors.New("shape: grid lines differ in length")
+		}
+	}
+
+	*s = Shape{js.X, js.Y, js.Grid, js.Color}
+	return nil
+}
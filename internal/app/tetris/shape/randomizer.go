@@ -1,22 +1,24 @@
 package shape
 
 import (
-	"math/rand"
+	"errors"
+	"math/rand/v2"
 	"slices"
 )
 
 // Randomizer makes the randrom pick of shapes to fill less 'unfair'. Inspired by info found
 // here: https://tetris.fandom.com/wiki/TGM_randomizer
 type Randomizer struct {
+	rng        *rand.Rand
 	lastValues []int
 }
 
 func (r *Randomizer) nextInt(maxValue int) int {
-	nextShape := rand.Intn(maxValue)
+	nextShape := r.rng.IntN(maxValue)
 
 	retries := 0
 	for retries < 6 && slices.Contains(r.lastValues, nextShape) {
-		nextShape = rand.Intn(maxValue)
+		nextShape = r.rng.IntN(maxValue)
 		retries++
 	}
 
@@ -26,7 +28,8 @@ func (r *Randomizer) nextInt(maxValue int) int {
 	return nextShape
 }
 
-func NewRandomizer() *Randomizer {
+// NewRandomizer returns a randomizer that picks shapes with rng.
+func NewRandomizer(rng *rand.Rand) *Randomizer {
 	lastValues := make([]int, 4)
 
 	lastValues[0] = Z
@@ -35,6 +38,22 @@ func NewRandomizer() *Randomizer {
 	lastValues[3] = S
 
 	return &Randomizer{
+		rng,
 		lastValues,
 	}
 }
+
+// History returns the shapes picked last, which steer the next picks.
+func (r *Randomizer) History() []int {
+	return slices.Clone(r.lastValues)
+}
+
+// RestoreRandomizer returns a randomizer that continues from the given
+// history, as returned by History.
+func RestoreRandomizer(rng *rand.Rand, history []int) (*Randomizer, error) {
+	if len(history) == 0 {
+		return nil, errors.New("shape: randomizer without history")
+	}
+
+	return &Randomizer{rng, slices.Clone(history)}, nil
+}
@@ -1,12 +1,13 @@
 package shape
 
 import (
+	"math/rand/v2"
 	"strconv"
 	"testing"
 )
 
 func TestNewRandomizerHasSZ(t *testing.T) {
-	randomizer := NewRandomizer()
+	randomizer := NewRandomizer(rand.New(rand.NewPCG(1, 2)))
 
 	if randomizer.lastValues[0] != Z ||
 		randomizer.lastValues[1] != S ||
@@ -18,7 +19,7 @@ func TestNewRandomizerHasSZ(t *testing.T) {
 }
 
 func TestNewRandomizerUpdatesStateCorrectlyOnNewInt(t *testing.T) {
-	randomizer := NewRandomizer()
+	randomizer := NewRandomizer(rand.New(rand.NewPCG(1, 2)))
 
 	firstShape := randomizer.nextInt(7)
 	secondShape := randomizer.nextInt(7)
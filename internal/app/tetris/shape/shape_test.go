@@ -1,6 +1,7 @@
 package shape
 
 import (
+	"math/rand/v2"
 	"reflect"
 	"testing"
 
@@ -8,7 +9,7 @@ import (
 )
 
 func TestShapeMoveDown(t *testing.T) {
-	shape := CreateNew(0, 0, NewRandomizer())
+	shape := CreateNew(0, 0, NewRandomizer(rand.New(rand.NewPCG(1, 2))))
 	movedDownShape := shape.MoveDown()
 
 	if shape.color != movedDownShape.color {
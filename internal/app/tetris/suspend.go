@@ -0,0 +1,98 @@
+package tetris
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/Kaamkiya/gg/internal/app/tetris/color"
+	"github.com/Kaamkiya/gg/internal/app/tetris/core"
+	"github.com/Kaamkiya/gg/internal/app/tetris/shape"
+	"github.com/Kaamkiya/gg/internal/game"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// snapshot is the saved state of a suspended game. The falling shape is part
+// of the grid as well, just like while playing.
+type snapshot struct {
+	Next      *shape.Shape    `json:"next"`
+	Current   *shape.Shape    `json:"current"`
+	Grid      core.Grid       `json:"grid"`
+	History   []int           `json:"history"`
+	Score     uint            `json:"score"`
+	Countdown int             `json:"countdown"`
+	Level     float32         `json:"level"`
+	Drop      core.DropStatus `json:"drop"`
+	Options   game.Options    `json:"options"`
+}
+
+func (gs *gameState) Suspend() any {
+	if gs.state.Outcome() != core.Playing {
+		return nil
+	}
+
+	return snapshot{
+		Next:      gs.state.Next,
+		Current:   gs.state.Current,
+		Grid:      gs.state.Grid,
+		History:   gs.state.History(),
+		Score:     gs.state.Score,
+		Countdown: gs.state.Countdown,
+		Level:     gs.state.Level,
+		Drop:      gs.state.Drop,
+		Options:   gs.opts,
+	}
+}
+
+// resume restores a suspended game. It starts paused, so that the player has
+// time to look at the board before the pieces start falling again.
+func resume(data []byte) (tea.Model, error) {
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+
+	for _, line := range s.Grid {
+		for _, c := range line {
+			if c < color.None || c > color.Beige {
+				return nil, errors.New("the grid holds an unknown color")
+			}
+		}
+	}
+
+	for _, sh := range []*shape.Shape{s.Next, s.Current} {
+		if sh == nil {
+			continue
+		}
+
+		x, y := sh.GetPosition()
+		grid := sh.GetGrid()
+		if x < 0 || y < 0 || x+len(grid[0]) > width || y+len(grid) > height {
+			return nil, errors.New("a shape is off the board")
+		}
+	}
+
+	state, err := core.Load(core.State{
+		Grid:      s.Grid,
+		Current:   s.Current,
+		Next:      s.Next,
+		Score:     s.Score,
+		Level:     s.Level,
+		Countdown: s.Countdown,
+		Drop:      s.Drop,
+	}, s.History, s.Options.Rand)
+	if err != nil {
+		return nil, err
+	}
+
+	// A game suspended while cleared lines were flashing still has them on
+	// the board. Finish clearing them, as the animation would have.
+	if len(state.Full) != 0 {
+		state.Step(core.Clear)
+	}
+
+	gs := initialModel(1, s.Options)
+	gs.state = state
+	gs.isPaused = true
+
+	return &gs, nil
+}
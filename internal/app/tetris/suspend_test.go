@@ -0,0 +1,75 @@
+package tetris
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/tetris/color"
+	"github.com/Kaamkiya/gg/internal/app/tetris/core"
+	"github.com/Kaamkiya/gg/internal/game"
+)
+
+func TestSuspendAndResume(t *testing.T) {
+	gamestate := initialModel(3, game.Seeded(7))
+	for range 5 {
+		gamestate.handleGameProgressTick()
+	}
+	gamestate.state.Step(core.Left)
+	gamestate.state.Score = 42
+	gamestate.state.Grid[height-1][0] = color.Orange
+
+	data, err := json.Marshal(gamestate.Suspend())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model, err := resume(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed := model.(*gameState)
+
+	if !resumed.isPaused {
+		t.Error("A resumed game should start paused")
+	}
+
+	gamestate.isPaused = true
+	if !reflect.DeepEqual(*resumed, gamestate) {
+		t.Fatalf("Resumed game differs:\nexpected %+v\ngot      %+v", gamestate, *resumed)
+	}
+}
+
+func TestResumeClearsFlashingLines(t *testing.T) {
+	gamestate := initialModel(1, game.Seeded(7))
+	for i := range width {
+		gamestate.state.Grid[height-1][i] = color.Beige
+	}
+	gamestate.state.Grid[height-2][0] = color.Blue
+
+	data, _ := json.Marshal(gamestate.Suspend())
+	model, err := resume(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed := model.(*gameState)
+
+	if resumed.state.Grid[height-1][0] != color.Blue || resumed.state.Grid[height-2] != [width]color.Color{} {
+		t.Fatal("Completed line not removed on resume")
+	}
+
+	if resumed.state.Score == 0 {
+		t.Fatal("Completed line not scored on resume")
+	}
+}
+
+func TestFinishedGameIsNotSuspended(t *testing.T) {
+	gamestate := initialModel(1, game.Seeded(7))
+	for gamestate.state.Outcome() == core.Playing {
+		gamestate.handleGameProgressTick()
+	}
+
+	if gamestate.Suspend() != nil {
+		t.Fatal("A finished game should not be suspended")
+	}
+}
@@ -1,20 +1,61 @@
 package tetris
 
 import (
+	"flag"
 	"fmt"
-	"os"
+
+	"github.com/Kaamkiya/gg/internal/game"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-func Run() {
-	initialModel := initialModel()
-	p := tea.NewProgram(&initialModel)
+// maxStartLevel is the highest level a game can be started at.
+const maxStartLevel = 10
 
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("An error: %v", err)
-		os.Exit(1)
-	}
+// bindings are the default keys of the player's actions.
+var bindings = []game.Binding{
+	{Action: "left", Keys: []string{"h", "H", "left"}, Help: "move left"},
+	{Action: "right", Keys: []string{"l", "L", "right"}, Help: "move right"},
+	{Action: "drop", Keys: []string{"j", "J", "down"}, Help: "drop"},
+	{Action: "rotate-left", Keys: []string{"z", "Z"}, Help: "rotate left"},
+	{Action: "rotate-right", Keys: []string{"x", "X"}, Help: "rotate right"},
+	{Action: "pause", Keys: []string{game.PauseKey, "P"}, Help: "pause"},
+	{Action: "quit", Keys: []string{"q", "Q"}, Help: "quit"},
+}
+
+func init() {
+	game.Register(game.Game{
+		ID:          "tetris",
+		Name:        "tetris",
+		Players:     1,
+		Description: "Stack the falling pieces and clear as many lines as you can.",
+		HighScores:  true,
+		Recordable:  true,
+		Keys:        bindings,
+		New: func(opts game.Options) tea.Model {
+			return newModel(1, opts)
+		},
+		Flags:  flags,
+		Resume: resume,
+		Daily: func(opts game.Options) tea.Model {
+			return newModel(1, opts)
+		},
+	})
+}
 
-	fmt.Println("")
+func newModel(level int, opts game.Options) tea.Model {
+	initialModel := initialModel(level, opts)
+	return &initialModel
+}
+
+func flags(fs *flag.FlagSet) func(game.Options) (tea.Model, error) {
+	level := fs.Int("level", 1, fmt.Sprintf("starting level, from 1 to %d", maxStartLevel))
+
+	return func(opts game.Options) (tea.Model, error) {
+		if *level < 1 || *level > maxStartLevel {
+			return nil, fmt.Errorf("the level must be between 1 and %d", maxStartLevel)
+		}
+
+		return newModel(*level, opts), nil
+	}
 }
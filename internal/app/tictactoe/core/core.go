@@ -0,0 +1,165 @@
+// Package core holds the rules of tictactoe between two players, free of any
+// rendering, so that they can be tested on their own and played by bots.
+//
+// Besides the classic 3x3 board, it plays m,n,k games: two players take turns
+// on a board of any size, and the first one with k marks in a row wins, such
+// as gomoku with five in a row on a 15x15 board.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// The marks of the players and the board. Tie is only ever returned by
+// Outcome.
+const (
+	Empty = ' '
+	X     = 'x'
+	O     = 'o'
+	Tie   = 't'
+)
+
+// directions are the steps along the rows, the columns and both diagonals.
+var directions = [...][2]int{{1, 0}, {0, 1}, {1, 1}, {1, -1}}
+
+// State is a position of the game.
+type State struct {
+	Width, Height int
+
+	// K is the number of marks in a row that wins.
+	K int
+
+	// Board has the cells row by row, numbered from 0 left to right and
+	// top to bottom.
+	Board []rune
+
+	// Turn is the mark of the player to move. X moves first.
+	Turn rune
+
+	// winner is the mark of the player with K in a row, or 0 if there is
+	// none yet, and free the number of empty cells.
+	winner rune
+	free   int
+}
+
+// New returns the empty board of the given size, with X to move.
+func New(width, height, k int) State {
+	s := State{
+		Width:  width,
+		Height: height,
+		K:      k,
+		Board:  make([]rune, width*height),
+		Turn:   X,
+		free:   width * height,
+	}
+	for i := range s.Board {
+		s.Board[i] = Empty
+	}
+
+	return s
+}
+
+// Classic returns the empty 3x3 board, with X to move.
+func Classic() State {
+	return New(3, 3, 3)
+}
+
+// IsClassic tells whether s is played on the 3x3 board.
+func (s State) IsClassic() bool {
+	return s.Width == 3 && s.Height == 3 && s.K == 3
+}
+
+// Clone returns a copy of s that doesn't share its board.
+func (s State) Clone() State {
+	s.Board = slices.Clone(s.Board)
+	return s
+}
+
+// Legal returns the free cells. There are none once the game is over.
+func (s State) Legal() []int {
+	if s.Outcome() != Empty {
+		return nil
+	}
+
+	cells := make([]int, 0, s.free)
+	for i, c := range s.Board {
+		if c == Empty {
+			cells = append(cells, i)
+		}
+	}
+
+	return cells
+}
+
+// Step marks cell for the player to move and passes the turn to the other
+// player.
+func (s *State) Step(cell int) error {
+	if s.Outcome() != Empty {
+		return errors.New("tictactoe: the game is over")
+	}
+	if cell < 0 || cell >= len(s.Board) {
+		return fmt.Errorf("tictactoe: no cell %d", cell+1)
+	}
+	if s.Board[cell] != Empty {
+		return fmt.Errorf("tictactoe: cell %d is taken", cell+1)
+	}
+
+	s.Board[cell] = s.Turn
+	s.free--
+	if s.Wins(cell, s.Turn) {
+		s.winner = s.Turn
+	}
+
+	s.Turn = Other(s.Turn)
+	return nil
+}
+
+// Wins tells whether the player with mark p has K in a row through cell,
+// counting cell as theirs even if it is still free.
+func (s State) Wins(cell int, p rune) bool {
+	x, y := cell%s.Width, cell/s.Width
+
+	for _, d := range directions {
+		// Count the marks on both sides of the cell.
+		run := 1
+		for _, sign := range [...]int{1, -1} {
+			for i := 1; i < s.K; i++ {
+				cx, cy := x+sign*i*d[0], y+sign*i*d[1]
+				if cx < 0 || cx >= s.Width || cy < 0 || cy >= s.Height || s.Board[cy*s.Width+cx] != p {
+					break
+				}
+				run++
+			}
+		}
+
+		if run >= s.K {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Other returns the mark of the opponent of the player with mark p.
+func Other(p rune) rune {
+	if p == X {
+		return O
+	}
+
+	return X
+}
+
+// Outcome returns the mark of the player with K in a row, Tie once the board
+// is full without one, or Empty while the game goes on.
+func (s State) Outcome() rune {
+	switch {
+	case s.winner != 0:
+		return s.winner
+	case s.free == 0:
+		return Tie
+	}
+
+	return Empty
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// play marks the given cells of the classic board in turn, starting with X.
+func play(t *testing.T, cells ...int) State {
+	t.Helper()
+
+	return playOn(t, Classic(), cells...)
+}
+
+// playOn marks the given cells of s in turn.
+func playOn(t *testing.T, s State, cells ...int) State {
+	t.Helper()
+
+	for _, cell := range cells {
+		if err := s.Step(cell); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func TestOutcome(t *testing.T) {
+	tests := []struct {
+		name  string
+		cells []int
+		want  rune
+	}{
+		{"empty", nil, Empty},
+		{"row", []int{0, 3, 1, 4, 2}, X},
+		{"column", []int{0, 1, 3, 4, 8, 7}, O},
+		{"diagonal", []int{2, 0, 4, 1, 6}, X},
+		{"tie", []int{0, 1, 2, 4, 3, 5, 7, 6, 8}, Tie},
+	}
+
+	for _, tt := range tests {
+		if got := play(t, tt.cells...).Outcome(); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestStep(t *testing.T) {
+	s := play(t, 4)
+	if s.Board[4] != X || s.Turn != O {
+		t.Errorf("expected x in the middle and o to move, got %q and %q", s.Board, s.Turn)
+	}
+	if err := s.Step(4); err == nil {
+		t.Error("expected a taken cell to be refused")
+	}
+	if err := s.Step(9); err == nil {
+		t.Error("expected a cell off the board to be refused")
+	}
+	if want := []int{0, 1, 2, 3, 5, 6, 7, 8}; !reflect.DeepEqual(s.Legal(), want) {
+		t.Errorf("expected the legal cells %v, got %v", want, s.Legal())
+	}
+
+	s = play(t, 0, 3, 1, 4, 2)
+	if s.Legal() != nil || s.Step(8) == nil {
+		t.Error("expected no move after the game is won")
+	}
+}
+
+func TestMNK(t *testing.T) {
+	// On a 7x6 board with four in a row, the cells are numbered 0 to 6 on
+	// the first row, 7 to 13 on the second and so on.
+	tests := []struct {
+		name  string
+		cells []int
+		want  rune
+	}{
+		{"three in a row", []int{0, 7, 1, 8, 2}, Empty},
+		{"row", []int{0, 7, 1, 8, 2, 9, 3}, X},
+		{"column", []int{0, 1, 7, 2, 14, 3, 21}, X},
+		{"diagonal", []int{6, 0, 12, 1, 18, 2, 24}, X},
+		{"anti-diagonal", []int{13, 3, 2, 11, 4, 19, 5, 27}, O},
+		{"row across the edge", []int{5, 10, 6, 11, 7, 12, 8}, Empty},
+	}
+
+	for _, tt := range tests {
+		if got := playOn(t, New(7, 6, 4), tt.cells...).Outcome(); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+
+	s := New(2, 2, 3)
+	for _, cell := range []int{0, 1, 2, 3} {
+		s.Step(cell)
+	}
+	if s.Outcome() != Tie {
+		t.Errorf("expected a tie on the full board, got %q", s.Outcome())
+	}
+}
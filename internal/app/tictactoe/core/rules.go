@@ -0,0 +1,225 @@
+package core
+
+import (
+	"math/rand/v2"
+	"slices"
+)
+
+// smallBoard is the number of cells of the largest board on which the
+// computer considers every free cell. On larger ones it only considers the
+// cells near the marks, where the game is played.
+const smallBoard = 25
+
+// Rules describes tictactoe to the computer players of package ai, with the
+// cells as moves. The moves it lists are only the ones worth considering:
+// the player to move takes a win when there is one and otherwise blocks the
+// opponent's, which saves the searches from finding it out.
+type Rules struct{}
+
+func (Rules) Legal(s State) []int {
+	if s.Outcome() != Empty {
+		return nil
+	}
+
+	if cells := s.winning(s.Turn); len(cells) > 0 {
+		return cells[:1]
+	}
+	if cells := s.winning(Other(s.Turn)); len(cells) > 0 {
+		return cells
+	}
+
+	if len(s.Board) <= smallBoard {
+		return s.Legal()
+	}
+
+	return s.near(2)
+}
+
+func (Rules) Play(s State, cell int) State {
+	s = s.Clone()
+	s.Step(cell)
+	return s
+}
+
+func (Rules) Result(s State) (bool, float64) {
+	switch s.Outcome() {
+	case Empty:
+		return false, 0
+	case Tie:
+		return true, 0
+	case s.Turn:
+		return true, 1
+	default:
+		return true, -1
+	}
+}
+
+// rolloutMoves is the number of moves after which a random game counts as a
+// draw, so that the games on large boards don't take long to play out.
+const rolloutMoves = 60
+
+// Rollout plays s on a copy of its board until the end or rolloutMoves moves.
+// Each player takes a win when there is one and blocks the opponent's, and
+// otherwise plays next to a mark on large boards, or anywhere on small ones.
+func (r Rules) Rollout(s State, rng *rand.Rand) float64 {
+	turn := s.Turn
+	s = s.Clone()
+
+	// cells are the cells to pick from, some of which may have been marked
+	// since they were added.
+	var cells []int
+	added := make([]bool, len(s.Board))
+	if len(s.Board) <= smallBoard {
+		cells = s.Legal()
+	} else {
+		cells = s.near(1)
+	}
+	for _, c := range cells {
+		added[c] = true
+	}
+
+	// last are the cells last marked by each player, -1 before they have
+	// played. Only the cells in line with them can have become winning
+	// cells since their previous move.
+	last := map[rune]int{X: -1, O: -1}
+
+	for range rolloutMoves {
+		if s.Outcome() != Empty {
+			break
+		}
+
+		cell := s.threat(s.Turn, last[s.Turn])
+		if cell < 0 {
+			cell = s.threat(Other(s.Turn), last[Other(s.Turn)])
+		}
+		for cell < 0 {
+			i := rng.IntN(len(cells))
+			if c := cells[i]; s.Board[c] == Empty {
+				cell = c
+			} else {
+				cells[i] = cells[len(cells)-1]
+				cells = cells[:len(cells)-1]
+			}
+		}
+
+		last[s.Turn] = cell
+		s.Step(cell)
+
+		// The free cells around the new mark can be picked from now.
+		x, y := cell%s.Width, cell/s.Width
+		for cy := max(0, y-1); cy <= min(s.Height-1, y+1); cy++ {
+			for cx := max(0, x-1); cx <= min(s.Width-1, x+1); cx++ {
+				if c := cy*s.Width + cx; !added[c] && s.Board[c] == Empty {
+					cells = append(cells, c)
+					added[c] = true
+				}
+			}
+		}
+	}
+
+	over, value := r.Result(s)
+	if !over {
+		return 0
+	}
+	if s.Turn != turn {
+		value = -value
+	}
+	return value
+}
+
+// near returns the free cells at most dist cells away from a mark, or the
+// middle cell of the empty board.
+func (s State) near(dist int) []int {
+	var cells []int
+	for i, c := range s.Board {
+		if c != Empty {
+			continue
+		}
+
+		x, y := i%s.Width, i/s.Width
+	search:
+		for cy := max(0, y-dist); cy <= min(s.Height-1, y+dist); cy++ {
+			for cx := max(0, x-dist); cx <= min(s.Width-1, x+dist); cx++ {
+				if s.Board[cy*s.Width+cx] != Empty {
+					cells = append(cells, i)
+					break search
+				}
+			}
+		}
+	}
+
+	if len(cells) == 0 {
+		return []int{s.Height/2*s.Width + s.Width/2}
+	}
+
+	return cells
+}
+
+// winning returns the free cells completing K in a row for the player with
+// mark p.
+func (s State) winning(p rune) []int {
+	var cells []int
+	for i, c := range s.Board {
+		if c != p {
+			continue
+		}
+
+		for _, end := range s.ends(i) {
+			if s.Wins(end, p) && !slices.Contains(cells, end) {
+				cells = append(cells, end)
+			}
+		}
+	}
+
+	return cells
+}
+
+// threat returns a free cell completing K in a row for the player with mark
+// p, at either end of the rows through cell if it is set, or -1 if there is
+// none.
+func (s State) threat(p rune, cell int) int {
+	if cell < 0 {
+		if cells := s.winning(p); len(cells) > 0 {
+			return cells[0]
+		}
+
+		return -1
+	}
+
+	for _, end := range s.ends(cell) {
+		if s.Wins(end, p) {
+			return end
+		}
+	}
+
+	return -1
+}
+
+// ends returns the free cells at either end of the rows of marks through
+// cell. A cell can only complete a row it is at the end of.
+func (s State) ends(cell int) []int {
+	p := s.Board[cell]
+	x, y := cell%s.Width, cell/s.Width
+
+	cells := make([]int, 0, 2*len(directions))
+	for _, d := range directions {
+		for _, sign := range [...]int{1, -1} {
+			cx, cy := x, y
+			for {
+				cx, cy = cx+sign*d[0], cy+sign*d[1]
+				if cx < 0 || cx >= s.Width || cy < 0 || cy >= s.Height {
+					break
+				}
+
+				if c := cy*s.Width + cx; s.Board[c] != p {
+					if s.Board[c] == Empty {
+						cells = append(cells, c)
+					}
+					break
+				}
+			}
+		}
+	}
+
+	return cells
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/ai"
+)
+
+// board plays the marks of the rows of x and o, or dots for free cells, in
+// turn, starting with the first x. There must be as many of each, so that X
+// is to move.
+func board(rows ...string) State {
+	var xs, os []int
+	for i, c := range strings.Join(rows, "") {
+		switch c {
+		case X:
+			xs = append(xs, i)
+		case O:
+			os = append(os, i)
+		}
+	}
+
+	s := New(len(rows[0]), len(rows), 3)
+	for i := range xs {
+		s.Step(xs[i])
+		s.Step(os[i])
+	}
+	return s
+}
+
+func TestRulesWin(t *testing.T) {
+	tests := []struct {
+		name  string
+		state State
+		want  int
+	}{
+		{"first row", board("xx.", "o.o", "..."), 2},
+		{"first column", board("x..", "xo.", ".o."), 6},
+		{"second column", board(".x.", ".xo", "..o"), 7},
+		{"diagonal", board("xo.", ".xo", "..."), 8},
+		{"anti-diagonal", board(".ox", ".xo", "..."), 6},
+		{"middle row", board("...", "x.x", "oo."), 4},
+		{"last row", board("...", "oo.", "xx."), 8},
+		{"last column", board("..x", "o.x", "o.."), 8},
+	}
+
+	ais := map[string]ai.AI[State, int]{
+		"minimax": ai.NewMinimax[State, int](Rules{}, 9),
+		"mcts":    ai.NewMCTS[State, int](Rules{}, ai.MCTSOptions{Iterations: 100}, rand.New(rand.NewPCG(1, 2))),
+	}
+
+	for name, player := range ais {
+		for _, tt := range tests {
+			if got := player.Solve(tt.state); got != tt.want {
+				t.Errorf("%s, %s: expected cell %d, got %d", name, tt.name, tt.want, got)
+			}
+		}
+	}
+}
+
+func TestRulesPerfectPlayTies(t *testing.T) {
+	player := ai.NewMinimax[State, int](Rules{}, 9)
+	s := Classic()
+	for s.Outcome() == Empty {
+		s.Step(player.Solve(s))
+	}
+
+	if s.Outcome() != Tie {
+		t.Errorf("expected a tie, got %q", s.Outcome())
+	}
+}
+
+func TestRulesGomoku(t *testing.T) {
+	// X has four in a row on the 15x15 board, from the fourth to the
+	// seventh cell of the eighth row, and O blocks the left end.
+	s := New(15, 15, 5)
+	for _, cell := range []int{7*15 + 3, 7*15 + 2, 7*15 + 4, 0, 7*15 + 5, 14, 7*15 + 6, 9*15 + 6} {
+		s.Step(cell)
+	}
+
+	player := ai.NewMCTS[State, int](Rules{}, ai.MCTSOptions{Iterations: 200}, rand.New(rand.NewPCG(1, 2)))
+	if got := player.Solve(s); got != 7*15+7 {
+		t.Errorf("expected x to complete five in a row, got cell %d", got)
+	}
+
+	// With O to move, O blocks the other end.
+	s.Turn = O
+	if got := player.Solve(s); got != 7*15+7 {
+		t.Errorf("expected o to block x's four, got cell %d", got)
+	}
+}
+
+func TestRulesLegalOnLargeBoards(t *testing.T) {
+	s := New(15, 15, 5)
+	if legal := (Rules{}).Legal(s); len(legal) != 1 || legal[0] != 7*15+7 {
+		t.Errorf("expected the middle cell on the empty board, got %v", legal)
+	}
+
+	s.Step(0)
+	if legal := (Rules{}).Legal(s); len(legal) != 8 {
+		t.Errorf("expected the 8 cells near the corner, got %v", legal)
+	}
+}
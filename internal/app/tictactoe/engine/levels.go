@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"flag"
+	"fmt"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/ai"
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/core"
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/grid"
+	"github.com/Kaamkiya/gg/internal/game"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// levels are the strengths of the computer, from the weakest.
+var levels = []string{"random", "weak", "strong", "perfect"}
+
+// weakIterations is the number of games the weak computer plays to pick each
+// move, few enough for it to miss some threats. It isn't drawn from the seed,
+// so the weak computer is as strong in every match and doesn't change the
+// numbers the rest of the round draws.
+const weakIterations = 60
+
+// newAI returns the computer player of the given level for a match. The
+// strong one searches several trees at once, or as many as there are CPUs for
+// think if it is set.
+func newAI(level string, think time.Duration, opts game.Options) ai.AI[core.State, int] {
+	switch level {
+	case "random":
+		return ai.NewRandom[core.State, int](anyCell{}, opts.Rand)
+	case "weak":
+		return ai.NewMCTS[core.State, int](core.Rules{}, ai.MCTSOptions{Iterations: weakIterations}, opts.Rand)
+	case "strong":
+		if think > 0 {
+			return ai.NewMCTS[core.State, int](core.Rules{}, ai.MCTSOptions{Budget: think, Trees: runtime.NumCPU()}, opts.Rand)
+		}
+		return ai.NewMCTS[core.State, int](core.Rules{}, ai.MCTSOptions{Iterations: 500, Trees: 8}, opts.Rand)
+	}
+
+	// Perfect play searches the nine cells of the classic board to the end.
+	return ai.NewMinimax[core.State, int](core.Rules{}, 9)
+}
+
+// anyCell lets the random computer play in any free cell, rather than only
+// in the ones the rules deem worth it.
+type anyCell struct {
+	core.Rules
+}
+
+func (anyCell) Legal(s core.State) []int {
+	return s.Legal()
+}
+
+// levelsFor returns the levels of the computer that can play on the board of
+// s. Only the classic board is small enough to be searched to the end.
+func levelsFor(s core.State) []string {
+	if s.IsClassic() {
+		return levels
+	}
+
+	return slices.DeleteFunc(slices.Clone(levels), func(level string) bool {
+		return level == "perfect"
+	})
+}
+
+// Flags defines the flags choosing the board and the computer's level.
+func Flags(fs *flag.FlagSet) func(game.Options) (tea.Model, error) {
+	board := grid.Flags(fs)
+	level := game.LevelFlag(fs, levels)
+	think := fs.Duration("think", 0, "how long the strong computer thinks about each move, e.g. 500ms (a fixed number of games if not set)")
+
+	return func(opts game.Options) (tea.Model, error) {
+		s, err := board()
+		if err != nil {
+			return nil, err
+		}
+
+		l, err := level()
+		switch {
+		case err != nil:
+			return nil, err
+		case *think < 0:
+			return nil, fmt.Errorf("-think can't be negative")
+		case *think > 0 && l != "strong":
+			return nil, fmt.Errorf("-think only applies to the strong level")
+		case l == "":
+			return newSetup(s, opts), nil
+		case l == "perfect" && !s.IsClassic():
+			return nil, fmt.Errorf("the perfect level only plays on the 3x3 board")
+		}
+
+		return newGame(s, l, *think, opts), nil
+	}
+}
+
+// GetModel asks for the level of the computer, then starts playing on the
+// classic board.
+func GetModel(opts game.Options) tea.Model {
+	return newSetup(core.Classic(), opts)
+}
+
+// newSetup asks for the level of the computer among those that can play on
+// board before the first match.
+func newSetup(board core.State, opts game.Options) tea.Model {
+	return game.PickLevel(levelsFor(board), func(level string) tea.Model {
+		return newGame(board, level, 0, opts)
+	})
+}
@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"flag"
+	"slices"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/ai"
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/core"
+	"github.com/Kaamkiya/gg/internal/game"
+)
+
+// match plays a match between the computers of the given levels, x moving
+// first, and returns the cells played.
+func match(x, o string, seed uint64) ([]int, rune) {
+	xOpts, oOpts := game.Seeded(seed), game.Seeded(seed+1)
+	players := map[rune]ai.AI[core.State, int]{
+		core.X: newAI(x, 0, xOpts),
+		core.O: newAI(o, 0, oOpts),
+	}
+
+	var cells []int
+	s := core.Classic()
+	for s.Outcome() == core.Empty {
+		cell := players[s.Turn].Solve(s)
+		s.Step(cell)
+		cells = append(cells, cell)
+	}
+
+	return cells, s.Outcome()
+}
+
+func TestLevelsAreDeterministic(t *testing.T) {
+	for _, level := range levels {
+		first, _ := match(level, level, 1)
+		for range 3 {
+			if cells, _ := match(level, level, 1); !slices.Equal(cells, first) {
+				t.Errorf("%s: expected the cells %v again, got %v", level, first, cells)
+			}
+		}
+	}
+}
+
+func TestPerfectNeverLoses(t *testing.T) {
+	for _, level := range levels {
+		for seed := range uint64(5) {
+			if _, winner := match(level, "perfect", seed); winner == core.X {
+				t.Errorf("seed %d: perfect lost to %s playing first", seed, level)
+			}
+			if _, winner := match("perfect", level, seed); winner == core.O {
+				t.Errorf("seed %d: perfect lost to %s playing second", seed, level)
+			}
+		}
+	}
+}
+
+func TestStrongBeatsRandom(t *testing.T) {
+	wins := 0
+	for seed := range uint64(10) {
+		if _, winner := match("strong", "random", seed); winner == core.X {
+			wins++
+		}
+	}
+
+	if wins < 7 {
+		t.Errorf("expected strong to beat random most of the time, won %d of 10", wins)
+	}
+}
+
+func TestFlags(t *testing.T) {
+	tests := []struct {
+		args []string
+		ok   bool
+	}{
+		{nil, true},
+		{[]string{"-level", "perfect"}, true},
+		{[]string{"-width", "15", "-height", "15", "-level", "strong"}, true},
+		{[]string{"-width", "15", "-height", "15", "-level", "perfect"}, false},
+		{[]string{"-width", "9", "-height", "9", "-k", "10"}, false},
+		{[]string{"-width", "2"}, false},
+		{[]string{"-level", "weak", "-think", "1s"}, false},
+		{[]string{"-level", "unbeatable"}, false},
+	}
+
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("tictactoe-ai", flag.ContinueOnError)
+		build := Flags(fs)
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := build(game.Seeded(1)); (err == nil) != tt.ok {
+			t.Errorf("%v: expected success to be %v, got %v", tt.args, tt.ok, err)
+		}
+	}
+}
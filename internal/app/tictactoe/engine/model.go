@@ -1,66 +1,85 @@
+// Package engine plays tictactoe against the computer.
 package engine
 
 import (
 	"fmt"
-	"log"
-	"math/rand/v2"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/Kaamkiya/gg/internal/ai"
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/core"
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/grid"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/theme"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// CellPrefix starts the names of the actions playing in a cell, which end with
+// the number of the cell.
+const CellPrefix = "cell-"
+
+// aiDelay is how long the computer waits before playing, so that its move
+// doesn't appear at the same time as the player's.
+const aiDelay = 200 * time.Millisecond
+
 type Game struct {
-	board    *Board
-	engine   *Engine
-	turn     Player
-	winner   Player
+	opts  game.Options
+	keys  game.KeyMap
+	state core.State
+	ai    ai.AI[core.State, int]
+
+	// cursor selects the cells of boards larger than 3x3.
+	cursor grid.Cursor
+
+	// level is the strength of the computer, and think how long it thinks
+	// at the strong level, if it isn't limited to a number of games.
+	level string
+	think time.Duration
+
+	// human is the mark of the player. Whoever starts a match plays X, and
+	// the player and the computer take turns starting.
+	human rune
+
+	// history holds the positions before the player's moves, so that undoing
+	// takes back the computer's reply along with the move.
+	history game.History[core.State]
+
 	gameover bool
 	round    int
 	scoreP1  int
 	scoreP2  int
 	colors   map[string]lipgloss.Style
+	styles   grid.Styles
 }
 
-const (
-	size   = 3
-	yellow = "#FF9E3B"
-	dark   = "#3C3A32"
-	gray   = "#717C7C"
-	light  = "#DCD7BA"
-	red    = "#E63D3D"
-	green  = "#98BB6C"
-	blue   = "#7E9CD8"
-)
-
-func GetModel() tea.Model {
-	board := NewBoard(size)
-	engine := NewEngine(100)
-
-	defaultStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#f9f6f2"))
-	c := func(s string) lipgloss.Color {
-		return lipgloss.Color(s)
-	}
+// newGame starts the first match against the computer on the board of s.
+func newGame(s core.State, level string, think time.Duration, opts game.Options) Game {
+	t := theme.Current()
 
 	return Game{
-		board:    board,
-		engine:   engine,
-		turn:     P1,
-		winner:   0,
-		round:    1,
-		scoreP1:  0,
-		scoreP2:  0,
-		gameover: false,
+		opts:    opts,
+		keys:    game.Keys("tictactoe-ai"),
+		state:   s,
+		ai:      newAI(level, think, opts),
+		cursor:  grid.Center(s),
+		level:   level,
+		think:   think,
+		human:   core.X,
+		history: game.NewHistory[core.State](game.UnlimitedUndo),
+		round:   1,
 		colors: map[string]lipgloss.Style{
-			"board":  defaultStyle.Background(c(dark)),
-			"text":   defaultStyle.Background(c(dark)).Foreground(c(light)),
-			"line":   defaultStyle.Background(c(dark)).Foreground(c(gray)),
-			"p1":     defaultStyle.Background(c(dark)).Foreground(c(yellow)),
-			"p2":     defaultStyle.Background(c(dark)).Foreground(c(red)),
-			"hi":     defaultStyle.Foreground(c(green)),
-			"status": defaultStyle.Foreground(c(blue)),
+			"board":  t.Board,
+			"text":   t.Text.Inherit(t.Board),
+			"line":   t.Muted.Inherit(t.Board),
+			"p1":     t.Players[0].Inherit(t.Board),
+			"p2":     t.Players[1].Inherit(t.Board),
+			"hi":     t.Good,
+			"status": t.Accent,
 		},
+		styles: grid.Styles{X: t.Players[0], O: t.Players[1], Free: t.Muted, Cursor: t.Cursor},
 	}
 }
 
@@ -68,172 +87,147 @@ func (g Game) Init() tea.Cmd {
 	return nil
 }
 
-type gameOverMsg struct{ winner Player }
-type nextTurnMsg struct{}
-type aiTurnMsg struct{}
+// aiMoveMsg carries the cell the computer plays in.
+type aiMoveMsg struct{ cell int }
 
 func (g Game) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
-	case aiTurnMsg:
-		time.Sleep(time.Millisecond * 200)
-		return g, aiMoveCmd(&g)
-
-	case nextTurnMsg:
-		g.turn = g.engine.GetOpponent(g.turn)
-		if g.turn == P2 {
-			return g, func() tea.Msg {
-				return aiTurnMsg{}
-			}
-		}
-		return g, nil
-
-	case gameOverMsg:
-		g.winner = msg.winner
-		g.turn = g.engine.GetOpponent(g.turn)
-		g.gameover = true
-		if g.winner == P1 {
-			g.scoreP1 += 1
-		} else if g.winner == P2 {
-			g.scoreP2 += 1
+	case aiMoveMsg:
+		if g.gameover || g.state.Turn == g.human {
+			return g, nil
 		}
-		return g, nil
+		return g.play(msg.cell)
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		action := g.keys.Action(msg)
+		switch action {
+		case "quit":
 			return g, tea.Quit
 
-		case "n", "N":
+		case "next-match":
+			if !g.gameover {
+				return g, nil
+			}
 			g.nextMatch()
-			if g.turn == P2 {
-				return g, aiMoveCmd(&g)
+			if g.state.Turn != g.human {
+				return g, g.aiMove()
 			}
 			return g, nil
+		}
 
-		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
-			// There shouldn't be an error, because this is only called for integers
-			index, _ := strconv.Atoi(msg.String())
-			index -= 1
-			cell, err := g.board.GetCell(index)
-			if err != nil {
-				log.Fatal(err)
-			}
+		if g.gameover || g.state.Turn != g.human {
+			return g, nil
+		}
 
-			if cell == EMPTY {
-				g.engine.PlayMove(g.board, P1, index)
-
-				isover, win := g.engine.CheckGameOver(g.board, index)
-
-				if isover {
-					if win > 0 {
-						g.winner = g.turn
-						// Update score
-						if g.winner == P1 {
-							g.scoreP1 += 1
-						} else if g.winner == P2 {
-							g.scoreP2 += 1
-						}
-					} else {
-						g.winner = 0
-					}
-
-					g.gameover = true
-					g.turn = g.engine.GetOpponent(g.turn)
-					return g, nil
-				}
-
-				return g, func() tea.Msg {
-					return nextTurnMsg{}
-				}
-			}
+		switch action {
+		case "undo":
+			g.state, _ = g.history.Undo(g.state)
+			return g, nil
+		case "redo":
+			g.state, _ = g.history.Redo(g.state)
+			return g, nil
+		}
+
+		// The cells of the classic board are marked by number, the others
+		// with the cursor.
+		if n, ok := strings.CutPrefix(action, CellPrefix); ok && g.state.IsClassic() {
+			// There shouldn't be an error, because the actions are named after the cells' numbers
+			index, _ := strconv.Atoi(n)
+			return g.move(index - 1)
+		} else if action == "mark" && !g.state.IsClassic() {
+			return g.move(g.cursor.Cell(g.state))
+		} else if !g.state.IsClassic() {
+			g.cursor, _ = g.cursor.Move(action, g.state)
 		}
 	}
 
 	return g, nil
 }
 
-// Handle AI turn
-func aiMoveCmd(g *Game) tea.Cmd {
-	return func() tea.Msg {
-		rollout := g.board.Copy()
-		move := g.engine.ai.Solve(rollout)
+// move marks cell for the player if it is free, keeping the position before it
+// in the history.
+func (g Game) move(cell int) (tea.Model, tea.Cmd) {
+	if g.state.Board[cell] != core.Empty {
+		return g, nil
+	}
 
-		g.engine.PlayMove(g.board, P2, move)
+	g.history.Record(g.state.Clone())
+	return g.play(cell)
+}
 
-		isover, win := g.engine.CheckGameOver(g.board, move)
-		if isover {
-			if win > 0 {
-				return gameOverMsg{winner: P2}
-			}
+// play marks cell for the player to move, then lets the computer move or ends
+// the match.
+func (g Game) play(cell int) (tea.Model, tea.Cmd) {
+	if err := g.state.Step(cell); err != nil {
+		return g, nil
+	}
 
-			return gameOverMsg{winner: 0}
+	switch g.state.Outcome() {
+	case core.Empty:
+		if g.state.Turn != g.human {
+			return g, g.aiMove()
 		}
-
-		return nextTurnMsg{}
+		return g, nil
+	case g.human:
+		g.scoreP1++
+	case core.Other(g.human):
+		g.scoreP2++
 	}
-}
 
-func (g *Game) nextMatch() {
-	g.board = NewBoard(size)
-	g.gameover = false
-	g.winner = 0
-	g.round += 1
-
-	randLvl := rand.IntN(50) + 50
-	g.engine = NewEngine(randLvl)
+	g.gameover = true
+	return g, g.result()
 }
 
-func printCell(board *Board, index int) string {
-	cell, err := board.GetCell(index)
-	if err != nil {
-		panic(err)
+// aiMove lets the computer pick its move in the current position.
+func (g Game) aiMove() tea.Cmd {
+	state, player := g.state.Clone(), g.ai
+	return func() tea.Msg {
+		time.Sleep(aiDelay)
+		return aiMoveMsg{player.Solve(state)}
 	}
+}
 
-	sign := printPlayer(cell)
-
-	if sign == "" {
-		return fmt.Sprintf("%d", index+1)
+// result reports the outcome of the match for the player.
+func (g Game) result() tea.Cmd {
+	outcome := game.Draw
+	var cmds []tea.Cmd
+	switch g.state.Outcome() {
+	case g.human:
+		outcome = game.Win
+		cmds = append(cmds, game.Publish("win", 0))
+	case core.Other(g.human):
+		outcome = game.Loss
 	}
 
-	return sign
+	return tea.Batch(append(cmds, game.Over(game.Result{
+		Outcome: outcome,
+		Summary: fmt.Sprintf("%d-%d after %d matches", g.scoreP1, g.scoreP2, g.round),
+		Undos:   g.history.Undos(),
+	}))...)
 }
 
-func printPlayer(cell int) string {
-	if cell == P1 {
-		return "O"
-	} else if cell == P2 {
-		return "X"
-	}
+func (g *Game) nextMatch() {
+	g.state = core.New(g.state.Width, g.state.Height, g.state.K)
+	g.human = core.Other(g.human)
+	g.gameover = false
+	g.round += 1
+	g.history.Clear()
 
-	return ""
+	g.ai = newAI(g.level, g.think, g.opts)
 }
 
-func (g Game) View() string {
-	renderCell := func(index int) string {
-		cell, _ := g.board.GetCell(index)
-		var style lipgloss.Style
-		content := ""
-
-		switch cell {
-		case P1:
-			style = g.colors["p1"]
-			content = "O"
-		case P2:
-			style = g.colors["p2"]
-			content = "X"
-		default: // Empty cell, show index
-			style = g.colors["text"]
-			content = strconv.Itoa(index + 1)
-		}
+// printPlayer returns the mark to show for a player.
+func printPlayer(mark rune) string {
+	return strings.ToUpper(string(mark))
+}
 
-		return style.Render(content)
-	}
+func (g Game) View() string {
 	winner := "\n"
 	if g.gameover {
 		winner = ""
-		if g.winner != 0 {
+		if w := g.state.Outcome(); w != core.Tie {
 			winner += g.colors["hi"].Render(" Winner: ")
-			winner += g.colors["hi"].Render(printPlayer(g.winner))
+			winner += g.colors["hi"].Render(printPlayer(w))
 			winner += "\n"
 		} else {
 			winner += g.colors["hi"].Render("   Draw!")
@@ -241,6 +235,45 @@ func (g Game) View() string {
 		}
 	}
 
+	var board string
+	if g.state.IsClassic() {
+		board = g.drawClassic()
+	} else {
+		// The player's marks are drawn in the first player's style.
+		styles := g.styles
+		if g.human == core.O {
+			styles.X, styles.O = styles.O, styles.X
+		}
+		board = grid.Draw(g.state, g.cursor, styles)
+	}
+
+	status := g.colors["status"].Render(fmt.Sprintf("\n#%d:(W%d-L%d) %s", g.round, g.scoreP1, g.scoreP2, g.level))
+	if g.gameover {
+		status += g.colors["status"].Render(fmt.Sprintf("> %s quit - %s next match", g.keys.Keys("quit"), g.keys.Keys("next-match")))
+		status += g.colors["status"].Render(fmt.Sprintf("\nSeed: %d", g.opts.Seed))
+	} else if g.state.Turn == g.human {
+		status += g.colors["status"].Render(fmt.Sprintf("> %s's turn (you)", printPlayer(g.state.Turn)))
+	} else {
+		status += g.colors["status"].Render(fmt.Sprintf("> %s's turn", printPlayer(g.state.Turn)))
+	}
+
+	return winner + board + status
+}
+
+// drawClassic draws the 3x3 board with the numbers of the free cells.
+func (g Game) drawClassic() string {
+	renderCell := func(index int) string {
+		mark := g.state.Board[index]
+		switch mark {
+		case core.Empty: // Show the index
+			return g.colors["text"].Render(strconv.Itoa(index + 1))
+		case g.human:
+			return g.colors["p1"].Render(printPlayer(mark))
+		default:
+			return g.colors["p2"].Render(printPlayer(mark))
+		}
+	}
+
 	board := ""
 	for i := 0; i < 3; i++ {
 		board += g.colors["board"].Render(" ")
@@ -256,12 +289,5 @@ func (g Game) View() string {
 		}
 	}
 
-	status := g.colors["status"].Render(fmt.Sprintf("\n#%d:(W%d-L%d)", g.round, g.scoreP1, g.scoreP2))
-	if g.gameover {
-		status += g.colors["status"].Render("> [Q]uit - [N]ext match")
-	} else {
-		status += g.colors["status"].Render(fmt.Sprintf("> %s's turn", printPlayer(g.turn)))
-	}
-
-	return winner + board + status
+	return board
 }
@@ -0,0 +1,130 @@
+// Package grid draws the boards of tictactoe larger than 3x3, on which the
+// players select a cell with a cursor instead of typing its number.
+package grid
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/core"
+	"github.com/Kaamkiya/gg/internal/game"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Bindings are the default keys moving the cursor and marking its cell.
+var Bindings = []game.Binding{
+	{Action: "up", Keys: []string{"up", "k"}, Help: "move the cursor up"},
+	{Action: "down", Keys: []string{"down", "j"}, Help: "move the cursor down"},
+	{Action: "left", Keys: []string{"left", "h"}, Help: "move the cursor left"},
+	{Action: "right", Keys: []string{"right", "l"}, Help: "move the cursor right"},
+	{Action: "mark", Keys: []string{"enter", " "}, Help: "mark the cell under the cursor"},
+}
+
+// Cursor is the selected cell of a board.
+type Cursor struct {
+	X, Y int
+}
+
+// Center returns the cursor on the middle cell of the board of s.
+func Center(s core.State) Cursor {
+	return Cursor{s.Width / 2, s.Height / 2}
+}
+
+// Move returns the cursor moved by action, staying on the board of s, and
+// whether action is one of the cursor's.
+func (c Cursor) Move(action string, s core.State) (Cursor, bool) {
+	switch action {
+	case "up":
+		c.Y = max(c.Y-1, 0)
+	case "down":
+		c.Y = min(c.Y+1, s.Height-1)
+	case "left":
+		c.X = max(c.X-1, 0)
+	case "right":
+		c.X = min(c.X+1, s.Width-1)
+	default:
+		return c, false
+	}
+
+	return c, true
+}
+
+// Cell returns the number of the cell under the cursor on the board of s.
+func (c Cursor) Cell(s core.State) int {
+	return c.Y*s.Width + c.X
+}
+
+// Styles are the styles a board is drawn with.
+type Styles struct {
+	X, O   lipgloss.Style
+	Free   lipgloss.Style
+	Cursor lipgloss.Style
+}
+
+// Draw draws the board of s with the cursor on it.
+func Draw(s core.State, c Cursor, styles Styles) string {
+	var b strings.Builder
+	for y := range s.Height {
+		for x := range s.Width {
+			cell := s.Board[y*s.Width+x]
+
+			style := styles.Free
+			switch {
+			case x == c.X && y == c.Y:
+				style = styles.Cursor
+			case cell == core.X:
+				style = styles.X
+			case cell == core.O:
+				style = styles.O
+			}
+
+			if x > 0 {
+				b.WriteString(" ")
+			}
+			b.WriteString(style.Render(string(markOf(cell))))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// markOf returns the character drawn for cell.
+func markOf(cell rune) rune {
+	if cell == core.Empty {
+		return '·'
+	}
+
+	return cell
+}
+
+// The limits of the size of a board, the largest fitting in a terminal of 80
+// columns with room to spare.
+const (
+	minSize = 3
+	maxSize = 19
+)
+
+// Flags defines the flags setting the size of the board and the number of
+// marks in a row that wins. The returned function returns the empty board
+// they describe once the flags have been parsed.
+func Flags(fs *flag.FlagSet) func() (core.State, error) {
+	width := fs.Int("width", minSize, "width of the board")
+	height := fs.Int("height", minSize, "height of the board")
+	k := fs.Int("k", 0, "number of marks in a row that wins (the smaller side of the board, up to 5, if not set)")
+
+	return func() (core.State, error) {
+		switch {
+		case *width < minSize || *width > maxSize || *height < minSize || *height > maxSize:
+			return core.State{}, fmt.Errorf("the board must be from %d to %d cells wide and high", minSize, maxSize)
+		case *k == 0:
+			*k = min(5, *width, *height)
+		case *k < minSize || *k > max(*width, *height):
+			return core.State{}, fmt.Errorf("-k must be from %d to %d on this board", minSize, max(*width, *height))
+		}
+
+		return core.New(*width, *height, *k), nil
+	}
+}
@@ -1,33 +1,74 @@
 package tictactoe
 
 import (
+	"flag"
 	"fmt"
 	"strconv"
+	"strings"
 
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/core"
 	"github.com/Kaamkiya/gg/internal/app/tictactoe/engine"
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/grid"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/theme"
+
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
-var winner = ' '
+// bindings returns the default keys of the players' actions.
+func bindings() []game.Binding {
+	var b []game.Binding
+	for cell := 1; cell <= 9; cell++ {
+		n := strconv.Itoa(cell)
+		b = append(b, game.Binding{Action: engine.CellPrefix + n, Keys: []string{n}, Help: "play in cell " + n})
+	}
+	b = append(b, grid.Bindings...)
+	b = append(b, game.UndoBindings...)
+	return append(b, game.Binding{Action: "quit", Keys: []string{"q"}, Help: "quit"})
+}
+
+// aiBindings returns the default keys of the player's actions against the
+// computer, which plays match after match.
+func aiBindings() []game.Binding {
+	next := game.Binding{Action: "next-match", Keys: []string{"n", "N"}, Help: "start the next match"}
+	return append(bindings(), next)
+}
 
 type model struct {
-	turn   rune
-	board  [9]rune
-	xcolor lipgloss.Style
-	ocolor lipgloss.Style
+	state   core.State
+	cursor  grid.Cursor
+	keys    game.KeyMap
+	styles  grid.Styles
+	history game.History[core.State]
 }
 
-func initialModel() tea.Model {
+func initialModel(game.Options) tea.Model {
+	return newModel(core.Classic())
+}
+
+// newModel starts a round on the board of s.
+func newModel(s core.State) model {
+	t := theme.Current()
+
 	return model{
-		turn: 'x',
-		board: [9]rune{
-			'1', '2', '3',
-			'4', '5', '6',
-			'7', '8', '9',
-		},
-		xcolor: lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")),
-		ocolor: lipgloss.NewStyle().Foreground(lipgloss.Color("#0000ff")),
+		state:   s,
+		cursor:  grid.Center(s),
+		keys:    game.Keys("tictactoe"),
+		styles:  grid.Styles{X: t.Players[0], O: t.Players[1], Free: t.Muted, Cursor: t.Cursor},
+		history: game.NewHistory[core.State](game.UnlimitedUndo),
+	}
+}
+
+func flags(fs *flag.FlagSet) func(game.Options) (tea.Model, error) {
+	board := grid.Flags(fs)
+
+	return func(game.Options) (tea.Model, error) {
+		s, err := board()
+		if err != nil {
+			return nil, err
+		}
+
+		return newModel(s), nil
 	}
 }
 
@@ -38,103 +79,123 @@ func (m model) Init() tea.Cmd {
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		action := m.keys.Action(msg)
+		if action == "quit" {
 			return m, tea.Quit
-		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
-			// There shouldn't be an error, because this is only called for integers
-			position, _ := strconv.Atoi(msg.String())
-
-			if m.board[position-1] != 'x' && m.board[position-1] != 'o' {
-				m.board[position-1] = m.turn
-
-				if m.turn == 'x' {
-					m.turn = 'o'
-				} else {
-					m.turn = 'x'
-				}
-			}
-
-			if m.CheckForWin() != ' ' {
-				winner = m.CheckForWin()
-				return m, tea.Quit
-			}
 		}
-	}
 
-	return m, nil
-}
-
-func (m model) View() string {
-	s := fmt.Sprintf("%c | %c | %c\n", m.board[0], m.board[1], m.board[2])
-	s += "---------\n"
-	s += fmt.Sprintf("%c | %c | %c\n", m.board[3], m.board[4], m.board[5])
-	s += "---------\n"
-	s += fmt.Sprintf("%c | %c | %c\n", m.board[6], m.board[7], m.board[8])
-
-	s += fmt.Sprintf("\n\n%c's turn", m.turn)
+		// The board can't be changed once the round is over.
+		if m.state.Outcome() != core.Empty {
+			break
+		}
 
-	return s
-}
+		switch action {
+		case "undo":
+			m.state, _ = m.history.Undo(m.state)
+		case "redo":
+			m.state, _ = m.history.Redo(m.state)
+		}
 
-func (m model) CheckForWin() rune {
-	// Check over each row to see if someone won.
-	for i := 0; i < 9; i += 3 {
-		o := 0
-		x := 0
-		for _, c := range m.board[i : i+3] {
-			if c == 'x' {
-				x++
-			} else if c == 'o' {
-				o++
-			}
+		// The cells of the classic board are marked by number, the others
+		// with the cursor.
+		cell := -1
+		if n, ok := strings.CutPrefix(action, engine.CellPrefix); ok && m.state.IsClassic() {
+			// There shouldn't be an error, because the actions are named after the cells' numbers
+			position, _ := strconv.Atoi(n)
+			cell = position - 1
+		} else if action == "mark" && !m.state.IsClassic() {
+			cell = m.cursor.Cell(m.state)
+		} else if !m.state.IsClassic() {
+			m.cursor, _ = m.cursor.Move(action, m.state)
 		}
 
-		if o == 3 {
-			return 'o'
-		} else if x == 3 {
-			return 'x'
+		if cell < 0 {
+			break
 		}
-	}
 
-	// Check the columns
-	for i := 0; i < 3; i++ {
-		if m.board[i] == 'x' && m.board[i+3] == 'x' && m.board[i+6] == 'x' {
-			return 'x'
+		// Taken cells are refused. The board is copied for the history,
+		// since marking a cell changes it in place.
+		before := m.state.Clone()
+		if err := m.state.Step(cell); err != nil {
+			break
 		}
-		if m.board[i] == 'o' && m.board[i+3] == 'o' && m.board[i+6] == 'o' {
-			return 'o'
+		m.history.Record(before)
+
+		if winner := m.state.Outcome(); winner != core.Empty {
+			r := result(winner)
+			r.Undos = m.history.Undos()
+			return m, game.Over(r)
 		}
 	}
 
-	// And finally, check the diagonals.
-	if m.board[0] == 'x' && m.board[4] == 'x' && m.board[8] == 'x' ||
-		m.board[2] == 'x' && m.board[4] == 'x' && m.board[6] == 'x' {
-		return 'x'
-	}
+	return m, nil
+}
 
-	if m.board[0] == 'o' && m.board[4] == 'o' && m.board[8] == 'o' ||
-		m.board[2] == 'o' && m.board[4] == 'o' && m.board[6] == 'o' {
-		return 'o'
+// result reports the outcome of the round for x, who plays first.
+func result(winner rune) game.Result {
+	switch winner {
+	case core.X:
+		return game.Result{Outcome: game.Win, Summary: "x wins"}
+	case core.O:
+		return game.Result{Outcome: game.Loss, Summary: "o wins"}
 	}
 
-	return ' '
+	return game.Result{Outcome: game.Draw, Summary: "tie"}
 }
 
-func Run() {
-	p := tea.NewProgram(initialModel())
+func (m model) View() string {
+	if !m.state.IsClassic() {
+		return grid.Draw(m.state, m.cursor, m.styles) + m.status()
+	}
 
-	if _, err := p.Run(); err != nil {
-		panic(err)
+	// Free cells show their number.
+	var cells [9]rune
+	for i, c := range m.state.Board {
+		cells[i] = c
+		if c == core.Empty {
+			cells[i] = rune('1' + i)
+		}
 	}
 
-	fmt.Printf("%c wins\n", winner)
-}
+	s := fmt.Sprintf("%c | %c | %c\n", cells[0], cells[1], cells[2])
+	s += "---------\n"
+	s += fmt.Sprintf("%c | %c | %c\n", cells[3], cells[4], cells[5])
+	s += "---------\n"
+	s += fmt.Sprintf("%c | %c | %c\n", cells[6], cells[7], cells[8])
 
-func RunVsAi() {
-	p := tea.NewProgram(engine.GetModel())
+	return s + m.status()
+}
 
-	if _, err := p.Run(); err != nil {
-		panic(err)
+// status tells whose turn it is or how the round ended.
+func (m model) status() string {
+	switch winner := m.state.Outcome(); winner {
+	case core.Empty:
+		return fmt.Sprintf("\n\n%c's turn", m.state.Turn)
+	case core.Tie:
+		return fmt.Sprintf("\n\ntie! Press %s to quit.\n", m.keys.Keys("quit"))
+	default:
+		return fmt.Sprintf("\n\n%c wins! Press %s to quit.\n", winner, m.keys.Keys("quit"))
 	}
 }
+
+func init() {
+	game.Register(game.Game{
+		ID:          "tictactoe",
+		Name:        "tictactoe",
+		Players:     2,
+		Description: "Get k in a row, three on the classic board, before your opponent does.",
+		Keys:        bindings(),
+		New:         initialModel,
+		Flags:       flags,
+	})
+
+	game.Register(game.Game{
+		ID:          "tictactoe-ai",
+		Name:        "tictactoe (vs AI)",
+		Players:     1,
+		Description: "Get k in a row, three on the classic board, before the computer does.",
+		Keys:        aiBindings(),
+		New:         engine.GetModel,
+		Flags:       engine.Flags,
+	})
+}
@@ -0,0 +1,41 @@
+package tictactoe
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/game"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestUndoTakesBackTheComputersReply(t *testing.T) {
+	g, _ := game.Lookup("tictactoe-ai")
+	m, err := g.Parse([]string{"-level", "random", "-seed", "1"}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := m.View()
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("5")})
+	if cmd == nil {
+		t.Fatal("expected the computer to reply")
+	}
+	m, _ = m.Update(cmd())
+
+	played := m.View()
+	if !strings.Contains(played, "X") || !strings.Contains(played, "O") {
+		t.Fatalf("expected the move and the reply on the board, got\n%s", played)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if m.View() != start {
+		t.Errorf("expected the move and the reply to have been taken back, got\n%s", m.View())
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if m.View() != played {
+		t.Errorf("expected the move and the reply to have been played again, got\n%s", m.View())
+	}
+}
@@ -0,0 +1,210 @@
+// Package core holds the rules of 2048, free of any rendering, so that they
+// can be tested on their own and played by bots.
+package core
+
+import (
+	"errors"
+	"math/rand/v2"
+)
+
+const (
+	// Size is the number of rows and columns of the grid.
+	Size = 4
+
+	// Goal is the tile that wins the game.
+	Goal = 2048
+)
+
+// Move slides every tile of the grid one way.
+type Move int
+
+const (
+	Up Move = iota
+	Down
+	Left
+	Right
+)
+
+// Outcome tells whether the game is over.
+type Outcome int
+
+const (
+	Playing Outcome = iota
+
+	// Won is the outcome once the Goal tile has been reached.
+	Won
+
+	// Stuck is the outcome once no move changes the grid.
+	Stuck
+)
+
+// Grid holds the tiles by row and column, 0 for an empty square.
+type Grid [Size][Size]int
+
+// State is a position of the game. The new tiles are picked with rand, so two
+// games played with generators in the same state get the same tiles.
+type State struct {
+	Grid Grid
+	rand *rand.Rand
+}
+
+// New returns a game starting with two tiles.
+func New(r *rand.Rand) State {
+	s := Load(Grid{}, r)
+	s.addTile()
+	s.addTile()
+	return s
+}
+
+// Load returns the game with the given grid.
+func Load(g Grid, r *rand.Rand) State {
+	return State{Grid: g, rand: r}
+}
+
+// Legal returns the moves that change the grid. There are none once the game
+// is over.
+func (s State) Legal() []Move {
+	if s.Outcome() != Playing {
+		return nil
+	}
+
+	var moves []Move
+	for _, m := range []Move{Up, Down, Left, Right} {
+		if s.Grid.slide(m) != s.Grid {
+			moves = append(moves, m)
+		}
+	}
+
+	return moves
+}
+
+// Step slides the tiles, merging equal tiles that meet, and adds a tile to an
+// empty square. A move that changes nothing is refused.
+func (s *State) Step(m Move) error {
+	if s.Outcome() != Playing {
+		return errors.New("twenty48: the game is over")
+	}
+
+	slid := s.Grid.slide(m)
+	if slid == s.Grid {
+		return errors.New("twenty48: no tile can move that way")
+	}
+
+	s.Grid = slid
+	s.addTile()
+	return nil
+}
+
+// Outcome returns Won once a tile has reached Goal, Stuck once no move is
+// left, and Playing otherwise.
+func (s State) Outcome() Outcome {
+	if s.Best() >= Goal {
+		return Won
+	}
+
+	// A game with an empty square or two equal tiles side by side can
+	// go on.
+	for y := range Size {
+		for x := range Size {
+			if s.Grid[y][x] == 0 ||
+				x < Size-1 && s.Grid[y][x] == s.Grid[y][x+1] ||
+				y < Size-1 && s.Grid[y][x] == s.Grid[y+1][x] {
+				return Playing
+			}
+		}
+	}
+
+	return Stuck
+}
+
+// Best returns the highest tile of the grid.
+func (s State) Best() int {
+	best := 0
+	for _, row := range s.Grid {
+		for _, tile := range row {
+			best = max(best, tile)
+		}
+	}
+
+	return best
+}
+
+// addTile puts a 2, or a 4 one time out of ten, on an empty square.
+func (s *State) addTile() {
+	var empty []int
+	for y, row := range s.Grid {
+		for x, cell := range row {
+			if cell == 0 {
+				empty = append(empty, y*Size+x)
+			}
+		}
+	}
+
+	if len(empty) == 0 {
+		return
+	}
+
+	cell := empty[s.rand.IntN(len(empty))]
+
+	if s.rand.IntN(10) < 9 {
+		s.Grid[cell/Size][cell%Size] = 2
+	} else {
+		s.Grid[cell/Size][cell%Size] = 4
+	}
+}
+
+// slide returns the grid after the move, without a new tile.
+func (g Grid) slide(m Move) Grid {
+	/* Instead of merging the tiles in every direction, we rotate the
+	 * grid so that the move goes left, merge left and rotate back.
+	 */
+	switch m {
+	case Down:
+		return g.rotate(false).mergeLeft().rotate(true)
+	case Up:
+		return g.rotate(true).mergeLeft().rotate(false)
+	case Right:
+		return g.rotate(false).rotate(false).mergeLeft().rotate(true).rotate(true)
+	}
+
+	return g.mergeLeft()
+}
+
+func (g Grid) mergeLeft() Grid {
+	for i := range g {
+		stopMerge := 0
+		for j := 1; j < len(g[i]); j++ {
+			if g[i][j] == 0 {
+				continue
+			}
+
+			for k := j; k > stopMerge; k-- {
+				if g[i][k-1] == 0 {
+					g[i][k-1] = g[i][k]
+					g[i][k] = 0
+				} else if g[i][k-1] == g[i][k] {
+					g[i][k-1] += g[i][k]
+					g[i][k] = 0
+					stopMerge = k
+				}
+			}
+		}
+	}
+
+	return g
+}
+
+func (g Grid) rotate(counterClockwise bool) Grid {
+	var rotated Grid
+	for i, row := range g {
+		for j := range row {
+			if counterClockwise {
+				rotated[i][j] = g[j][Size-i-1]
+			} else {
+				rotated[i][j] = g[Size-j-1][i]
+			}
+		}
+	}
+
+	return rotated
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"testing"
+)
+
+func newRand() *rand.Rand {
+	return rand.New(rand.NewPCG(1, 2))
+}
+
+func TestSlide(t *testing.T) {
+	g := Grid{
+		{2, 2, 2, 2},
+		{0, 4, 0, 4},
+		{8, 4, 4, 0},
+		{2, 0, 0, 0},
+	}
+
+	tests := []struct {
+		move Move
+		want Grid
+	}{
+		{Left, Grid{{4, 4, 0, 0}, {8, 0, 0, 0}, {8, 8, 0, 0}, {2, 0, 0, 0}}},
+		{Right, Grid{{0, 0, 4, 4}, {0, 0, 0, 8}, {0, 0, 8, 8}, {0, 0, 0, 2}}},
+		{Up, Grid{{2, 2, 2, 2}, {8, 8, 4, 4}, {2, 0, 0, 0}, {0, 0, 0, 0}}},
+		{Down, Grid{{0, 0, 0, 0}, {2, 0, 0, 0}, {8, 2, 2, 2}, {2, 8, 4, 4}}},
+	}
+
+	for _, tt := range tests {
+		if got := g.slide(tt.move); got != tt.want {
+			t.Errorf("move %d: expected %v, got %v", tt.move, tt.want, got)
+		}
+	}
+}
+
+func TestStep(t *testing.T) {
+	s := Load(Grid{{2, 0, 0, 0}}, newRand())
+
+	if err := s.Step(Left); err == nil {
+		t.Error("expected a move that changes nothing to be refused")
+	}
+	if want := []Move{Down, Right}; !reflect.DeepEqual(s.Legal(), want) {
+		t.Errorf("expected the legal moves %v, got %v", want, s.Legal())
+	}
+
+	if err := s.Step(Right); err != nil {
+		t.Fatal(err)
+	}
+	tiles := 0
+	for _, row := range s.Grid {
+		for _, tile := range row {
+			if tile != 0 {
+				tiles++
+			}
+		}
+	}
+	if s.Grid[0][3] != 2 || tiles != 2 {
+		t.Errorf("expected the tile to slide and a new one to appear, got %v", s.Grid)
+	}
+}
+
+func TestOutcome(t *testing.T) {
+	stuck := Grid{
+		{2, 4, 2, 4},
+		{4, 2, 4, 2},
+		{2, 4, 2, 4},
+		{4, 2, 4, 2},
+	}
+	if got := Load(stuck, newRand()).Outcome(); got != Stuck {
+		t.Errorf("expected a full grid without merges to be stuck, got %d", got)
+	}
+
+	stuck[3][3] = 4
+	if got := Load(stuck, newRand()).Outcome(); got != Playing {
+		t.Errorf("expected a grid with a merge left to go on, got %d", got)
+	}
+
+	won := Grid{{Goal}}
+	if s := Load(won, newRand()); s.Outcome() != Won || s.Legal() != nil {
+		t.Errorf("expected the goal tile to win, got %d", s.Outcome())
+	}
+}
+
+func TestNewIsSeeded(t *testing.T) {
+	if a, b := New(newRand()), New(newRand()); a.Grid != b.Grid {
+		t.Errorf("expected the same tiles from the same seed, got %v and %v", a.Grid, b.Grid)
+	}
+}
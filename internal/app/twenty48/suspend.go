@@ -0,0 +1,51 @@
+package twenty48
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/Kaamkiya/gg/internal/app/twenty48/core"
+	"github.com/Kaamkiya/gg/internal/game"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// snapshot is the saved state of a suspended game.
+type snapshot struct {
+	Grid    core.Grid    `json:"grid"`
+	Undos   int          `json:"undos,omitempty"`
+	Options game.Options `json:"options"`
+}
+
+func (m model) Suspend() any {
+	if m.state.Outcome() != core.Playing {
+		return nil
+	}
+
+	return snapshot{Grid: m.state.Grid, Undos: m.history.Undos(), Options: m.opts}
+}
+
+func resume(data []byte) (tea.Model, error) {
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+
+	if s.Undos < 0 || s.Undos > undos {
+		return nil, errors.New("the number of undos is out of range")
+	}
+
+	for _, row := range s.Grid {
+		for _, tile := range row {
+			// Every tile is empty or a power of two from 2 on.
+			if tile < 0 || tile == 1 || tile&(tile-1) != 0 {
+				return nil, errors.New("the grid holds a tile that isn't a power of two")
+			}
+		}
+	}
+
+	m := newModel(s.Options, undos)
+	m.state = core.Load(s.Grid, s.Options.Rand)
+	m.history.Resume(s.Undos)
+	return m, nil
+}
@@ -1,48 +1,73 @@
 package twenty48
 
 import (
-	"math/rand/v2"
+	"fmt"
 	"strconv"
-	"time"
+
+	"github.com/Kaamkiya/gg/internal/app/twenty48/core"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/theme"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
+// bindings returns the default keys of the player's actions.
+func bindings() []game.Binding {
+	b := []game.Binding{
+		{Action: "up", Keys: []string{"up", "k"}, Help: "move up"},
+		{Action: "down", Keys: []string{"down", "j"}, Help: "move down"},
+		{Action: "left", Keys: []string{"left", "h"}, Help: "move left"},
+		{Action: "right", Keys: []string{"right", "l"}, Help: "move right"},
+	}
+	b = append(b, game.UndoBindings...)
+	return append(b, game.Binding{Action: "quit", Keys: []string{"q"}, Help: "quit"})
+}
+
+// undos is the number of moves a player may take back in a game. The random
+// numbers drawn aren't taken back, so a move undone and played again brings
+// up another tile. Undos are few so that the tiles can't be drawn again and
+// again until they suit the player, which keeps the leaderboard fair.
+const undos = 3
+
 type model struct {
 	// TODO: add a score counter.
-	colors map[int]lipgloss.Style
-	grid   [4][4]int
+	opts    game.Options
+	keys    game.KeyMap
+	theme   theme.Theme
+	state   core.State
+	history game.History[core.State]
 }
 
-func initialModel() tea.Model {
-	defaultStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#f9f6f2"))
-	c := func(s string) lipgloss.Color {
-		return lipgloss.Color(s)
-	}
+func initialModel(opts game.Options) tea.Model {
+	m := newModel(opts, undos)
+	m.state = core.New(opts.Rand)
+	return m
+}
+
+// dailyModel starts the daily challenge, in which no move can be undone.
+func dailyModel(opts game.Options) tea.Model {
+	m := newModel(opts, game.NoUndo)
+	m.state = core.New(opts.Rand)
+	return m
+}
 
-	m := model{
-		colors: map[int]lipgloss.Style{
-			0:    defaultStyle.Background(c("#3c3a32")),
-			2:    defaultStyle.Background(c("#eee4da")).Foreground(c("#000000")),
-			4:    defaultStyle.Background(c("#ede0c8")).Foreground(c("#000000")),
-			8:    defaultStyle.Background(c("#f2b179")),
-			16:   defaultStyle.Background(c("#f59563")),
-			32:   defaultStyle.Background(c("#f67c5f")),
-			64:   defaultStyle.Background(c("#f65e3b")),
-			128:  defaultStyle.Background(c("#edcf72")),
-			256:  defaultStyle.Background(c("#edcc61")),
-			512:  defaultStyle.Background(c("#edc850")),
-			1024: defaultStyle.Background(c("#edc53f")),
-			2048: defaultStyle.Background(c("#edc22e")),
-		},
-		grid: [4][4]int{},
+// newModel returns a model with an empty grid.
+func newModel(opts game.Options, policy game.UndoPolicy) model {
+	return model{
+		opts:    opts,
+		keys:    game.Keys("twenty48"),
+		theme:   theme.Current(),
+		state:   core.Load(core.Grid{}, opts.Rand),
+		history: game.NewHistory[core.State](policy),
 	}
+}
 
-	// The board needs to start with two starting tiles.
-	m.AddTile()
-	m.AddTile()
-	return m
+// moves are the moves of the player's actions.
+var moves = map[string]core.Move{
+	"up":    core.Up,
+	"down":  core.Down,
+	"left":  core.Left,
+	"right": core.Right,
 }
 
 func (m model) Init() tea.Cmd {
@@ -52,55 +77,64 @@ func (m model) Init() tea.Cmd {
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		action := m.keys.Action(msg)
+		if action == "quit" {
 			return m, tea.Quit
-		case "left", "h":
-			beforeMerge := m.grid
-			m.MergeTilesLeft()
-			m.ValidateTile(beforeMerge)
-		case "down", "j":
-			/* Instead of creating a separate method to merge down,
-			 * we rotate the grid. This is because the
-			 * m.MergeTilesLeft() method is *much* more complex
-			 * than m.Rotate90(), so it's simpler to rotate, merge,
-			 * then rotate back than to create a separate function.
-			 */
-			beforeMerge := m.grid
-			m.Rotate90(false)
-			m.MergeTilesLeft()
-			m.Rotate90(true)
+		}
 
-			m.ValidateTile(beforeMerge)
-		case "up", "k":
-			beforeMerge := m.grid
-			m.Rotate90(true)
-			m.MergeTilesLeft()
-			m.Rotate90(false)
-			m.ValidateTile(beforeMerge)
-		case "right", "l":
-			beforeMerge := m.grid
-			m.Rotate90(false)
-			m.Rotate90(false)
-			m.MergeTilesLeft()
-			m.Rotate90(true)
-			m.Rotate90(true)
-			m.ValidateTile(beforeMerge)
+		// The game can't be changed once it is over.
+		if m.state.Outcome() != core.Playing {
+			break
 		}
-	}
 
-	if m.CheckForWin() {
-		return m, tea.Quit
-	}
+		switch action {
+		case "undo":
+			m.state, _ = m.history.Undo(m.state)
+			return m, nil
+		case "redo":
+			m.state, _ = m.history.Redo(m.state)
+			return m, nil
+		}
+
+		move, ok := moves[action]
+		if !ok {
+			break
+		}
+
+		// Moves that change nothing are refused.
+		before := m.state
+		if err := m.state.Step(move); err != nil {
+			break
+		}
+		m.history.Record(before)
 
-	// The game is over when there are no possible merges.
-	if !m.CanMove() {
-		return m, tea.Quit
+		if m.state.Outcome() != core.Playing {
+			r := m.result()
+			return m, tea.Batch(game.Over(r), game.Publish("tile", r.Score))
+		}
 	}
 
 	return m, nil
 }
 
+// result describes the finished game by its best tile.
+func (m model) result() game.Result {
+	best := m.state.Best()
+
+	r := game.Result{
+		Score:   best,
+		Summary: fmt.Sprintf("stuck at %d", best),
+		Stats:   []game.Stat{{Name: "best tile", Value: float64(best)}},
+		Undos:   m.history.Undos(),
+	}
+	if m.state.Outcome() == core.Won {
+		r.Outcome = game.Win
+		r.Summary = "reached 2048"
+	}
+
+	return r
+}
+
 func (m model) View() string {
 	s := ""
 
@@ -115,11 +149,11 @@ func (m model) View() string {
 			 * For that reason, we add empty spaces. It provides a
 			 * row of padding, so the game looks better.
 			 */
-			s += m.colors[m.grid[y][x]].Render("      ")
+			s += m.theme.Tile(m.state.Grid[y][x]).Render("      ")
 		}
 		s += "\n"
 		for x := 0; x < 4; x++ {
-			stringifiedNum := strconv.Itoa(m.grid[y][x])
+			stringifiedNum := strconv.Itoa(m.state.Grid[y][x])
 			if stringifiedNum == "0" {
 				stringifiedNum = "."
 			}
@@ -128,152 +162,43 @@ func (m model) View() string {
 			 * the tiles is even.
 			 */
 			for i := 0; i < 5-len(stringifiedNum); i++ {
-				s += m.colors[m.grid[y][x]].Render(" ")
+				s += m.theme.Tile(m.state.Grid[y][x]).Render(" ")
 			}
-			s += m.colors[m.grid[y][x]].Render(stringifiedNum + " ")
+			s += m.theme.Tile(m.state.Grid[y][x]).Render(stringifiedNum + " ")
 		}
 		s += "\n"
 		for x := 0; x < 4; x++ {
 			// This is for the bottom line of padding.
-			s += m.colors[m.grid[y][x]].Render("      ")
+			s += m.theme.Tile(m.state.Grid[y][x]).Render("      ")
 		}
 		s += "\n"
 	}
 
-	s += "\nhjkl or arrows to move"
-
-	return s
-}
-
-func (m *model) MergeTilesLeft() {
-	for i := range m.grid {
-		stopMerge := 0
-		for j := 1; j < len(m.grid[i]); j++ {
-			if m.grid[i][j] != 0 {
-				for k := j; k > stopMerge; k-- {
-					switch {
-					case m.grid[i][k-1] == 0:
-						m.grid[i][k-1] = m.grid[i][k]
-						m.grid[i][k] = 0
-					case m.grid[i][k-1] == m.grid[i][k]:
-						m.grid[i][k-1] += m.grid[i][k]
-						m.grid[i][k] = 0
-						stopMerge = k
-					default:
-						break
-					}
-				}
-			}
-		}
-	}
-}
-
-func (m *model) AddTile() bool {
-	empty := []int{}
-	for y, row := range m.grid {
-		for x, cell := range row {
-			if cell == 0 {
-				empty = append(empty, y*4+x)
-			}
+	switch m.state.Outcome() {
+	case core.Won:
+		s += fmt.Sprintf("\nYou reached 2048! Press %s to quit.\nSeed: %d", m.keys.Keys("quit"), m.opts.Seed)
+	case core.Stuck:
+		s += fmt.Sprintf("\nNo moves left! Press %s to quit.\nSeed: %d", m.keys.Keys("quit"), m.opts.Seed)
+	default:
+		s += "\n" + game.HelpKey + " for the keys"
+		if undos := m.history.View(); undos != "" {
+			s += " - " + undos
 		}
 	}
 
-	if len(empty) == 0 {
-		return false
-	}
-
-	rndSrc := rand.NewPCG(
-		uint64(time.Now().UnixNano()),
-		uint64(time.Now().UnixMilli()),
-	)
-	rnd := rand.New(rndSrc)
-
-	cell := empty[rnd.IntN(len(empty))]
-
-	if rnd.IntN(10) < 9 {
-		m.grid[cell/len(m.grid)][cell%len(m.grid)] = 2
-	} else {
-		m.grid[cell/len(m.grid)][cell%len(m.grid)] = 4
-	}
-
-	return true
-}
-
-func (m *model) Rotate90(counterClockWise bool) {
-	rotatedGrid := [4][4]int{}
-	for i, row := range m.grid {
-		rotatedGrid[i] = [4]int{}
-		for j := range row {
-			if counterClockWise {
-				rotatedGrid[i][j] = m.grid[j][len(m.grid)-i-1]
-			} else {
-				rotatedGrid[i][j] = m.grid[len(m.grid)-j-1][i]
-			}
-		}
-	}
-	m.grid = rotatedGrid
-}
-
-func (m model) CheckForWin() bool {
-	for _, row := range m.grid {
-		for x := range row {
-			if row[x] == 2048 {
-				return true
-			}
-		}
-	}
-
-	return false
-}
-
-func (m *model) ValidateTile(beforeMerge [4][4]int) (tea.Model, tea.Cmd) {
-	// Check if the grid has changed after handling the merge logic.
-	if m.grid != beforeMerge {
-		// If unable to add a tile, quit.
-		if !m.AddTile() {
-			return m, tea.Quit
-		}
-	}
-
-	return m, nil
-}
-
-// Validates that movement is possible. Returns true only if there is at least one empty tile or any adjacent equal pairs present.
-func (m model) CanMove() bool {
-	// Checks for empty tiles.
-	for y := 0; y < 4; y++ {
-		for x := 0; x < 4; x++ {
-			if m.grid[y][x] == 0 {
-				return true
-			}
-		}
-	}
-
-	// Checks if there are any horizontal merges within the grid.
-	for y := 0; y < 4; y++ {
-		for x := 0; x < 3; x++ {
-			if m.grid[y][x] == m.grid[y][x+1] {
-				return true
-			}
-		}
-	}
-
-	// Checks if there are any vertical merges within the grid.
-	for x := 0; x < 4; x++ {
-		for y := 0; y < 3; y++ {
-			if m.grid[y][x] == m.grid[y+1][x] {
-				return true
-			}
-		}
-	}
-
-	return false
+	return s
 }
 
-func Run() {
-	p := tea.NewProgram(initialModel())
-
-	if _, err := p.Run(); err != nil {
-		panic(err)
-	}
+func init() {
+	game.Register(game.Game{
+		ID:          "twenty48",
+		Name:        "2048",
+		Players:     1,
+		Description: "Slide and merge tiles until you reach 2048.",
+		HighScores:  true,
+		Keys:        bindings(),
+		New:         initialModel,
+		Resume:      resume,
+		Daily:       dailyModel,
+	})
 }
@@ -0,0 +1,89 @@
+package twenty48
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/game"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var (
+	undoKey = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")}
+	redoKey = tea.KeyMsg{Type: tea.KeyCtrlR}
+)
+
+// play makes n moves, trying the directions in turn until one changes the
+// grid.
+func play(t *testing.T, m model, n int) model {
+	t.Helper()
+
+	directions := []tea.KeyMsg{{Type: tea.KeyLeft}, {Type: tea.KeyUp}, {Type: tea.KeyRight}, {Type: tea.KeyDown}}
+	for range n {
+		moved := false
+		for _, key := range directions {
+			next, _ := m.Update(key)
+			if next.(model).state.Grid != m.state.Grid {
+				m, moved = next.(model), true
+				break
+			}
+		}
+		if !moved {
+			t.Fatal("expected a move to be possible")
+		}
+	}
+
+	return m
+}
+
+// undo presses the undo key and reports whether it took back a move.
+func undo(m model) (model, bool) {
+	next, _ := m.Update(undoKey)
+	return next.(model), next.(model).state.Grid != m.state.Grid
+}
+
+func TestUndosAreLimited(t *testing.T) {
+	m := play(t, initialModel(game.Seeded(1)).(model), undos+1)
+
+	var ok bool
+	for i := range undos {
+		if m, ok = undo(m); !ok {
+			t.Fatalf("expected undo %d to be allowed", i+1)
+		}
+	}
+	if _, ok = undo(m); ok {
+		t.Fatalf("expected only %d undos to be allowed", undos)
+	}
+
+	// A move redone gives its undo back.
+	next, _ := m.Update(redoKey)
+	if m, ok = undo(next.(model)); !ok {
+		t.Error("expected the redo to give an undo back")
+	}
+	if m.result().Undos != undos {
+		t.Errorf("expected %d undos in the result, got %d", undos, m.result().Undos)
+	}
+}
+
+func TestDailyHasNoUndo(t *testing.T) {
+	m := play(t, dailyModel(game.Seeded(1)).(model), 2)
+
+	if _, ok := undo(m); ok {
+		t.Error("expected no undo in the daily challenge")
+	}
+}
+
+func TestResumeRefusesTooManyUndos(t *testing.T) {
+	m := initialModel(game.Seeded(1)).(model)
+	m.history.Resume(undos + 1)
+
+	data, err := json.Marshal(m.Suspend())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := resume(data); err == nil {
+		t.Error("expected more undos than allowed to be refused")
+	}
+}
@@ -0,0 +1,246 @@
+// Package core holds the rules of typespeed, free of any rendering and
+// timing, so that they can be tested on their own and played by bots. The
+// host decides when a second has passed.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// Prompt is a text to type, with the ID it has in the library of prompts.
+type Prompt struct {
+	ID   int    `json:"id"`
+	Text string `json:"text"`
+}
+
+// Tally counts the tries of a key and the errors among them.
+type Tally struct {
+	Tries  int `json:"tries"`
+	Errors int `json:"errors"`
+}
+
+// Action is a key typed, a printable ASCII character, or one of Backspace and
+// Tick.
+type Action rune
+
+const (
+	// Tick is a second passing.
+	Tick Action = 0
+
+	// Backspace rubs out the last character typed of the current word.
+	Backspace Action = '\b'
+)
+
+// Outcome tells whether the game is over.
+type Outcome int
+
+const (
+	Playing Outcome = iota
+
+	// Finished is the outcome once every prompt has been typed.
+	Finished
+)
+
+// State is a position of the game. The prompts are picked with rand, so two
+// games played with generators in the same state get the same prompts.
+type State struct {
+	// Prompts are the prompts that can come up, and Seen holds the IDs of
+	// those that already have.
+	Prompts []Prompt     `json:"-"`
+	Seen    map[int]bool `json:"seen"`
+
+	// Prompt is the prompt being typed. Pos is the position in its text of
+	// the next character to type, and Start that of the word being typed,
+	// which can't be rubbed out once it is done. Word is the index of that
+	// word, and Input what has been typed of it.
+	Prompt Prompt `json:"prompt"`
+	Pos    int    `json:"pos"`
+	Start  int    `json:"start"`
+	Word   int    `json:"word"`
+	Input  string `json:"input"`
+
+	PromptCompletions int `json:"prompt_completions"`
+	WordCompletions   int `json:"word_completions"`
+
+	// Time is the time spent typing, in seconds.
+	Time int `json:"time"`
+
+	// Hits and Errors count the characters of the prompts typed right and
+	// wrong the first time. Counted holds the positions in the prompt that
+	// have been, so that retyping doesn't count twice.
+	Hits    int          `json:"hits"`
+	Errors  int          `json:"errors"`
+	Counted map[int]bool `json:"counted"`
+
+	// Keys holds the tries and errors of every key of the prompts, by the
+	// key that was expected.
+	Keys map[string]Tally `json:"keys,omitempty"`
+
+	finished bool
+	rand     *rand.Rand
+}
+
+// New returns the game on the given prompts, with the first one picked.
+func New(prompts []Prompt, r *rand.Rand) State {
+	s := Load(State{Seen: map[int]bool{}}, prompts, r)
+	s.next()
+	return s
+}
+
+// Load returns a game continued from s, which is missing the prompts that can
+// come up and the random numbers picking them.
+func Load(s State, prompts []Prompt, r *rand.Rand) State {
+	s.Prompts, s.rand = prompts, r
+	if s.Seen == nil {
+		s.Seen = map[int]bool{}
+	}
+	if s.Counted == nil {
+		s.Counted = map[int]bool{}
+	}
+	return s
+}
+
+// Legal returns the keys that can be typed, with Backspace once something of
+// the current word has been, and Tick. There are none once the game is over.
+func (s State) Legal() []Action {
+	if s.finished {
+		return nil
+	}
+
+	actions := []Action{Tick}
+	if s.Input != "" {
+		actions = append(actions, Backspace)
+	}
+	for c := Action(' '); c <= '~'; c++ {
+		actions = append(actions, c)
+	}
+	return actions
+}
+
+// Step takes an action. Typing the last word of a prompt right moves on to
+// another one, until they have all been typed.
+func (s *State) Step(a Action) error {
+	switch {
+	case s.finished:
+		return errors.New("typespeed: the game is over")
+	case a == Tick:
+		s.Time++
+	case a == Backspace:
+		if s.Input != "" {
+			s.Input = s.Input[:len(s.Input)-1]
+			s.Pos--
+		}
+	case a >= ' ' && a <= '~':
+		s.typeKey(byte(a))
+	default:
+		return fmt.Errorf("typespeed: %q can't be typed", rune(a))
+	}
+
+	return nil
+}
+
+// Outcome returns Finished once every prompt has been typed.
+func (s State) Outcome() Outcome {
+	if s.finished {
+		return Finished
+	}
+
+	return Playing
+}
+
+// Words returns the words of the prompt being typed.
+func (s State) Words() []string {
+	return strings.Split(s.Prompt.Text, " ")
+}
+
+// WPM returns the number of words typed a minute.
+func (s State) WPM() float64 {
+	return s.perMinute(s.WordCompletions)
+}
+
+// CPM returns the number of characters typed right a minute.
+func (s State) CPM() float64 {
+	return s.perMinute(s.Hits)
+}
+
+// Accuracy returns the percentage of characters typed right, 0 until one
+// has been.
+func (s State) Accuracy() float64 {
+	if s.Hits == 0 {
+		return 0
+	}
+	return (1 - float64(s.Errors)/float64(s.Hits)) * 100
+}
+
+func (s State) perMinute(n int) float64 {
+	if s.Time == 0 {
+		return float64(n)
+	}
+	return float64(n) / (float64(s.Time) / 60)
+}
+
+func (s *State) typeKey(c byte) {
+	if s.Pos < len(s.Prompt.Text) {
+		s.Input += string(c)
+
+		// Spaces are neither hits nor errors.
+		want := s.Prompt.Text[s.Pos]
+		if !s.Counted[s.Pos] && c != ' ' {
+			if want != ' ' {
+				if s.Keys == nil {
+					s.Keys = make(map[string]Tally)
+				}
+				t := s.Keys[string(want)]
+				t.Tries++
+				if c != want {
+					t.Errors++
+				}
+				s.Keys[string(want)] = t
+			}
+
+			if c == want {
+				s.Hits++
+			} else {
+				s.Errors++
+			}
+			s.Counted[s.Pos] = true
+		}
+
+		s.Pos++
+	}
+
+	words := s.Words()
+	if word := words[s.Word]; len(s.Input) >= len(word) && strings.TrimSpace(s.Input) == word {
+		s.WordCompletions++
+		s.Word++
+		s.Start = s.Pos
+		s.Input = ""
+	}
+
+	if s.Word == len(words) {
+		s.PromptCompletions++
+		s.next()
+	}
+}
+
+// next picks a prompt that hasn't come up yet, or ends the game if there are
+// none left.
+func (s *State) next() {
+	if s.PromptCompletions == len(s.Prompts) {
+		s.finished = true
+		return
+	}
+
+	p := s.Prompts[s.rand.IntN(len(s.Prompts))]
+	for s.Seen[p.ID] {
+		p = s.Prompts[s.rand.IntN(len(s.Prompts))]
+	}
+
+	s.Seen[p.ID] = true
+	s.Prompt = p
+	s.Pos, s.Start, s.Word, s.Input = 0, 0, 0, ""
+	s.Counted = map[int]bool{}
+}
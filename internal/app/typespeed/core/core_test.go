@@ -0,0 +1,78 @@
+package core
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func newGame(texts ...string) State {
+	prompts := make([]Prompt, len(texts))
+	for i, text := range texts {
+		prompts[i] = Prompt{ID: i + 1, Text: text}
+	}
+	return New(prompts, rand.New(rand.NewPCG(1, 2)))
+}
+
+func typeText(t *testing.T, s *State, text string) {
+	t.Helper()
+	for _, c := range []byte(text) {
+		if err := s.Step(Action(c)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTyping(t *testing.T) {
+	s := newGame("go fmt")
+
+	typeText(t, &s, "gx")
+	if s.Hits != 1 || s.Errors != 1 || s.Keys["o"] != (Tally{1, 1}) {
+		t.Errorf("expected a hit and an error on o, got %d, %d and %v", s.Hits, s.Errors, s.Keys)
+	}
+
+	// Retyping a character doesn't count it again.
+	typeText(t, &s, string(Backspace)+"o")
+	if s.Hits != 1 || s.Errors != 1 || s.WordCompletions != 1 {
+		t.Errorf("expected the word done without counting o again, got %d hits, %d errors and %d words", s.Hits, s.Errors, s.WordCompletions)
+	}
+
+	// A word done can't be rubbed out.
+	typeText(t, &s, string(Backspace))
+	if s.Pos != 2 || s.Start != 2 {
+		t.Errorf("expected the cursor to stay after the word, got %d", s.Pos)
+	}
+
+	typeText(t, &s, " fmt")
+	if s.Outcome() != Finished || s.WordCompletions != 2 || s.PromptCompletions != 1 {
+		t.Errorf("expected the game to be over, got %v after %d words", s.Outcome(), s.WordCompletions)
+	}
+	if s.Legal() != nil || s.Step(Tick) == nil {
+		t.Error("expected no actions once the game is over")
+	}
+}
+
+func TestPrompts(t *testing.T) {
+	s := newGame("a", "b", "c")
+
+	seen := map[string]bool{}
+	for s.Outcome() == Playing {
+		seen[s.Prompt.Text] = true
+		typeText(t, &s, s.Prompt.Text)
+	}
+
+	if len(seen) != 3 || s.PromptCompletions != 3 {
+		t.Errorf("expected every prompt to come up once, got %v", seen)
+	}
+}
+
+func TestSpeed(t *testing.T) {
+	s := newGame("one two three")
+	typeText(t, &s, "one two")
+	for range 30 {
+		s.Step(Tick)
+	}
+
+	if s.WPM() != 4 || s.CPM() != 12 || s.Accuracy() != 100 {
+		t.Errorf("expected 4 WPM, 12 CPM and 100%% accuracy, got %v, %v and %v", s.WPM(), s.CPM(), s.Accuracy())
+	}
+}
@@ -9,14 +9,7 @@ import (
 var libraryYaml string
 
 type Config struct {
-	Prompts        []Prompt `yaml:"prompts"`
-	ActivePromptID int
-	SeenIDs        map[int]int
-
-	// how many prompts are active for the specific
-	// type requested. Used for determining when game should
-	// end if all prompts are used
-	ActivePromptsLen int
+	Prompts []Prompt `yaml:"prompts"`
 
 	// The type of game mode
 	PromptType string
@@ -24,7 +17,7 @@ type Config struct {
 	// Color for printing
 	PromptTypeColor string
 
-	PromptFormattedPrintString string 
+	PromptFormattedPrintString string
 }
 
 type Prompt struct {
@@ -0,0 +1,66 @@
+package typespeed
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Kaamkiya/gg/internal/app/typespeed/core"
+	"github.com/Kaamkiya/gg/internal/game"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// snapshot is the saved state of a suspended game. The prompt being typed is
+// kept as well, so that a game can be continued even if the library has
+// changed.
+type snapshot struct {
+	PromptType string     `json:"prompt_type"`
+	State      core.State `json:"state"`
+
+	Options game.Options `json:"options"`
+}
+
+func (m Model) Suspend() any {
+	if m.State.Outcome() == core.Finished {
+		return nil
+	}
+
+	return snapshot{
+		PromptType: m.Cfg.PromptType,
+		State:      m.State,
+		Options:    m.Opts,
+	}
+}
+
+func resume(data []byte) (tea.Model, error) {
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+
+	if s.PromptType == "coding" || !slices.Contains(gameModes, s.PromptType) && !slices.Contains(languages, s.PromptType) {
+		return nil, fmt.Errorf("unknown prompt type %q", s.PromptType)
+	}
+
+	st := s.State
+	switch {
+	case st.Prompt.Text == "":
+		return nil, errors.New("the prompt is missing")
+	case st.Start < 0 || st.Pos < st.Start || st.Pos > len(st.Prompt.Text):
+		return nil, errors.New("the cursor is outside the prompt")
+	case st.Word < 0 || st.Word >= len(st.Words()):
+		return nil, errors.New("the current word is outside the prompt")
+	case len(st.Input) != st.Pos-st.Start:
+		return nil, errors.New("the input is inconsistent")
+	}
+
+	cfg := newConfig(s.PromptType)
+
+	return Model{
+		Cfg:   cfg,
+		Opts:  s.Options,
+		State: core.Load(st, prompts(s.PromptType, cfg.Prompts), s.Options.Rand),
+	}, nil
+}
@@ -1,105 +1,36 @@
 package typespeed
 
 import (
+	"flag"
 	"fmt"
-	"log"
-	"math/rand"
-	"os"
+	"math"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/Kaamkiya/gg/internal/app/typespeed/core"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/theme"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 )
 
-
 const (
-	RESET       = "\033[0m"
-	RED         = "\033[31m"
-	RED_HEX = "#FF2900"
-	GREEN_HEX = "#00FF00"
-	DARK_BLUE_HEX = "#0011FF"
-	YELLOW_HEX = "#FFD900"
-	ORANGE_HEX = "#FF8C00"
-	TEAL_HEX = "#00D5FF"
-	BROWN_HEX = "#A34900"
-	PURPLE_HEX = "#9500FF"
-	WHITE_HEX = "#FFFFFF"
-	BLACK_HEX = "#000000"
-	GREY_HEX = "#6E7072"
 	UNDERLINE_CHAR = " "
 )
 
-var (
-	RED_HEX_LEN = len(lipgloss.NewStyle().Foreground(lipgloss.Color(RED_HEX)).Render("a"))
-	GREEN_HEX_LEN = len(lipgloss.NewStyle().Foreground(lipgloss.Color(GREEN_HEX)).Render("a"))
-)
-
 type TickMsg time.Time
 
-type State struct {
-	// Number of prompts completed
-	PromptCompletions int
-
-	// Number of words completed
-	WordCompletions int
-
-	// As decimal
-	Accuracy float32
-
-	// Correct words typed/minute
-	WPM float32
-
-	// Correct chars typed/minute
-	CPM float32
-
-	// Time elapsed in seconds
-	Time int
-
-	// Number of incorrect presses
-	Errors int
-
-	// Number of successful presses
-	Hits int
-
-	// Stores indexes of characters that have been attempted
-	// Ensures no double counting for Hits or Errors
-	SeenIdxSet map[int]int
-} 
-
 // Define your model
 type Model struct {
 	Cfg *Config
 
-	// Current string ID in play
-	PromptStrsID int
-
-	PromptStr string
-
-	// Current character the user is trying to solve for
-	// based on PromptStr
-	PromptIdx int
-
-	PromptIdxLowerLimit int
-
-	PromptUnderlines string
-
-	PromptSlice []string
+	// Opts holds the random numbers picking the prompts.
+	Opts game.Options
 
-	// Current word being attempted, initially 0
-	WordIdx int
-
-	InputStr string
-
-	InputStrPlain string
-
-	// Length of user input string
-	InputLen int
-
-	CharLenSlice []int
-
-	State *State
+	State core.State
 }
 
 func doTick() tea.Cmd {
@@ -112,123 +43,50 @@ func (m Model) Init() tea.Cmd {
 	return doTick()
 }
 
-func backspace(m *Model) {
-	if m.InputLen > 0 {
-		m.InputStr = m.InputStr[:len(m.InputStr)-m.CharLenSlice[len(m.CharLenSlice)-1]] //CHAR_LEN]
-		m.InputLen--
-		m.CharLenSlice = m.CharLenSlice[:len(m.CharLenSlice)-1] // pop
-		m.InputStrPlain = m.InputStrPlain[:len(m.InputStrPlain)-1]
-
-		// Decrement underline pointer if greater than lower limit
-		if m.PromptIdx > m.PromptIdxLowerLimit {
-			m.PromptIdx--
-			m.PromptUnderlines = updateUnderlines(m.PromptIdx, m.PromptIdx+1, m.PromptUnderlines)
-		}
-	}
-}
-
-func typeChar(m *Model, in string) {
-	if m.PromptIdx < len(m.PromptStr) {
-		m.InputStrPlain += in
-
-		if in == string(m.PromptStr[m.PromptIdx]) {
-
-			if _, ok := m.State.SeenIdxSet[m.PromptIdx]; !ok && in != " " {
-				m.State.Hits++
-				m.State.SeenIdxSet[m.PromptIdx] = 1
-			}
-
-			greenStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(GREEN_HEX))
-			in = greenStyle.Render(in)
-			m.CharLenSlice = append(m.CharLenSlice, GREEN_HEX_LEN)
-
-		} else {
-			if _, ok := m.State.SeenIdxSet[m.PromptIdx]; !ok && in != " " {
-				m.State.Errors++
-				m.State.SeenIdxSet[m.PromptIdx] = 1
-			}
-			redStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(RED_HEX))
-			in = redStyle.Render(in)
-			m.CharLenSlice = append(m.CharLenSlice, RED_HEX_LEN)
-		} 
-
-		m.InputLen++
-
-		m.PromptIdx++
-		m.PromptUnderlines = updateUnderlines(m.PromptIdx, m.PromptIdx-1, m.PromptUnderlines)
-
-		m.InputStr += in
-	}
-
-	// User typed word correctly
-	if len(m.InputStrPlain) >= len(m.PromptSlice[m.WordIdx]) && strings.TrimSpace(m.InputStrPlain) == (m.PromptSlice[m.WordIdx]) {
-		m.State.WordCompletions++
-		m.WordIdx++
-		m.PromptIdxLowerLimit = m.PromptIdx
-		m.InputStr = ""
-		m.InputStrPlain = ""
-		for range m.PromptIdxLowerLimit {
-			m.InputStr += " "
-		}
-		m.InputLen = 0
-	}
-}
-
-func getPrompt(prompts []Prompt, promptID int) Prompt {
-	return prompts[promptID-1]
-}
-
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	if m.PromptStrsID == -2 {
-		return m, tea.Quit
-	}
 	switch msg := msg.(type) {
 
 	case tea.KeyMsg:
+		// Any key leaves the game once all prompts are finished.
+		if m.State.Outcome() == core.Finished {
+			return m, tea.Quit
+		}
+
 		in := msg.String()
 
 		switch in {
-		case "ctrl+c":
+		case "ctrl+c", "esc":
 			return m, tea.Quit
 		case "enter", "ctrl+w", "ctrl+h", "ctrl+backspace", "tab", "ctrl+tab":
 
 		case "backspace":
-			backspace(&m)
+			m.State.Step(core.Backspace)
 		default:
 			if isValidChar(in) {
-				typeChar(&m, in)
-
-				if m.WordIdx > len(m.PromptSlice)-1 {
-					m.State.PromptCompletions++
-
-					m.PromptStrsID = getNewPromptId(m.Cfg, m.PromptStrsID, m.State)
-
-					// Exit the game
-					if m.PromptStrsID == -2 {
-						fmt.Println("Finished!")
-						return m, nil
-					}
-
-					// Reinitialize variables
-					m.PromptStr = getPrompt(m.Cfg.Prompts, m.PromptStrsID).Text
-					m.PromptSlice = strings.Split(m.PromptStr, " ")
-					m.PromptUnderlines = UNDERLINE_CHAR
-					for range m.PromptStr {
-						m.PromptUnderlines += " "
-					}
-					m.InputStr = ""
-					m.PromptIdx = 0
-					m.PromptIdxLowerLimit = 0
-					m.WordIdx = 0
-					m.InputLen = 0
-
-					// Wipe the seen set
-					m.State.SeenIdxSet = make(map[int]int)
+				m.State.Step(core.Action(in[0]))
+
+				// The game is finished, View shows the results.
+				if m.State.Outcome() == core.Finished {
+					wpm, accuracy := m.State.WPM(), m.State.Accuracy()
+					return m, tea.Batch(game.Publish("wpm", int(math.Round(wpm))), game.Over(game.Result{
+						Score:   int(math.Round(wpm)),
+						Summary: fmt.Sprintf("%.0f WPM at %.0f%% accuracy", wpm, accuracy),
+						Stats: []game.Stat{
+							{Name: "wpm", Value: wpm},
+							{Name: "accuracy", Value: accuracy},
+						},
+						Tallies: tallies(m.State.Keys),
+					}))
 				}
 			}
 		}
 	case TickMsg:
-		m.State.Time++
+		// The clock stops once the game is finished.
+		if m.State.Outcome() == core.Finished {
+			return m, nil
+		}
+
+		m.State.Step(core.Tick)
 		return m, doTick()
 	}
 
@@ -240,202 +98,229 @@ func isValidChar(in string) bool {
 	return len(in) == 1 && r >= 32 && r <= 126
 }
 
-func getNewPromptId(cfg *Config, curID int, state *State) int {
-	cfg.SeenIDs[curID] = 1
-
-	if cfg.ActivePromptsLen == state.PromptCompletions {
-		return -2
+// tallies returns the tries and errors of the keys as stats.
+func tallies(keys map[string]core.Tally) map[string]game.Tally {
+	if keys == nil {
+		return nil
 	}
 
-	newID := rand.Intn(len(cfg.Prompts)) + 1 // 1 indexed
-
-	// Keep looping if already used newID prompt
-	_, ok := cfg.SeenIDs[newID]
-	for ok || cfg.PromptType != "any" && getPrompt(cfg.Prompts, newID).Type != cfg.PromptType {
-		newID = rand.Intn(len(cfg.Prompts)) + 1
-		_, ok = cfg.SeenIDs[newID]
+	t := make(map[string]game.Tally, len(keys))
+	for key, tally := range keys {
+		t[key] = game.Tally{Tries: tally.Tries, Errors: tally.Errors}
 	}
-
-	return newID
-}
-
-// Takes the new idx to mark where the current pointer should point to in the string
-func updateUnderlines(newI, old int, s string) string {
-	s = s[:old] + " " + s[old+1:]
-	s = s[:newI] + UNDERLINE_CHAR + s[newI+1:]
-	return s
+	return t
 }
 
-func getActivePromptsLen(pType string, prompts []Prompt) int {
-	var res int
-
-	if pType == "any" {
-		return len(prompts)
-	}
-
-	for _, prompt := range prompts {
-		if prompt.Type == pType {
-			res++
+// prompts returns the prompts of the given type, or all of them for "any".
+func prompts(pType string, library []Prompt) []core.Prompt {
+	var res []core.Prompt
+	for _, prompt := range library {
+		if pType == "any" || prompt.Type == pType {
+			res = append(res, core.Prompt{ID: prompt.ID, Text: prompt.Text})
 		}
 	}
 
 	return res
 }
 
-func shiftCursor(m *Model) string {
-	if m.PromptIdx+1 < len(m.PromptStr)+1 {
-		highlightedChar := lipgloss.NewStyle().Background(lipgloss.Color(WHITE_HEX)).Foreground(lipgloss.Color(BLACK_HEX)).Render(string(m.PromptStr[m.PromptIdx]))
-		greyedOutText := lipgloss.NewStyle().Foreground(lipgloss.Color(GREY_HEX)).Render(string(m.PromptStr[m.PromptIdx+1:]))
-		return m.PromptStr[:m.PromptIdx] + highlightedChar + greyedOutText
+func shiftCursor(s core.State) string {
+	text := s.Prompt.Text
+	if s.Pos < len(text) {
+		highlightedChar := theme.Current().Cursor.Render(string(text[s.Pos]))
+		greyedOutText := theme.Current().Muted.Render(text[s.Pos+1:])
+		return text[:s.Pos] + highlightedChar + greyedOutText
 	}
 
-	return m.PromptStr
+	return text
 }
 
-func updateWPM(s *State) {
-	timeInMinutes := float32(s.Time) / float32(60)
-
-	if timeInMinutes > 0 {
-		s.WPM = (float32(s.WordCompletions) / timeInMinutes)
-	} else {
-		s.WPM = float32(s.WordCompletions)
-	}
+// underlines returns the line under the prompt, marking the next character
+// to type.
+func underlines(s core.State) string {
+	return strings.Repeat(" ", s.Pos) + UNDERLINE_CHAR + strings.Repeat(" ", len(s.Prompt.Text)-s.Pos)
 }
 
-func updateCPM(s *State) {
-	timeInMinutes := float32(s.Time) / float32(60)
+// input returns what has been typed of the current word under the prompt, the
+// right characters in one style and the wrong ones in another.
+func input(s core.State) string {
+	t := theme.Current()
 
-	if timeInMinutes > 0 {
-		s.CPM = (float32(s.Hits) / timeInMinutes)
-	} else {
-		s.CPM = float32(s.Hits)
+	res := strings.Repeat(" ", s.Start)
+	for i, c := range []byte(s.Input) {
+		if c == s.Prompt.Text[s.Start+i] {
+			res += t.Good.Render(string(c))
+		} else {
+			res += t.Bad.Render(string(c))
+		}
 	}
+	return res
 }
 
 func (m Model) View() string {
-	updateAccuracy(m.State)
-	updateWPM(m.State)
-	updateCPM(m.State)
-
-	PromptStr := shiftCursor(&m)
-
 	var display string
 
 	display = fmt.Sprintf(
-		"%s\n\n%s\n%s\n%s\nPrompt completions: %d\nWord completions: %d\nTime elapsed (s): %vs\nAccuracy: %.0f%%\nWPM: %.02f\nCPM: %0.02f\n\n", m.Cfg.PromptFormattedPrintString, PromptStr, m.PromptUnderlines, m.InputStr, m.State.PromptCompletions, m.State.WordCompletions, m.State.Time, m.State.Accuracy, m.State.WPM, m.State.CPM)
+		"%s\n\n%s\n%s\n%s\nPrompt completions: %d\nWord completions: %d\nTime elapsed (s): %vs\nAccuracy: %.0f%%\nWPM: %.02f\nCPM: %0.02f\n\n", m.Cfg.PromptFormattedPrintString, shiftCursor(m.State), underlines(m.State), input(m.State), m.State.PromptCompletions, m.State.WordCompletions, m.State.Time, m.State.Accuracy(), m.State.WPM(), m.State.CPM())
 
-	// -2 means game should quit
-	if m.PromptStrsID != -2 {
+	if m.State.Outcome() != core.Finished {
 		return display
 	}
 
-	return display + lipgloss.NewStyle().Foreground(lipgloss.Color(GREEN_HEX)).Render("\nFinished!\n")  
+	return display + theme.Current().Good.Render(fmt.Sprintf("\nFinished! Press any key to quit.\nSeed: %d\n", m.Opts.Seed))
+}
+
+// setupModel asks for the prompt type before the game itself starts.
+type setupModel struct {
+	opts     game.Options
+	form     *huh.Form
+	gameMode *string
+	pType    *string
 }
 
-func updateAccuracy(s *State) {
-	if s.Hits > 0 {
-		s.Accuracy = (1.0 - (float32(s.Errors) / float32(s.Hits))) * 100
+func initialModel(opts game.Options) tea.Model {
+	gameMode := new(string)
+	pType := new(string)
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Select a mode").
+				Options(
+					huh.NewOption("standard (standard prompts for typing speed)", "standard"),
+					huh.NewOption("coding (common coding motifs from different languages)", "coding"),
+					huh.NewOption("any", "any"),
+				).Value(gameMode),
+		),
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Select a coding language").
+				Options(huh.NewOptions(languages...)...).
+				Value(pType),
+		).WithHideFunc(func() bool {
+			return *gameMode != "coding"
+		}),
+	)
+
+	return setupModel{
+		opts:     opts,
+		form:     form,
+		gameMode: gameMode,
+		pType:    pType,
 	}
 }
 
-func Run() {
-	var gameMode string
-	err := huh.NewSelect[string]().
-		Title("Select a mode").
-		Options(
-			huh.NewOption("standard (standard prompts for typing speed)", "standard"),
-			huh.NewOption("coding (common coding motifs from different languages)", "coding"),
-			huh.NewOption("any", "any"),
-		).Value(&gameMode).Run()
+func (m setupModel) Init() tea.Cmd {
+	return m.form.Init()
+}
 
-	if err != nil {
-		fmt.Println("Error: failed to run selected game mode")
-		panic(err)
+func (m setupModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "esc" {
+		return m, tea.Quit
 	}
 
-	var pType string
-	var pTypeColor string
-	var hexColor string
-
-	switch gameMode {
-	case "standard":
-		pType = "standard"
-
-	case "coding":
-		err := huh.NewSelect[string]().
-			Title("Select a coding language").
-			Options(
-				huh.NewOption("c++", "c++"),
-				huh.NewOption("golang", "golang"),
-				huh.NewOption("python", "python"),
-				huh.NewOption("java", "java"),
-				huh.NewOption("rust", "rust"),
-			).Value(&pType).Run()
-		if err != nil {
-			fmt.Println("Error: failed to run selected game mode")
-			panic(err)
-		}
+	form, cmd := m.form.Update(msg)
+	m.form = form.(*huh.Form)
 
-	case "any":
-		pType = "any"
+	switch m.form.State {
+	case huh.StateAborted:
+		return m, tea.Quit
+	case huh.StateCompleted:
+		pType := *m.gameMode
+		if pType == "coding" {
+			pType = *m.pType
+		}
 
-	default:
-		panic("The game mode " + gameMode + " is not currently supported")
+		game := newModel(pType, m.opts)
+		return game, game.Init()
 	}
 
-	switch pType {
-	case "c++":
-		hexColor = DARK_BLUE_HEX
-	case "python":
-		hexColor = ORANGE_HEX
-	case "golang":
-		hexColor = TEAL_HEX
-	case "rust":
-		hexColor = BROWN_HEX
-	case "java":
-		hexColor = YELLOW_HEX
-	case "any", "standard":
-		hexColor = WHITE_HEX
+	return m, cmd
+}
+
+func (m setupModel) View() string {
+	return m.form.View()
+}
+
+// newConfig loads the prompts and sets them up for the given prompt type.
+func newConfig(pType string) *Config {
+	t := theme.Current()
+
+	// Each language is written in the color of a different piece of the
+	// theme.
+	style := t.Text
+	if i, ok := map[string]int{"c++": 0, "python": 2, "golang": 4, "rust": 6, "java": 7}[pType]; ok {
+		style = lipgloss.NewStyle().Foreground(t.Pieces[i].GetBackground())
 	}
 
-	pTypeColor = lipgloss.NewStyle().Foreground(lipgloss.Color(hexColor)).Render("---------" + pType + "---------")
+	pTypeColor := style.Render("---------" + pType + "---------")
 
 	cfg, err := parseYAML()
 	if err != nil {
-		log.Fatal(err)
+		panic(err)
 	}
 
 	cfg.PromptType = pType
 	cfg.PromptFormattedPrintString = pTypeColor
 	cfg.PromptTypeColor = pTypeColor
-	cfg.ActivePromptsLen = getActivePromptsLen(pType, cfg.Prompts)
 
-	state := State{
-		SeenIdxSet: make(map[int]int),
-	}
+	return cfg
+}
 
-	cfg.SeenIDs = make(map[int]int)
-	pStrsID := getNewPromptId(cfg, -1, &state)
-	pStr := getPrompt(cfg.Prompts, pStrsID).Text
-	pSlice := strings.Split(pStr, " ")
-	pUnderlines := UNDERLINE_CHAR
-	for range pStr {
-		pUnderlines += " "
+func newModel(pType string, opts game.Options) Model {
+	cfg := newConfig(pType)
+
+	return Model{
+		Cfg:   cfg,
+		Opts:  opts,
+		State: core.New(prompts(pType, cfg.Prompts), opts.Rand),
 	}
-	charLenSlice := []int{}
-
-	p := tea.NewProgram(Model{
-		Cfg:              cfg,
-		PromptStrsID:     pStrsID,
-		PromptStr:        pStr,
-		PromptSlice:      pSlice,
-		PromptUnderlines: pUnderlines,
-		State:            &state,
-		CharLenSlice: charLenSlice,
+}
+
+func init() {
+	game.Register(game.Game{
+		ID:          "typespeed",
+		Name:        "typespeed",
+		Players:     1,
+		Description: "Type the prompts as fast and as accurately as you can.",
+		HighScores:  true,
+		New:         initialModel,
+		Flags:       flags,
+		Resume:      resume,
+		Daily: func(opts game.Options) tea.Model {
+			return newModel("standard", opts)
+		},
 	})
+}
+
+var (
+	gameModes = []string{"standard", "coding", "any"}
+	languages = []string{"c++", "golang", "python", "java", "rust"}
+)
+
+func flags(fs *flag.FlagSet) func(game.Options) (tea.Model, error) {
+	mode := fs.String("mode", "", "prompt mode: "+strings.Join(gameModes, ", ")+" (asks if not set)")
+	lang := fs.String("lang", "", "language for the coding mode: "+strings.Join(languages, ", "))
+
+	return func(opts game.Options) (tea.Model, error) {
+		if *lang != "" && *mode == "" {
+			*mode = "coding"
+		}
+
+		switch {
+		case *mode == "":
+			return initialModel(opts), nil
+		case !slices.Contains(gameModes, *mode):
+			return nil, fmt.Errorf("unknown mode %q", *mode)
+		case *mode != "coding" && *lang != "":
+			return nil, fmt.Errorf("-lang only applies to the coding mode")
+		case *mode != "coding":
+			return newModel(*mode, opts), nil
+		case *lang == "":
+			return nil, fmt.Errorf("the coding mode needs -lang")
+		case !slices.Contains(languages, *lang):
+			return nil, fmt.Errorf("unknown language %q", *lang)
+		}
 
-	if _, err := p.Run(); err != nil {
-		fmt.Println("Error running program:", err)
-		os.Exit(1)
+		return newModel(*lang, opts), nil
 	}
 }
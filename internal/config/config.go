@@ -0,0 +1,80 @@
+// Package config loads the player's settings from gg's config file,
+// $XDG_CONFIG_HOME/gg/config.yaml. Every setting is optional, and a missing
+// file leaves gg with its defaults.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the player's settings.
+type Config struct {
+	// Theme names the theme games are drawn with, e.g. "colorblind". It is
+	// chosen from the terminal's background if empty.
+	Theme string `yaml:"theme"`
+
+	// Keys maps the ID of a game to the keys of its actions, replacing
+	// their default keys, e.g. {"pong": {"player1-left": ["a", "s"]}}.
+	Keys map[string]map[string][]string `yaml:"keys"`
+}
+
+// Path returns the path of the config file, $XDG_CONFIG_HOME/gg/config.yaml or
+// ~/.config/gg/config.yaml if XDG_CONFIG_HOME isn't set.
+func Path() (string, error) {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" || !filepath.IsAbs(base) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("config: finding the config directory: %w", err)
+		}
+		base = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(base, "gg", "config.yaml"), nil
+}
+
+// Load reads the config file. Settings it doesn't know are reported as errors,
+// so that a typo doesn't go unnoticed.
+func Load() (Config, error) {
+	var c Config
+
+	path, err := Path()
+	if err != nil {
+		return c, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return c, fmt.Errorf("config: %w", err)
+	}
+
+	if err := Parse(data, &c); err != nil {
+		return c, fmt.Errorf("config: %s: %w", path, err)
+	}
+
+	return c, nil
+}
+
+// Parse decodes the YAML document in data into c.
+func Parse(data []byte, c *Config) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	// An empty file is a valid config.
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	c, err := Load()
+	if err != nil || c.Keys != nil {
+		t.Fatalf("expected a missing file to leave the defaults, got %v, %v", c, err)
+	}
+
+	path := filepath.Join(dir, "gg", "config.yaml")
+	if p, _ := Path(); p != path {
+		t.Fatalf("expected the config at %s, got %s", path, p)
+	}
+
+	os.MkdirAll(filepath.Dir(path), 0o755)
+	data := "theme: colorblind\nkeys:\n  pong:\n    player1-left: [s, a]\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]map[string][]string{"pong": {"player1-left": {"s", "a"}}}
+	if !reflect.DeepEqual(c.Keys, want) {
+		t.Errorf("expected %v, got %v", want, c.Keys)
+	}
+	if c.Theme != "colorblind" {
+		t.Errorf("expected the colorblind theme, got %q", c.Theme)
+	}
+}
+
+func TestParse(t *testing.T) {
+	var c Config
+	if err := Parse(nil, &c); err != nil {
+		t.Errorf("expected an empty file to be valid, got %v", err)
+	}
+
+	if err := Parse([]byte("key:\n  pong: {}\n"), &c); err == nil {
+		t.Error("expected a misspelled setting to be reported")
+	}
+
+	if err := Parse([]byte("keys:\n  pong: [a]\n"), &c); err == nil {
+		t.Error("expected keys of the wrong type to be reported")
+	}
+}
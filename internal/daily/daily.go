@@ -0,0 +1,179 @@
+// Package daily runs the daily challenges. Every game with a daily challenge
+// is played with a seed derived from the current UTC date, so everyone gets
+// the same round that day. The first result of each day is kept in a single
+// versioned file in gg's data directory, from which the streaks are counted.
+package daily
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/storage"
+)
+
+const (
+	fileName = "daily.json"
+
+	// version is the current version of the results file. Files written by
+	// a newer version of gg are refused rather than overwritten.
+	version = 1
+
+	// layout is the format of the days results are kept under.
+	layout = time.DateOnly
+)
+
+// errPlayed aborts an update when the day's result has already been recorded.
+var errPlayed = errors.New("daily: already played")
+
+// Result is the outcome of a daily challenge.
+type Result struct {
+	Score   int    `json:"score"`
+	Summary string `json:"summary,omitempty"`
+}
+
+type file struct {
+	Version int `json:"version"`
+
+	// Days maps a day, e.g. "2024-12-31", to the results of the games
+	// played that day, keyed by game ID.
+	Days map[string]map[string]Result `json:"days"`
+}
+
+// Today returns the current day in UTC, which is the same for every player.
+func Today() string {
+	return time.Now().UTC().Format(layout)
+}
+
+// Seed returns the seed of the challenges of a day. It is the date written as
+// a number, e.g. 20241231, so it can also be typed in to play an old
+// challenge again with -seed.
+func Seed(day string) (uint64, error) {
+	t, err := time.Parse(layout, day)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(t.Year()*10000 + int(t.Month())*100 + t.Day()), nil
+}
+
+func decode(data []byte) (file, error) {
+	f := file{Version: version}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &f); err != nil {
+			return f, fmt.Errorf("daily: reading %s: %w", fileName, err)
+		}
+	}
+
+	if f.Version > version {
+		return f, fmt.Errorf("daily: %s was written by a newer version of gg", fileName)
+	}
+
+	if f.Days == nil {
+		f.Days = map[string]map[string]Result{}
+	}
+
+	return f, nil
+}
+
+func load() (file, error) {
+	data, err := storage.Read(fileName)
+	if err != nil {
+		return file{}, err
+	}
+
+	return decode(data)
+}
+
+// Lookup returns the result of a game's challenge on the given day. The
+// boolean is false if it hasn't been played.
+func Lookup(day, game string) (Result, bool, error) {
+	f, err := load()
+	r, ok := f.Days[day][game]
+	return r, ok, err
+}
+
+// Record saves the result of a game's challenge on the given day. Only the
+// first result of a day counts, so if one has already been recorded, Record
+// returns that one instead.
+func Record(day, id string, r game.Result) (Result, error) {
+	recorded := Result{Score: r.Score, Summary: r.Summary}
+
+	err := storage.Update(fileName, func(data []byte) ([]byte, error) {
+		f, err := decode(data)
+		if err != nil {
+			return nil, err
+		}
+
+		if earlier, ok := f.Days[day][id]; ok {
+			recorded = earlier
+			return nil, errPlayed
+		}
+
+		if f.Days[day] == nil {
+			f.Days[day] = map[string]Result{}
+		}
+		f.Days[day][id] = recorded
+		f.Version = version
+
+		return json.MarshalIndent(f, "", "  ")
+	})
+	if errors.Is(err, errPlayed) {
+		err = nil
+	}
+
+	return recorded, err
+}
+
+// Streak returns the number of consecutive days up to the given one on which
+// the game's challenge was played. A streak that ended the day before still
+// counts, since the day isn't over yet.
+func Streak(day, game string) (int, error) {
+	f, err := load()
+	if err != nil {
+		return 0, err
+	}
+
+	return f.streak(day, game)
+}
+
+func (f file) streak(day, game string) (int, error) {
+	t, err := time.Parse(layout, day)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, ok := f.Days[day][game]; !ok {
+		t = t.AddDate(0, 0, -1)
+	}
+
+	streak := 0
+	for {
+		if _, ok := f.Days[t.Format(layout)][game]; !ok {
+			return streak, nil
+		}
+
+		streak++
+		t = t.AddDate(0, 0, -1)
+	}
+}
+
+// Share returns a short text about a day's result that can be pasted into a
+// chat, in the spirit of Wordle's.
+func Share(day, name string, r Result, streak int) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "gg daily %s %s\n", name, day)
+	if r.Summary != "" {
+		b.WriteString(r.Summary + "\n")
+	} else {
+		fmt.Fprintf(&b, "score %d\n", r.Score)
+	}
+
+	fmt.Fprintf(&b, "%s %d day streak", strings.Repeat("🟩", min(streak, 7)), streak)
+	return b.String()
+}
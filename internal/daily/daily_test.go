@@ -0,0 +1,90 @@
+package daily
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/game"
+)
+
+func TestSeed(t *testing.T) {
+	seed, err := Seed("2024-12-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if seed != 20241231 {
+		t.Errorf("expected seed 20241231, got %d", seed)
+	}
+
+	if _, err := Seed("yesterday"); err == nil {
+		t.Error("expected an error for a malformed day")
+	}
+}
+
+func TestFirstResultCounts(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	if _, played, err := Lookup("2024-12-31", "sudoku"); played || err != nil {
+		t.Fatalf("expected nothing to be played, got %v, %v", played, err)
+	}
+
+	first, err := Record("2024-12-31", "sudoku", game.Result{Summary: "solved in 4:05"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := Record("2024-12-31", "sudoku", game.Result{Summary: "solved in 1:00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first != second || second.Summary != "solved in 4:05" {
+		t.Errorf("expected the first result to be kept, got %v", second)
+	}
+}
+
+func TestStreak(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	for _, day := range []string{"2024-12-27", "2024-12-29", "2024-12-30", "2024-12-31"} {
+		if _, err := Record(day, "maze", game.Result{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		day  string
+		want int
+	}{
+		{"2024-12-31", 3},
+		// The streak is still alive until the day is over.
+		{"2025-01-01", 3},
+		{"2025-01-02", 0},
+		{"2024-12-28", 1},
+	}
+
+	for _, tt := range tests {
+		got, err := Streak(tt.day, "maze")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("expected a streak of %d on %s, got %d", tt.want, tt.day, got)
+		}
+	}
+
+	if got, _ := Streak("2024-12-31", "sudoku"); got != 0 {
+		t.Errorf("expected no streak for another game, got %d", got)
+	}
+}
+
+func TestShare(t *testing.T) {
+	text := Share("2024-12-31", "2048", Result{Score: 512, Summary: "stuck at 512"}, 2)
+
+	for _, want := range []string{"2048", "2024-12-31", "stuck at 512", "2 day streak"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in the shared text:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package daily
+
+import (
+	"fmt"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type loadedMsg struct {
+	result Result
+	streak int
+	err    error
+}
+
+// Screen is shown after a daily challenge, or instead of one that has already
+// been played. It records the result, if there is one, and shows the text to
+// share until a key is pressed.
+type Screen struct {
+	day  string
+	game string
+	name string
+
+	// result is the result to record, or nil to show the recorded one.
+	result *game.Result
+
+	loaded   bool
+	recorded Result
+	streak   int
+	err      error
+
+	highlight lipgloss.Style
+}
+
+// NewScreen returns the screen for the challenge of the game with the given
+// ID on day. The name is the game's name as shown to the player. If result
+// is nil, the result recorded earlier that day is shown.
+func NewScreen(day, id, name string, result *game.Result) Screen {
+	return Screen{
+		day:       day,
+		game:      id,
+		name:      name,
+		result:    result,
+		highlight: theme.Current().Good.Bold(true),
+	}
+}
+
+func (s Screen) Init() tea.Cmd {
+	return func() tea.Msg {
+		var msg loadedMsg
+
+		if s.result != nil {
+			msg.result, msg.err = Record(s.day, s.game, *s.result)
+		} else {
+			var ok bool
+			msg.result, ok, msg.err = Lookup(s.day, s.game)
+			if msg.err == nil && !ok {
+				msg.err = fmt.Errorf("the challenge of %s hasn't been played yet", s.day)
+			}
+		}
+
+		if msg.err == nil {
+			msg.streak, msg.err = Streak(s.day, s.game)
+		}
+
+		return msg
+	}
+}
+
+func (s Screen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case loadedMsg:
+		s.loaded = true
+		s.recorded = msg.result
+		s.streak = msg.streak
+		s.err = msg.err
+	case tea.KeyMsg:
+		if s.loaded {
+			return s, tea.Quit
+		}
+	}
+
+	return s, nil
+}
+
+func (s Screen) View() string {
+	if !s.loaded {
+		return ""
+	}
+
+	v := fmt.Sprintf("Daily challenge: %s\n\n", s.name)
+	if s.err != nil {
+		v += fmt.Sprintf("The daily results are unavailable: %v\n", s.err)
+	} else {
+		v += s.highlight.Render(Share(s.day, s.name, s.recorded, s.streak)) + "\n\n"
+		v += "Come back tomorrow for a new challenge.\n"
+	}
+
+	v += "\nPress any key to continue.\n"
+	return v
+}
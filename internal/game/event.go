@@ -0,0 +1,55 @@
+package game
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// Event is something notable that happened during a round, such as a tile
+// reached in 2048 or four lines cleared at once in tetris. Events feed the
+// player's achievements.
+type Event struct {
+	// Name names what happened, e.g. "tile" or "lines cleared". Names
+	// only need to be unique within a game.
+	Name string
+
+	// Value measures what happened, e.g. the value of the tile. Events
+	// that don't measure anything leave it at zero.
+	Value int
+}
+
+// EventMsg carries an event published by a game.
+type EventMsg struct {
+	Event Event
+}
+
+// Publish returns a command that publishes an event of the current round. The
+// launcher hands it to whatever listens for the events of the game, and a
+// replay ignores it, so publishing never changes how a round plays.
+func Publish(name string, value int) tea.Cmd {
+	return func() tea.Msg {
+		return EventMsg{Event{name, value}}
+	}
+}
+
+// Subscriber is called with the events published by the games. It returns a
+// command for the host to run, e.g. to save something or to tell the player,
+// or nil.
+type Subscriber func(id string, e Event) tea.Cmd
+
+// subscribers is the event bus: everything listening for the events of games.
+var subscribers []Subscriber
+
+// Subscribe adds fn to the subscribers of the events of every game. Packages
+// subscribe from an init function, the same way games register.
+func Subscribe(fn Subscriber) {
+	subscribers = append(subscribers, fn)
+}
+
+// Dispatch hands an event published by the game with the given ID to every
+// subscriber and returns their commands.
+func Dispatch(id string, e Event) tea.Cmd {
+	cmds := make([]tea.Cmd, 0, len(subscribers))
+	for _, fn := range subscribers {
+		cmds = append(cmds, fn(id, e))
+	}
+
+	return tea.Batch(cmds...)
+}
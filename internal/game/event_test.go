@@ -0,0 +1,33 @@
+package game
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDispatch(t *testing.T) {
+	defer func(saved []Subscriber) { subscribers = saved }(subscribers)
+	subscribers = nil
+
+	if cmd := Dispatch("snake", Event{Name: "length"}); cmd != nil {
+		t.Error("expected no command without subscribers")
+	}
+
+	var got []string
+	Subscribe(func(id string, e Event) tea.Cmd {
+		got = append(got, id+" "+e.Name)
+		return nil
+	})
+	Subscribe(func(id string, e Event) tea.Cmd {
+		return func() tea.Msg { return e.Value }
+	})
+
+	cmd := Dispatch("twenty48", Event{Name: "tile", Value: 2048})
+	if len(got) != 1 || got[0] != "twenty48 tile" {
+		t.Errorf("expected the first subscriber to get the event, got %v", got)
+	}
+	if cmd == nil || cmd() != 2048 {
+		t.Error("expected the command of the second subscriber")
+	}
+}
@@ -0,0 +1,191 @@
+// Package game is the registry of games gg can launch. Each game package
+// registers a descriptor from an init function, and the launcher builds its
+// menu and dispatch from the registry instead of a hand-maintained list.
+package game
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Game describes a single playable game.
+type Game struct {
+	// ID is the stable identifier used to select the game, e.g. "twenty48".
+	ID string
+
+	// Name is the human readable name shown in menus, e.g. "2048".
+	Name string
+
+	// Players is the number of people needed at the keyboard.
+	Players int
+
+	// Description is a short, one line summary of the game.
+	Description string
+
+	// HighScores is set for games whose scores are kept on a leaderboard.
+	// Those games report their score with Over when a round ends.
+	HighScores bool
+
+	// Keys are the default key bindings of the game's actions, in the order
+	// they are listed in the help overlay. Players can bind other keys to
+	// the actions in their config file, so the game must look its keys up
+	// with the package's Keys function. Games that take free text input,
+	// where any key may be typed, have none.
+	Keys []Binding
+
+	// Recordable is set for games whose rounds can be recorded and
+	// replayed. Their commands must return at once, so they schedule their
+	// timers with Tick, and their models may depend on nothing but the
+	// options and the messages they receive.
+	Recordable bool
+
+	// Daily, if set, returns the round of the game's daily challenge.
+	// Everyone playing on the same day gets the round returned for that
+	// day's seed, so it must not depend on anything but the options. The
+	// game reports how the round went with Over.
+	Daily func(opts Options) tea.Model
+
+	// New returns the model for a fresh round of the game.
+	New func(opts Options) tea.Model
+
+	// Flags, if set, defines the game's command line flags on fs. The
+	// returned function is called once the flags have been parsed and
+	// builds the model they describe, or explains why the values can't be
+	// used.
+	Flags func(fs *flag.FlagSet) func(opts Options) (tea.Model, error)
+
+	// Resume, if set, rebuilds a game suspended by a model implementing
+	// Suspender from the JSON encoding of its snapshot.
+	Resume func(data []byte) (tea.Model, error)
+}
+
+// Suspender is implemented by the models of games that can be saved when the
+// player quits and continued later. Games opt in by implementing it and
+// setting Resume on their descriptor.
+type Suspender interface {
+	// Suspend returns a snapshot of the game that can be encoded with
+	// encoding/json, or nil if there is nothing worth continuing, e.g.
+	// because the game is over.
+	Suspend() any
+}
+
+// Title is the label shown for the game in the launcher menu.
+func (g Game) Title() string {
+	if g.Players > 1 {
+		return fmt.Sprintf("%s (%d player)", g.Name, g.Players)
+	}
+
+	return g.Name
+}
+
+// FlagSet returns the game's flag set along with the constructor to call once
+// it has been parsed. Every game gets a flag set with -seed, even if it has no
+// flags of its own.
+func (g Game) FlagSet() (*flag.FlagSet, func() (tea.Model, error)) {
+	fs := flag.NewFlagSet(g.ID, flag.ContinueOnError)
+	seed := fs.Uint64("seed", 0, "seed for the random numbers, to play a round again (random if not set)")
+
+	build := func(opts Options) (tea.Model, error) {
+		return g.New(opts), nil
+	}
+	if g.Flags != nil {
+		build = g.Flags(fs)
+	}
+
+	return fs, func() (tea.Model, error) {
+		seeded := false
+		fs.Visit(func(f *flag.Flag) {
+			seeded = seeded || f.Name == "seed"
+		})
+
+		if !seeded {
+			*seed = RandomSeed()
+		}
+
+		return build(Seeded(*seed))
+	}
+}
+
+// Parse builds a model for the game from its command line arguments. If the
+// arguments ask for help, the usage is written to output and flag.ErrHelp is
+// returned. Other errors are returned without being printed.
+func (g Game) Parse(args []string, output io.Writer) (tea.Model, error) {
+	fs, build := g.FlagSet()
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		g.printUsage(fs, output)
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	return build()
+}
+
+func (g Game) printUsage(fs *flag.FlagSet, output io.Writer) {
+	fmt.Fprintf(output, "Usage: gg %s [flags]\n\n%s\n", g.ID, g.Description)
+
+	hasFlags := false
+	fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		fmt.Fprintln(output, "\nFlags:")
+		fs.SetOutput(output)
+		fs.PrintDefaults()
+	}
+}
+
+var registry = map[string]Game{}
+
+// Register adds a game to the registry. It panics if the descriptor is
+// incomplete or its ID is already taken, since both are programming errors.
+func Register(g Game) {
+	if g.ID == "" || g.Name == "" || g.New == nil {
+		panic("game: Register called with an incomplete descriptor")
+	}
+
+	if _, ok := registry[g.ID]; ok {
+		panic("game: Register called twice for " + g.ID)
+	}
+
+	if err := validateKeys(g, nil); err != nil {
+		panic("game: Register called with bad keys for " + g.ID + ": " + err.Error())
+	}
+
+	registry[g.ID] = g
+}
+
+// Lookup returns the game registered under id.
+func Lookup(id string) (Game, bool) {
+	g, ok := registry[id]
+	return g, ok
+}
+
+// All returns every registered game, sorted by name.
+func All() []Game {
+	games := make([]Game, 0, len(registry))
+	for _, g := range registry {
+		games = append(games, g)
+	}
+
+	sort.Slice(games, func(i, j int) bool {
+		if games[i].Name == games[j].Name {
+			return games[i].ID < games[j].ID
+		}
+		return games[i].Name < games[j].Name
+	})
+
+	return games
+}
@@ -0,0 +1,150 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTitle(t *testing.T) {
+	single := Game{Name: "snake", Players: 1}
+	if single.Title() != "snake" {
+		t.Errorf("expected title snake, got %q", single.Title())
+	}
+
+	multi := Game{Name: "pong", Players: 2}
+	if multi.Title() != "pong (2 player)" {
+		t.Errorf("expected title pong (2 player), got %q", multi.Title())
+	}
+}
+
+func TestRegister(t *testing.T) {
+	defer func(saved map[string]Game) { registry = saved }(registry)
+	registry = map[string]Game{}
+
+	newModel := func(Options) tea.Model { return nil }
+	Register(Game{ID: "b", Name: "beta", New: newModel})
+	Register(Game{ID: "a", Name: "alpha", New: newModel})
+
+	if _, ok := Lookup("b"); !ok {
+		t.Fatal("expected registered game to be found")
+	}
+
+	if _, ok := Lookup("c"); ok {
+		t.Fatal("expected unknown game not to be found")
+	}
+
+	all := All()
+	if len(all) != 2 || all[0].ID != "a" || all[1].ID != "b" {
+		t.Fatalf("expected games sorted by name, got %v", all)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected duplicate registration to panic")
+		}
+	}()
+	Register(Game{ID: "a", Name: "again", New: newModel})
+}
+
+type sizeModel struct {
+	tea.Model
+	size int
+	seed uint64
+}
+
+func TestParse(t *testing.T) {
+	g := Game{
+		ID:   "sized",
+		Name: "sized",
+		New:  func(opts Options) tea.Model { return sizeModel{size: 10, seed: opts.Seed} },
+		Flags: func(fs *flag.FlagSet) func(Options) (tea.Model, error) {
+			size := fs.Int("size", 10, "size of the board")
+			return func(opts Options) (tea.Model, error) {
+				if *size < 1 {
+					return nil, errors.New("too small")
+				}
+				return sizeModel{size: *size, seed: opts.Seed}, nil
+			}
+		},
+	}
+
+	m, err := g.Parse([]string{"-size", "4"}, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.(sizeModel).size != 4 {
+		t.Errorf("expected size 4, got %d", m.(sizeModel).size)
+	}
+
+	if _, err := g.Parse([]string{"-size", "0"}, io.Discard); err == nil {
+		t.Error("expected an error for an invalid size")
+	}
+
+	if _, err := g.Parse([]string{"-h"}, io.Discard); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("expected flag.ErrHelp, got %v", err)
+	}
+
+	if _, err := g.Parse([]string{"extra"}, io.Discard); err == nil {
+		t.Error("expected an error for a stray argument")
+	}
+
+	g.Flags = nil
+	m, err = g.Parse(nil, io.Discard)
+	if err != nil || m.(sizeModel).size != 10 {
+		t.Errorf("expected the default model, got %v, %v", m, err)
+	}
+
+	m, err = g.Parse([]string{"-seed", "0"}, io.Discard)
+	if err != nil || m.(sizeModel).seed != 0 {
+		t.Errorf("expected seed 0, got %v, %v", m, err)
+	}
+}
+
+func TestSeededOptions(t *testing.T) {
+	a, b := Seeded(42), Seeded(42)
+	for range 10 {
+		if a.Rand.Uint64() != b.Rand.Uint64() {
+			t.Fatal("expected the same seed to give the same numbers")
+		}
+	}
+
+	data, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var c Options
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Seed != 42 {
+		t.Errorf("expected seed 42, got %d", c.Seed)
+	}
+
+	for range 10 {
+		if a.Rand.Uint64() != c.Rand.Uint64() {
+			t.Fatal("expected decoded options to continue where they left off")
+		}
+	}
+}
+
+func TestZeroOptions(t *testing.T) {
+	data, err := json.Marshal(Options{Seed: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var o Options
+	if err := json.Unmarshal(data, &o); err != nil {
+		t.Fatal(err)
+	}
+	if want := Seeded(7); o.Seed != 7 || o.Rand.Uint64() != want.Rand.Uint64() {
+		t.Error("expected options without a state to start over from their seed")
+	}
+}
@@ -0,0 +1,129 @@
+package game
+
+import "fmt"
+
+// UndoBindings are the default keys of the "undo" and "redo" actions, which
+// games with a History add to their own.
+var UndoBindings = []Binding{
+	{Action: "undo", Keys: []string{"u"}, Help: "undo the last move"},
+	{Action: "redo", Keys: []string{"ctrl+r"}, Help: "redo the last move undone"},
+}
+
+// UndoPolicy is the number of moves a player may take back in a round, or
+// one of NoUndo and UnlimitedUndo.
+type UndoPolicy int
+
+const (
+	// NoUndo is the policy of ranked rounds, such as daily challenges,
+	// which must be played as they come.
+	NoUndo UndoPolicy = 0
+
+	// UnlimitedUndo lets the player take back every move.
+	UnlimitedUndo UndoPolicy = -1
+)
+
+// historySize is the number of moves a History remembers. The oldest ones
+// can't be undone anymore.
+const historySize = 100
+
+// History is the undo and redo stack of a turn-based game. Before every move,
+// the game records its state, which must not share memory with the state it
+// goes on playing, and undoing a move brings the recorded state back. Games
+// keep a History in their model and report how many moves were undone in the
+// Result of the round.
+type History[S any] struct {
+	policy UndoPolicy
+
+	// done are the states before the moves that can be undone, the last
+	// one last, and undone the states after the moves that can be redone.
+	done   []S
+	undone []S
+
+	// undos counts the moves undone in the round and not redone.
+	undos int
+}
+
+// NewHistory returns an empty history with the given policy.
+func NewHistory[S any](policy UndoPolicy) History[S] {
+	return History[S]{policy: policy}
+}
+
+// Record remembers s, the state before a move, and forgets the moves that
+// were undone, which can't be redone after another move.
+func (h *History[S]) Record(s S) {
+	if h.policy == NoUndo {
+		return
+	}
+
+	if len(h.done) == historySize {
+		h.done = h.done[1:]
+	}
+	h.done = append(h.done, s)
+	h.undone = nil
+}
+
+// CanUndo reports whether there is a move to undo and the policy allows it.
+func (h History[S]) CanUndo() bool {
+	return len(h.done) > 0 && (h.policy == UnlimitedUndo || h.undos < int(h.policy))
+}
+
+// Undo returns the state before the last move, given the current state s,
+// which becomes the one to redo. It reports whether a move was undone.
+func (h *History[S]) Undo(s S) (S, bool) {
+	if !h.CanUndo() {
+		return s, false
+	}
+
+	prev := h.done[len(h.done)-1]
+	h.done = h.done[:len(h.done)-1]
+	h.undone = append(h.undone, s)
+	h.undos++
+	return prev, true
+}
+
+// Redo returns the state after the last move undone, given the current state
+// s, which becomes the one to undo again. It reports whether a move was
+// redone. Redoing a move gives its undo back, since the move stands as it was
+// played.
+func (h *History[S]) Redo(s S) (S, bool) {
+	if len(h.undone) == 0 {
+		return s, false
+	}
+
+	next := h.undone[len(h.undone)-1]
+	h.undone = h.undone[:len(h.undone)-1]
+	h.done = append(h.done, s)
+	h.undos--
+	return next, true
+}
+
+// Clear forgets the moves, e.g. when a new match starts, but keeps counting
+// the undos.
+func (h *History[S]) Clear() {
+	h.done, h.undone = nil, nil
+}
+
+// Undos returns the number of moves undone in the round and not redone.
+func (h History[S]) Undos() int {
+	return h.undos
+}
+
+// Resume sets the number of moves undone in a round continued after being
+// suspended. The moves made before can't be undone.
+func (h *History[S]) Resume(undos int) {
+	h.undos = undos
+}
+
+// View returns a line about the undos left to show below the game, or an
+// empty string if the policy doesn't limit them.
+func (h History[S]) View() string {
+	if h.policy <= 0 {
+		return ""
+	}
+
+	left := max(int(h.policy)-h.undos, 0)
+	if left == 1 {
+		return "1 undo left"
+	}
+	return fmt.Sprintf("%d undos left", left)
+}
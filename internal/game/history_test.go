@@ -0,0 +1,91 @@
+package game
+
+import "testing"
+
+func TestHistory(t *testing.T) {
+	h := NewHistory[int](UnlimitedUndo)
+
+	state := 0
+	for range 3 {
+		h.Record(state)
+		state++
+	}
+
+	for want := 2; want >= 0; want-- {
+		var ok bool
+		if state, ok = h.Undo(state); !ok || state != want {
+			t.Fatalf("expected to undo back to %d, got %d, %v", want, state, ok)
+		}
+	}
+	if _, ok := h.Undo(state); ok {
+		t.Fatal("expected nothing left to undo")
+	}
+
+	state, _ = h.Redo(state)
+	if state != 1 {
+		t.Fatalf("expected to redo to 1, got %d", state)
+	}
+
+	// A new move can't be followed by the moves undone before it.
+	h.Record(state)
+	state = 10
+	if _, ok := h.Redo(state); ok {
+		t.Error("expected a move to forget the moves undone")
+	}
+	if state, _ = h.Undo(state); state != 1 {
+		t.Errorf("expected to undo the new move, got %d", state)
+	}
+
+	// The redone move gave its undo back.
+	if h.Undos() != 3 {
+		t.Errorf("expected 3 undos, got %d", h.Undos())
+	}
+}
+
+func TestHistoryPolicy(t *testing.T) {
+	none := NewHistory[int](NoUndo)
+	none.Record(0)
+	if _, ok := none.Undo(1); ok {
+		t.Error("expected no undo to be allowed")
+	}
+
+	limited := NewHistory[int](2)
+	for i := range 5 {
+		limited.Record(i)
+	}
+
+	state := 5
+	for range 2 {
+		state, _ = limited.Undo(state)
+	}
+	if _, ok := limited.Undo(state); ok || state != 3 {
+		t.Errorf("expected two undos to be allowed, got back to %d", state)
+	}
+	if limited.View() != "0 undos left" {
+		t.Errorf("expected no undos left, got %q", limited.View())
+	}
+
+	// Redoing gives the undo back.
+	state, _ = limited.Redo(state)
+	if state, ok := limited.Undo(state); !ok || state != 3 {
+		t.Errorf("expected the redo to give an undo back, got back to %d", state)
+	}
+	if limited.Undos() != 2 {
+		t.Errorf("expected 2 undos, got %d", limited.Undos())
+	}
+}
+
+func TestHistoryIsBounded(t *testing.T) {
+	h := NewHistory[int](UnlimitedUndo)
+	for i := range historySize + 10 {
+		h.Record(i)
+	}
+
+	state := historySize + 10
+	for h.CanUndo() {
+		state, _ = h.Undo(state)
+	}
+	if state != 10 {
+		t.Errorf("expected the oldest moves to be forgotten, got back to %d", state)
+	}
+}
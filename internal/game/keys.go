@@ -0,0 +1,177 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// HelpKey is the key that shows the key bindings of the game being played.
+// It is handled by the launcher, so games can't bind it, and neither can they
+// bind ctrl+c, which always leaves gg.
+const HelpKey = "?"
+
+var reservedKeys = []string{HelpKey, "ctrl+c"}
+
+// Binding is the default binding of an action of a game.
+type Binding struct {
+	// Action names the action in the config file, e.g. "rotate-left".
+	Action string
+
+	// Keys are the keys that trigger the action, as named by
+	// tea.KeyMsg.String, e.g. "z" or "left".
+	Keys []string
+
+	// Help describes the action in the help overlay, e.g. "rotate left".
+	Help string
+}
+
+// KeyMap is the set of active key bindings of a game. It implements
+// help.KeyMap, listing the bindings in the order of the game's descriptor.
+type KeyMap struct {
+	actions  []string
+	bindings []key.Binding
+}
+
+// Action returns the action that msg triggers, or an empty string if it
+// triggers none.
+func (k KeyMap) Action(msg tea.KeyMsg) string {
+	for i, b := range k.bindings {
+		if key.Matches(msg, b) {
+			return k.actions[i]
+		}
+	}
+
+	return ""
+}
+
+// Keys returns the keys bound to action, separated by slashes, e.g. "h/left",
+// to tell the player about them.
+func (k KeyMap) Keys(action string) string {
+	if i := slices.Index(k.actions, action); i >= 0 {
+		return k.bindings[i].Help().Key
+	}
+
+	return ""
+}
+
+// Binding returns the binding of action. It is disabled if the game has no
+// such action.
+func (k KeyMap) Binding(action string) key.Binding {
+	if i := slices.Index(k.actions, action); i >= 0 {
+		return k.bindings[i]
+	}
+
+	return key.NewBinding(key.WithDisabled())
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return k.bindings
+}
+
+// FullHelp splits the bindings into columns of helpColumn rows.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	const helpColumn = 6
+
+	var columns [][]key.Binding
+	for b := range slices.Chunk(k.bindings, helpColumn) {
+		columns = append(columns, b)
+	}
+	return columns
+}
+
+// Empty reports whether the game has no key bindings.
+func (k KeyMap) Empty() bool {
+	return len(k.bindings) == 0
+}
+
+// keyConfig holds the keys configured for the actions of each game, by game
+// and action.
+var keyConfig map[string]map[string][]string
+
+// ConfigureKeys replaces the default keys of the actions of games with those
+// in config, which maps the ID of a game to the keys of its actions. It
+// returns an error and changes nothing if config names an unknown game or
+// action, binds an action to no key, or binds a key to two actions of a game
+// or to a key reserved by gg.
+func ConfigureKeys(config map[string]map[string][]string) error {
+	ids := make([]string, 0, len(config))
+	for id := range config {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		g, ok := registry[id]
+		if !ok {
+			return fmt.Errorf("keys: unknown game %q", id)
+		}
+
+		if err := validateKeys(g, config[id]); err != nil {
+			return fmt.Errorf("keys of %s: %w", id, err)
+		}
+	}
+
+	keyConfig = config
+	return nil
+}
+
+// Keys returns the active key bindings of the game with the given ID: the
+// defaults from its descriptor, with the keys set by ConfigureKeys.
+func Keys(id string) KeyMap {
+	g := registry[id]
+
+	k := KeyMap{
+		actions:  make([]string, len(g.Keys)),
+		bindings: make([]key.Binding, len(g.Keys)),
+	}
+	for i, b := range g.Keys {
+		keys := b.Keys
+		if configured, ok := keyConfig[id][b.Action]; ok {
+			keys = configured
+		}
+
+		k.actions[i] = b.Action
+		k.bindings[i] = key.NewBinding(key.WithKeys(keys...), key.WithHelp(strings.Join(keys, "/"), b.Help))
+	}
+
+	return k
+}
+
+// validateKeys checks the default keys of g, replaced by those in config.
+func validateKeys(g Game, config map[string][]string) error {
+	for action := range config {
+		if !slices.ContainsFunc(g.Keys, func(b Binding) bool { return b.Action == action }) {
+			return fmt.Errorf("unknown action %q", action)
+		}
+	}
+
+	bound := map[string]string{}
+	for _, b := range g.Keys {
+		keys := b.Keys
+		if configured, ok := config[b.Action]; ok {
+			keys = configured
+		}
+
+		if len(keys) == 0 {
+			return fmt.Errorf("%q has no keys", b.Action)
+		}
+
+		for _, k := range keys {
+			if slices.Contains(reservedKeys, k) {
+				return fmt.Errorf("%q can't be bound to %s, it is reserved", b.Action, k)
+			}
+
+			if other, ok := bound[k]; ok && other != b.Action {
+				return fmt.Errorf("%s is bound to both %q and %q", k, other, b.Action)
+			}
+			bound[k] = b.Action
+		}
+	}
+
+	return nil
+}
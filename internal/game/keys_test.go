@@ -0,0 +1,104 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func registerKeyed(t *testing.T) {
+	t.Helper()
+
+	saved, savedConfig := registry, keyConfig
+	t.Cleanup(func() { registry, keyConfig = saved, savedConfig })
+	registry = map[string]Game{}
+	keyConfig = nil
+
+	Register(Game{
+		ID:   "paddle",
+		Name: "paddle",
+		New:  func(Options) tea.Model { return nil },
+		Keys: []Binding{
+			{Action: "left", Keys: []string{"a", "left"}, Help: "move left"},
+			{Action: "right", Keys: []string{"d", "right"}, Help: "move right"},
+			{Action: "quit", Keys: []string{"q"}, Help: "quit"},
+		},
+	})
+}
+
+func TestKeys(t *testing.T) {
+	registerKeyed(t)
+
+	keys := Keys("paddle")
+	if got := keys.Action(tea.KeyMsg{Type: tea.KeyLeft}); got != "left" {
+		t.Errorf("expected the left arrow to move left, got %q", got)
+	}
+	if got := keys.Action(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")}); got != "" {
+		t.Errorf("expected x to do nothing, got %q", got)
+	}
+	if keys.Keys("right") != "d/right" {
+		t.Errorf("expected d/right, got %q", keys.Keys("right"))
+	}
+
+	if err := ConfigureKeys(map[string]map[string][]string{"paddle": {"left": {"j"}, "right": {"l", "a"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	keys = Keys("paddle")
+	if got := keys.Action(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")}); got != "right" {
+		t.Errorf("expected a to move right once configured, got %q", got)
+	}
+	if got := keys.Action(tea.KeyMsg{Type: tea.KeyLeft}); got != "" {
+		t.Errorf("expected the left arrow to be unbound, got %q", got)
+	}
+	if got := keys.Action(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); got != "quit" {
+		t.Errorf("expected q to keep its default, got %q", got)
+	}
+
+	if len(keys.FullHelp()) != 1 || len(keys.ShortHelp()) != 3 {
+		t.Error("expected the help to list the three bindings")
+	}
+	if !Keys("unknown").Empty() {
+		t.Error("expected an unknown game to have no keys")
+	}
+}
+
+func TestConfigureKeysRejectsBadConfigs(t *testing.T) {
+	registerKeyed(t)
+
+	for want, config := range map[string]map[string]map[string][]string{
+		"unknown game":   {"pinball": {"left": {"a"}}},
+		"unknown action": {"paddle": {"jump": {"w"}}},
+		"no keys":        {"paddle": {"quit": {}}},
+		"bound to both":  {"paddle": {"quit": {"a"}}},
+		"reserved":       {"paddle": {"quit": {"ctrl+c"}}},
+	} {
+		err := ConfigureKeys(config)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected an error about %s, got %v", want, err)
+		}
+	}
+
+	if keyConfig != nil {
+		t.Error("expected a rejected config not to be applied")
+	}
+}
+
+func TestRegisterRejectsConflictingKeys(t *testing.T) {
+	registerKeyed(t)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected conflicting default keys to panic")
+		}
+	}()
+	Register(Game{
+		ID:   "clash",
+		Name: "clash",
+		New:  func(Options) tea.Model { return nil },
+		Keys: []Binding{
+			{Action: "help", Keys: []string{HelpKey}},
+		},
+	})
+}
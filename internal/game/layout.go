@@ -0,0 +1,28 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Place centers view in a terminal of the given size. A view that doesn't fit
+// is replaced by a note asking for a larger terminal, since a view that wraps
+// can't be played. A zero size, before the terminal has reported its size,
+// leaves the view as is.
+func Place(width, height int, view string) string {
+	if width <= 0 || height <= 0 {
+		return view
+	}
+
+	// The newline ending most views doesn't take up a line of its own.
+	view = strings.TrimSuffix(view, "\n")
+
+	w, h := lipgloss.Size(view)
+	if w > width || h > height {
+		view = fmt.Sprintf("terminal too small, need %dx%d", w, h)
+	}
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, view)
+}
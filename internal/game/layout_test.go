@@ -0,0 +1,31 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestPlace(t *testing.T) {
+	view := "ab\ncd\n"
+
+	if got := Place(0, 0, view); got != view {
+		t.Errorf("expected the view as is before the size is known, got %q", got)
+	}
+
+	got := Place(6, 4, view)
+	if w, h := lipgloss.Size(got); w != 6 || h != 4 {
+		t.Fatalf("expected the view to fill the 6x4 terminal, got %dx%d", w, h)
+	}
+	if lines := strings.Split(got, "\n"); lines[1] != "  ab  " || lines[2] != "  cd  " {
+		t.Errorf("expected the view in the middle, got %q", got)
+	}
+
+	if got := Place(30, 1, view); !strings.Contains(got, "terminal too small, need 2x2") {
+		t.Errorf("expected a view taller than the terminal to be refused, got %q", got)
+	}
+	if got := Place(1, 10, view); !strings.Contains(got, "need 2x2") {
+		t.Errorf("expected a view wider than the terminal to be refused, got %q", got)
+	}
+}
@@ -0,0 +1,83 @@
+package game
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+// LevelFlag defines the -level flag of a game against the computer, which
+// sets the computer's strength to one of levels, given from the weakest. The
+// function it returns gives the level, or "" if the flag wasn't set, and
+// refuses unknown levels.
+func LevelFlag(fs *flag.FlagSet, levels []string) func() (string, error) {
+	level := fs.String("level", "", "strength of the computer: "+strings.Join(levels, ", ")+" (asks if not set)")
+
+	return func() (string, error) {
+		if *level != "" && !slices.Contains(levels, *level) {
+			return "", fmt.Errorf("unknown level %q", *level)
+		}
+
+		return *level, nil
+	}
+}
+
+// levelPicker asks for the strength of the computer before the game starts.
+type levelPicker struct {
+	form  *huh.Form
+	level *string
+	start func(level string) tea.Model
+}
+
+// PickLevel returns a model asking for the strength of the computer, one of
+// levels, given from the weakest. Once it is picked, the game start returns
+// for it takes over. Esc leaves the game.
+func PickLevel(levels []string, start func(level string) tea.Model) tea.Model {
+	level := new(string)
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Select a level").
+				Options(huh.NewOptions(levels...)...).
+				Value(level),
+		),
+	)
+
+	return levelPicker{
+		form:  form,
+		level: level,
+		start: start,
+	}
+}
+
+func (m levelPicker) Init() tea.Cmd {
+	return m.form.Init()
+}
+
+func (m levelPicker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "esc" {
+		return m, tea.Quit
+	}
+
+	form, cmd := m.form.Update(msg)
+	m.form = form.(*huh.Form)
+
+	switch m.form.State {
+	case huh.StateAborted:
+		return m, tea.Quit
+	case huh.StateCompleted:
+		game := m.start(*m.level)
+		return game, game.Init()
+	}
+
+	return m, cmd
+}
+
+func (m levelPicker) View() string {
+	return m.form.View()
+}
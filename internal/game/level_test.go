@@ -0,0 +1,50 @@
+package game
+
+import (
+	"flag"
+	"io"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLevelFlag(t *testing.T) {
+	levels := []string{"easy", "hard"}
+
+	for _, tt := range []struct {
+		args  []string
+		level string
+		err   bool
+	}{
+		{nil, "", false},
+		{[]string{"-level", "hard"}, "hard", false},
+		{[]string{"-level", "medium"}, "", true},
+	} {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		level := LevelFlag(fs, levels)
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := level()
+		if got != tt.level || (err != nil) != tt.err {
+			t.Errorf("%v: expected %q, error %v, got %q, %v", tt.args, tt.level, tt.err, got, err)
+		}
+	}
+}
+
+func TestPickLevelLeavesOnEsc(t *testing.T) {
+	m := PickLevel([]string{"easy", "hard"}, func(string) tea.Model {
+		t.Fatal("expected no game to be started")
+		return nil
+	})
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd == nil {
+		t.Fatal("expected the game to be left")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("expected the game to be left")
+	}
+}
@@ -0,0 +1,73 @@
+package game
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+)
+
+// maxRandomSeed bounds the seeds picked at random, so that they stay short
+// enough to be read off the screen and typed in again.
+const maxRandomSeed = 1_000_000
+
+// Options are handed to every game when a round starts.
+type Options struct {
+	// Seed is the seed Rand was built from. Games that use randomness show
+	// it when a round ends, so that the round can be played again with
+	// -seed.
+	Seed uint64
+
+	// Rand is the only source of randomness a game may use.
+	Rand *rand.Rand
+
+	src *rand.PCG
+}
+
+// Seeded returns the options of a round played with the given seed.
+func Seeded(seed uint64) Options {
+	src := rand.NewPCG(seed, seed)
+
+	return Options{
+		Seed: seed,
+		Rand: rand.New(src),
+		src:  src,
+	}
+}
+
+// RandomSeed picks a seed for a round the player didn't ask a seed for.
+func RandomSeed() uint64 {
+	return rand.Uint64N(maxRandomSeed)
+}
+
+type jsonOptions struct {
+	Seed  uint64 `json:"seed"`
+	State []byte `json:"state,omitempty"`
+}
+
+// MarshalJSON encodes the seed along with the current state of Rand, so that
+// a suspended game draws the same numbers once it is continued. Options that
+// weren't made by Seeded have no state, and are continued from their seed.
+func (o Options) MarshalJSON() ([]byte, error) {
+	if o.src == nil {
+		return json.Marshal(jsonOptions{Seed: o.Seed})
+	}
+
+	state, err := o.src.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(jsonOptions{o.Seed, state})
+}
+
+func (o *Options) UnmarshalJSON(data []byte) error {
+	var jo jsonOptions
+	if err := json.Unmarshal(data, &jo); err != nil {
+		return err
+	}
+
+	*o = Seeded(jo.Seed)
+	if jo.State == nil {
+		return nil
+	}
+	return o.src.UnmarshalBinary(jo.State)
+}
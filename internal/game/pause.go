@@ -0,0 +1,126 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PauseKey is the default key of the "pause" action, which pauses and resumes
+// real-time games.
+const PauseKey = "p"
+
+// countdown is the number of seconds counted down before a paused game
+// resumes. It is longer than the timers of the games using Pause, so that the
+// last timer scheduled before the pause has fired by the time they resume.
+const countdown = 3
+
+type countdownMsg struct {
+	round int
+}
+
+// Pause is the pause state of a real-time game. The game is paused with the
+// key of its "pause" action or when the terminal loses focus, and resumes
+// after a short countdown once the key is pressed again. Games keep a Pause in
+// their model, pass their messages through Update, and drop their own timers
+// while it isn't running.
+type Pause struct {
+	// key pauses and resumes the game. The zero Pause uses PauseKey.
+	key key.Binding
+
+	paused bool
+
+	// left is the number of seconds until the game resumes, or zero if
+	// it isn't counting down.
+	left int
+
+	// round tells the current countdown from those that were cancelled by
+	// pausing again.
+	round int
+}
+
+// NewPause returns the pause state of a running game with the given keys.
+func NewPause(keys KeyMap) Pause {
+	return Pause{key: keys.Binding("pause")}
+}
+
+// Running reports whether the game is neither paused nor counting down.
+func (p Pause) Running() bool {
+	return !p.paused && p.left == 0
+}
+
+// Update handles the messages that pause and resume the game: the pause key,
+// tea.BlurMsg and the ticks of the countdown. It reports whether msg was one
+// of them, in which case the game should return cmd and not handle msg
+// itself. When the countdown is over, resumed is set and the game should start
+// its timers again.
+func (p *Pause) Update(msg tea.Msg) (cmd tea.Cmd, handled, resumed bool) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if !p.matches(msg) {
+			return nil, false, false
+		}
+
+		if !p.paused {
+			p.pause()
+			return nil, true, false
+		}
+
+		p.paused = false
+		p.left = countdown
+		return p.tick(), true, false
+	case tea.BlurMsg:
+		if !p.paused {
+			p.pause()
+		}
+		return nil, true, false
+	case countdownMsg:
+		if msg.round != p.round || p.left == 0 {
+			return nil, true, false
+		}
+
+		p.left--
+		if p.left > 0 {
+			return p.tick(), true, false
+		}
+		return nil, true, true
+	}
+
+	return nil, false, false
+}
+
+func (p Pause) matches(msg tea.KeyMsg) bool {
+	if !p.key.Enabled() {
+		return msg.String() == PauseKey
+	}
+	return key.Matches(msg, p.key)
+}
+
+func (p *Pause) pause() {
+	p.paused = true
+	p.left = 0
+	p.round++
+}
+
+func (p Pause) tick() tea.Cmd {
+	return Tick(time.Second, countdownMsg{p.round})
+}
+
+// View returns a line about the pause to show below the game, or an empty
+// string if the game is running.
+func (p Pause) View() string {
+	switch {
+	case p.paused:
+		resume := PauseKey
+		if p.key.Enabled() {
+			resume = p.key.Help().Key
+		}
+		return fmt.Sprintf("Paused. Press %s to resume.", resume)
+	case p.left > 0:
+		return fmt.Sprintf("Resuming in %d...", p.left)
+	}
+
+	return ""
+}
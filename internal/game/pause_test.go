@@ -0,0 +1,78 @@
+package game
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var pauseKey = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(PauseKey)}
+
+// fire returns the message of a timer scheduled with Tick.
+func fire(t *testing.T, cmd tea.Cmd) tea.Msg {
+	t.Helper()
+
+	if cmd == nil {
+		t.Fatal("expected a timer")
+	}
+
+	tick, ok := cmd().(TickMsg)
+	if !ok {
+		t.Fatal("expected the timer to be scheduled with Tick")
+	}
+	return tick.Msg
+}
+
+func TestPause(t *testing.T) {
+	var p Pause
+
+	if _, handled, _ := p.Update(tea.KeyMsg{Type: tea.KeyLeft}); handled || !p.Running() {
+		t.Fatal("expected other keys to be left to the game")
+	}
+
+	if _, handled, _ := p.Update(tea.BlurMsg{}); !handled || p.Running() {
+		t.Fatal("expected losing focus to pause the game")
+	}
+	if p.View() == "" {
+		t.Error("expected the pause to be shown")
+	}
+
+	cmd, _, _ := p.Update(pauseKey)
+	if p.Running() || p.View() != "Resuming in 3..." {
+		t.Fatalf("expected a countdown, got %q", p.View())
+	}
+
+	for left := 2; left > 0; left-- {
+		var resumed bool
+		cmd, _, resumed = p.Update(fire(t, cmd))
+		if resumed || p.View() == "" {
+			t.Fatalf("expected the game to resume in %d seconds", left)
+		}
+	}
+
+	if _, _, resumed := p.Update(fire(t, cmd)); !resumed || !p.Running() || p.View() != "" {
+		t.Error("expected the game to resume after the countdown")
+	}
+}
+
+func TestPauseDuringCountdown(t *testing.T) {
+	var p Pause
+
+	p.Update(pauseKey)
+	cmd, _, _ := p.Update(pauseKey)
+	stale := fire(t, cmd)
+
+	// Pausing again cancels the countdown.
+	p.Update(pauseKey)
+	if _, handled, resumed := p.Update(stale); !handled || resumed || p.Running() {
+		t.Error("expected the cancelled countdown to be ignored")
+	}
+
+	cmd, _, _ = p.Update(pauseKey)
+	for i := 0; i < 2; i++ {
+		cmd, _, _ = p.Update(fire(t, cmd))
+	}
+	if _, _, resumed := p.Update(fire(t, cmd)); !resumed {
+		t.Error("expected the new countdown to resume the game")
+	}
+}
@@ -0,0 +1,77 @@
+package game
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// Outcome tells whether the player won a round.
+type Outcome int
+
+const (
+	// NoOutcome is the outcome of rounds that aren't won or lost, such as
+	// those of snake.
+	NoOutcome Outcome = iota
+	Win
+	Loss
+	Draw
+)
+
+// Result describes how a round of a game ended. Besides ending the round, it
+// is added to the player's lifetime stats.
+type Result struct {
+	// Score is the final score of the round. Higher scores are better.
+	Score int
+
+	// Summary is a short line about how the round went, e.g. "solved in
+	// 4:05", used where the score alone doesn't say much.
+	Summary string
+
+	// Outcome is set by games that are won or lost. In games for two
+	// players at one keyboard, it is the outcome for the first player.
+	Outcome Outcome
+
+	// Stats are the measures of the round to keep track of, such as the
+	// typing speed in typespeed.
+	Stats []Stat
+
+	// Tallies count the tries of the items of the round that can go
+	// wrong, such as the keys in typespeed, by item.
+	Tallies map[string]Tally
+
+	// Undos is the number of moves the player took back, which is kept
+	// with the score on the leaderboard.
+	Undos int
+}
+
+// Stat is a measure of a round.
+type Stat struct {
+	// Name names the stat, e.g. "wpm" or "solve time".
+	Name  string
+	Value float64
+
+	// LowerIsBetter is set for stats such as times, whose best value is
+	// the lowest.
+	LowerIsBetter bool
+
+	// Seconds is set when Value is a time in seconds.
+	Seconds bool
+}
+
+// Tally counts the tries of an item and the errors among them.
+type Tally struct {
+	Tries  int `json:"tries"`
+	Errors int `json:"errors"`
+}
+
+// OverMsg is sent by a game when a round has ended. The game keeps running,
+// usually to show a game over screen, until it quits.
+type OverMsg struct {
+	Result Result
+}
+
+// Over returns a command that reports the end of a round. Commands in a
+// tea.Batch run concurrently, so a game that wants to quit right away should
+// still report its result first and quit on a later update.
+func Over(r Result) tea.Cmd {
+	return func() tea.Msg {
+		return OverMsg{r}
+	}
+}
@@ -0,0 +1,22 @@
+package game
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TickMsg asks the host to send Msg to the game once Delay has passed.
+type TickMsg struct {
+	Delay time.Duration
+	Msg   tea.Msg
+}
+
+// Tick is the tea.Tick of games: it returns a command that sends msg once d
+// has passed. The host runs the timer, so that a replay can fire it without
+// waiting. Games that can be recorded schedule all of their timers with it.
+func Tick(d time.Duration, msg tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return TickMsg{d, msg}
+	}
+}
@@ -0,0 +1,639 @@
+// Package launcher hosts gg's menu and the game being played in a single
+// Bubble Tea program. When a game quits, the launcher swaps the menu back in
+// instead of letting the whole program exit.
+package launcher
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/achievements"
+	"github.com/Kaamkiya/gg/internal/daily"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/replay"
+	"github.com/Kaamkiya/gg/internal/save"
+	"github.com/Kaamkiya/gg/internal/scores"
+	"github.com/Kaamkiya/gg/internal/stats"
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	"github.com/charmbracelet/bubbles/help"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const header = "gg - a tui for small offline games\n\n"
+
+const (
+	// continuePrefix marks the menu option that continues a suspended game.
+	continuePrefix = "continue:"
+
+	// dailyOption is the menu option that opens the daily challenges.
+	dailyOption = "daily:"
+)
+
+// toastDuration is how long a toast about an unlocked achievement is shown.
+const toastDuration = 3 * time.Second
+
+var (
+	teaPkgPath = reflect.TypeOf(tea.QuitMsg{}).PkgPath()
+	cmdType    = reflect.TypeOf(tea.Cmd(nil))
+)
+
+// gameMsg carries a message produced by the command with the given ID of the
+// game started in session. Messages from earlier sessions, such as the last
+// tick of a game that has already been left, are dropped.
+type gameMsg struct {
+	session int
+	id      string
+	msg     tea.Msg
+}
+
+// exitMsg is sent when the game started in session asks to quit.
+type exitMsg struct {
+	session int
+}
+
+// toastDoneMsg is sent when the toast with the given ID has been shown long
+// enough.
+type toastDoneMsg struct {
+	id int
+}
+
+// Model is the root model of the gg program.
+type Model struct {
+	// selected is the ID of the game picked last, so that the menu can
+	// start on it when it is shown again.
+	selected *string
+	menu     *huh.Form
+
+	// inDaily is set while the menu lists the daily challenges.
+	inDaily bool
+
+	// current describes the game being played, and game is its model.
+	current game.Game
+	game    tea.Model
+	session int
+
+	// showKeys is set while the current game's key bindings are shown
+	// instead of the game.
+	showKeys bool
+
+	// result is the last result reported by the current game.
+	result *game.Result
+
+	// daily is the day of the challenge being played, if any.
+	daily string
+
+	// single is set when gg was started with a game instead of the menu.
+	// Leaving that game ends the program.
+	single bool
+
+	// rec, if set, records the single game until it is left.
+	rec *replay.Recording
+
+	// err is shown above the menu, e.g. when a game couldn't be suspended.
+	err error
+
+	// toast lists the achievements just unlocked, shown over the game or
+	// the menu until the toast with ID toastID is done.
+	toast   []achievements.Achievement
+	toastID int
+
+	size tea.WindowSizeMsg
+}
+
+// New returns a launcher that starts on the game menu.
+func New() Model {
+	m := Model{selected: new(string)}
+	m.menu = m.newMenu()
+	return m
+}
+
+// Play returns a launcher that plays a single game and exits after it.
+func Play(g game.Game, model tea.Model) Model {
+	return Model{
+		selected: new(string),
+		current:  g,
+		game:     model,
+		single:   true,
+	}
+}
+
+// Record returns a launcher that plays a single game like Play and records it
+// into rec.
+func Record(g game.Game, model tea.Model, rec *replay.Recording) Model {
+	m := Play(g, model)
+	m.rec = rec
+	return m
+}
+
+// Daily returns a launcher that plays today's challenge of g and exits after
+// it. If the challenge has already been played, its result is shown instead.
+func Daily(g game.Game) Model {
+	current, model, day := dailyRound(g)
+	m := Play(current, model)
+	m.daily = day
+	return m
+}
+
+// dailyRound returns the game and model to start for today's challenge of g,
+// along with the day of the challenge. If it has already been played, the
+// model is the screen with its result and the day is empty.
+func dailyRound(g game.Game) (game.Game, tea.Model, string) {
+	day := daily.Today()
+
+	// A results file that can't be read is reported once the challenge
+	// has been played.
+	if _, played, _ := daily.Lookup(day, g.ID); played {
+		return game.Game{}, daily.NewScreen(day, g.ID, g.Name, nil), ""
+	}
+
+	seed, _ := daily.Seed(day)
+	return g, g.Daily(game.Seeded(seed)), day
+}
+
+func (m Model) newMenu() *huh.Form {
+	if m.inDaily {
+		return m.newDailyMenu()
+	}
+
+	games := game.All()
+	options := make([]huh.Option[string], 0, len(games)+2)
+
+	var resumable []string
+	for _, g := range games {
+		if g.Resume != nil {
+			resumable = append(resumable, g.ID)
+		}
+	}
+
+	if info, ok := save.Latest(resumable); ok {
+		g, _ := game.Lookup(info.Game)
+		title := fmt.Sprintf("continue %s (%s)", g.Title(), info.Saved.Format("Jan 2 15:04"))
+		options = append(options, huh.NewOption(title, continuePrefix+g.ID))
+	}
+
+	for _, g := range games {
+		if g.Daily != nil {
+			options = append(options, huh.NewOption("daily challenge", dailyOption))
+			break
+		}
+	}
+
+	for _, g := range games {
+		options = append(options, huh.NewOption(g.Title(), g.ID))
+	}
+
+	return huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("choose a game:").
+				Options(options...).
+				Value(m.selected),
+		),
+	).WithShowHelp(false)
+}
+
+// newDailyMenu lists the games with a daily challenge, marking those that have
+// been played today.
+func (m Model) newDailyMenu() *huh.Form {
+	day := daily.Today()
+
+	var options []huh.Option[string]
+	for _, g := range game.All() {
+		if g.Daily == nil {
+			continue
+		}
+
+		title := g.Title()
+		if _, played, _ := daily.Lookup(day, g.ID); played {
+			title += " (played)"
+		}
+		options = append(options, huh.NewOption(title, g.ID))
+	}
+
+	return huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("daily challenge of " + day + ":").
+				Description("esc to go back").
+				Options(options...).
+				Value(m.selected),
+		),
+	).WithShowHelp(false)
+}
+
+func (m Model) Init() tea.Cmd {
+	if m.game != nil {
+		return m.wrap(m.game.Init())
+	}
+
+	return m.menu.Init()
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		// ctrl+c always leaves gg, even while a game is running.
+		if msg.String() == "ctrl+c" {
+			m.suspend()
+			return m, tea.Quit
+		}
+
+		if m.game != nil {
+			if m.showKeys {
+				switch msg.String() {
+				case game.HelpKey, "esc", "q":
+					m.showKeys = false
+				}
+				return m, nil
+			}
+
+			if msg.String() == game.HelpKey && !game.Keys(m.current.ID).Empty() {
+				m.showKeys = true
+				// Real-time games pause when they lose focus, which
+				// keeps them from running on behind the keys.
+				return m.sendGame(tea.BlurMsg{})
+			}
+		}
+	case tea.WindowSizeMsg:
+		m.size = msg
+	case gameMsg:
+		if msg.session != m.session || m.game == nil {
+			return m, nil
+		}
+
+		// The program handles its own messages, such as the one sent by
+		// tea.ClearScreen, so they are passed on to it as is.
+		if reflect.TypeOf(msg.msg).PkgPath() == teaPkgPath {
+			return m, func() tea.Msg { return msg.msg }
+		}
+
+		switch inner := msg.msg.(type) {
+		case game.OverMsg:
+			m.result = &inner.Result
+			if err := stats.Record(m.current, inner.Result); err != nil {
+				m.err = err
+			}
+			return m, nil
+		case game.EventMsg:
+			return m, game.Dispatch(m.current.ID, inner.Event)
+		case game.TickMsg:
+			return m, tea.Tick(inner.Delay, func(time.Time) tea.Msg {
+				return gameMsg{msg.session, msg.id, inner.Msg}
+			})
+		}
+
+		m.rec.Message(msg.id)
+		return m.updateGame(msg.msg)
+	case exitMsg:
+		if msg.session != m.session || m.game == nil {
+			return m, nil
+		}
+		return m.leaveGame()
+	case achievements.UnlockedMsg:
+		if msg.Err != nil {
+			m.err = msg.Err
+		}
+		if len(msg.Achievements) == 0 {
+			return m, nil
+		}
+
+		m.toast = append(m.toast, msg.Achievements...)
+		m.toastID++
+		id := m.toastID
+		return m, tea.Tick(toastDuration, func(time.Time) tea.Msg {
+			return toastDoneMsg{id}
+		})
+	case toastDoneMsg:
+		if msg.id == m.toastID {
+			m.toast = nil
+		}
+		return m, nil
+	}
+
+	if m.game != nil {
+		return m.sendGame(msg)
+	}
+
+	return m.updateMenu(msg)
+}
+
+func (m Model) View() string {
+	return m.overlayToast(m.view())
+}
+
+func (m Model) view() string {
+	if m.game != nil && m.showKeys {
+		return m.keysView()
+	}
+
+	if m.game != nil {
+		return game.Place(m.size.Width, m.size.Height, m.game.View())
+	}
+
+	// A single game has been left and the program is exiting.
+	if m.menu == nil {
+		return ""
+	}
+
+	if m.err != nil {
+		return header + "error: " + m.err.Error() + "\n\n" + m.menu.View()
+	}
+
+	return header + m.menu.View()
+}
+
+// overlayToast draws the toast, if any, over the top lines of view, which
+// are usually blank around a centered game. Above the menu, or before the size
+// of the terminal is known, the toast goes above the view instead.
+func (m Model) overlayToast(view string) string {
+	if len(m.toast) == 0 {
+		return view
+	}
+
+	t := theme.Current()
+	lines := make([]string, len(m.toast))
+	for i, a := range m.toast {
+		lines[i] = t.Accent.Render("achievement unlocked: "+a.Title) + "  " + t.Muted.Render(a.Description)
+	}
+	toast := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Border).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+
+	if m.game == nil || m.size.Width <= 0 {
+		return toast + "\n" + view
+	}
+
+	toastLines := strings.Split(lipgloss.PlaceHorizontal(m.size.Width, lipgloss.Center, toast), "\n")
+	viewLines := strings.Split(view, "\n")
+	for i, line := range toastLines {
+		if i < len(viewLines) {
+			viewLines[i] = line
+		} else {
+			viewLines = append(viewLines, line)
+		}
+	}
+
+	return strings.Join(viewLines, "\n")
+}
+
+func (m Model) updateMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
+	form, cmd := m.menu.Update(msg)
+	m.menu = form.(*huh.Form)
+
+	switch m.menu.State {
+	case huh.StateAborted:
+		if m.inDaily {
+			m.inDaily = false
+			*m.selected = dailyOption
+			return m.showMenu()
+		}
+		return m, tea.Quit
+	case huh.StateCompleted:
+		if *m.selected == dailyOption {
+			m.inDaily = true
+			return m.showMenu()
+		}
+
+		if id, ok := strings.CutPrefix(*m.selected, continuePrefix); ok {
+			*m.selected = id
+			return m.resumeGame(id)
+		}
+
+		g, ok := game.Lookup(*m.selected)
+		if !ok {
+			return m.showMenu()
+		}
+
+		if m.inDaily {
+			m.inDaily = false
+			current, model, day := dailyRound(g)
+			m.daily = day
+			return m.startGame(current, model)
+		}
+
+		return m.startGame(g, g.New(game.Seeded(game.RandomSeed())))
+	}
+
+	return m, cmd
+}
+
+// keysView shows the key bindings of the current game.
+func (m Model) keysView() string {
+	keys := game.Keys(m.current.ID)
+
+	var b strings.Builder
+	b.WriteString(m.current.Name + " keys\n\n")
+	b.WriteString(help.New().FullHelpView(keys.FullHelp()))
+	fmt.Fprintf(&b, "\n\n%s or esc to go back to the game", game.HelpKey)
+
+	box := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(theme.Current().Border).Padding(1, 2).Render(b.String())
+	return lipgloss.Place(m.size.Width, m.size.Height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// sendGame sends a message from the program to the game, recording it if a
+// recording is running.
+func (m Model) sendGame(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m.rec.Input(msg)
+	return m.updateGame(msg)
+}
+
+func (m Model) updateGame(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.game, cmd = m.game.Update(msg)
+	return m, m.wrap(cmd)
+}
+
+func (m Model) startGame(g game.Game, model tea.Model) (tea.Model, tea.Cmd) {
+	m.session++
+	m.showKeys = false
+	m.current = g
+	m.game = model
+	m.result = nil
+	m.err = nil
+	cmds := []tea.Cmd{m.wrap(model.Init())}
+
+	// The game missed the size sent when the program started.
+	if m.size.Width > 0 {
+		var cmd tea.Cmd
+		m.game, cmd = m.game.Update(m.size)
+		cmds = append(cmds, m.wrap(cmd))
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// resumeGame continues the suspended game with the given ID. The snapshot is
+// removed once the game is running again, so that it is only continued once.
+func (m Model) resumeGame(id string) (tea.Model, tea.Cmd) {
+	g, ok := game.Lookup(id)
+	if !ok || g.Resume == nil {
+		return m.showMenu()
+	}
+
+	data, err := save.Load(id)
+	if err != nil {
+		m.err = err
+		return m.showMenu()
+	}
+
+	model, err := g.Resume(data)
+	if err != nil {
+		// The snapshot will never load, so don't offer it again.
+		save.Delete(id)
+		m.err = fmt.Errorf("can't continue %s: %w", g.Name, err)
+		return m.showMenu()
+	}
+
+	err = save.Delete(id)
+	next, cmd := m.startGame(g, model)
+	launched := next.(Model)
+	launched.err = err
+	return launched, cmd
+}
+
+// suspend saves the current game if it supports being continued later. Daily
+// challenges aren't suspended, their result is recorded instead, so that a
+// round left part-way can't be played again for a better one.
+func (m *Model) suspend() {
+	if m.daily != "" {
+		if _, err := daily.Record(m.daily, m.current.ID, m.dailyResult()); err != nil {
+			m.err = fmt.Errorf("can't record the daily challenge of %s: %w", m.current.Name, err)
+		}
+		return
+	}
+
+	s, ok := m.game.(game.Suspender)
+	if !ok || m.current.Resume == nil {
+		return
+	}
+
+	state := s.Suspend()
+	if state == nil {
+		return
+	}
+
+	if err := save.Store(m.current.ID, state); err != nil {
+		m.err = fmt.Errorf("can't suspend %s: %w", m.current.Name, err)
+	}
+}
+
+// dailyResult returns the result of the daily round being played. A round
+// left before it was over counts as abandoned.
+func (m Model) dailyResult() game.Result {
+	if m.result == nil {
+		return game.Result{Summary: "abandoned"}
+	}
+
+	return *m.result
+}
+
+func (m Model) leaveGame() (tea.Model, tea.Cmd) {
+	m.rec.Stop()
+
+	// Daily challenges show the text to share instead of the leaderboard. The
+	// screen records the result itself.
+	if day := m.daily; day != "" {
+		m.daily = ""
+		result := m.dailyResult()
+		screen := daily.NewScreen(day, m.current.ID, m.current.Name, &result)
+		return m.startGame(game.Game{}, screen)
+	}
+
+	m.suspend()
+
+	if m.current.HighScores && m.result != nil && m.result.Score > 0 {
+		// The leaderboard is checked again when the score is saved, so a
+		// file that can't be read here just means no prompt.
+		if ok, _ := scores.Qualifies(m.current.ID, m.result.Score); ok {
+			prompt := scores.NewPrompt(m.current.ID, m.current.Name, m.result.Score, m.result.Undos)
+			return m.startGame(game.Game{}, prompt)
+		}
+	}
+
+	m.current = game.Game{}
+	m.game = nil
+	m.result = nil
+	if m.single {
+		return m, tea.Quit
+	}
+
+	return m.showMenu()
+}
+
+func (m Model) showMenu() (tea.Model, tea.Cmd) {
+	m.menu = m.newMenu()
+	cmd := m.menu.Init()
+
+	if m.size.Width > 0 {
+		form, sizeCmd := m.menu.Update(m.size)
+		m.menu = form.(*huh.Form)
+		cmd = tea.Batch(cmd, sizeCmd)
+	}
+
+	return m, cmd
+}
+
+// wrap tags the messages produced by cmd with the current session and the
+// command's ID, and turns a request to quit into a request to leave the game.
+// The commands of batches and sequences are tagged in turn, so that a quit at
+// the end of a sequence leaves the game too.
+func (m Model) wrap(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+
+	return tag(m.session, m.rec.Command(), cmd)
+}
+
+func tag(session int, id string, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		msg := cmd()
+		switch msg := msg.(type) {
+		case nil:
+			return nil
+		case tea.QuitMsg:
+			return exitMsg{session}
+		case tea.BatchMsg:
+			cmds := make(tea.BatchMsg, len(msg))
+			for i, c := range msg {
+				cmds[i] = tag(session, replay.CommandID(id, i), c)
+			}
+			return cmds
+		}
+
+		if cmds, ok := sequence(msg); ok {
+			for i, c := range cmds {
+				cmds[i] = tag(session, replay.CommandID(id, i), c)
+			}
+			return tea.Sequence(cmds...)()
+		}
+		return gameMsg{session, id, msg}
+	}
+}
+
+// sequence returns the commands of the message sent by tea.Sequence, whose
+// type is unexported, and reports whether msg is one.
+func sequence(msg tea.Msg) ([]tea.Cmd, bool) {
+	t := reflect.TypeOf(msg)
+	if t.PkgPath() != teaPkgPath || t.Kind() != reflect.Slice || t.Elem() != cmdType {
+		return nil, false
+	}
+
+	v := reflect.ValueOf(msg)
+	cmds := make([]tea.Cmd, v.Len())
+	for i := range cmds {
+		cmds[i] = v.Index(i).Interface().(tea.Cmd)
+	}
+	return cmds, true
+}
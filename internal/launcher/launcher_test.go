@@ -0,0 +1,446 @@
+package launcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/achievements"
+	"github.com/Kaamkiya/gg/internal/daily"
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/replay"
+	"github.com/Kaamkiya/gg/internal/save"
+	"github.com/Kaamkiya/gg/internal/scores"
+	"github.com/Kaamkiya/gg/internal/stats"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// quitter is a game that quits on any key.
+type quitter struct{}
+
+func (quitter) Init() tea.Cmd                       { return nil }
+func (quitter) Update(tea.Msg) (tea.Model, tea.Cmd) { return quitter{}, tea.Quit }
+func (quitter) View() string                        { return "playing" }
+
+// sequencer is a game that clears the screen and then quits on any key.
+type sequencer struct{}
+
+func (sequencer) Init() tea.Cmd { return nil }
+func (sequencer) Update(tea.Msg) (tea.Model, tea.Cmd) {
+	return sequencer{}, tea.Sequence(tea.ClearScreen, tea.Quit)
+}
+func (sequencer) View() string { return "playing" }
+
+// scorer is a game that ends with a score of 5, reached with the given number
+// of undos, on the first key and quits on the second.
+type scorer struct {
+	over  bool
+	undos int
+}
+
+func (s scorer) Init() tea.Cmd { return nil }
+func (s scorer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(tea.KeyMsg); !ok {
+		return s, nil
+	}
+	if s.over {
+		return s, tea.Quit
+	}
+	return scorer{over: true}, game.Over(game.Result{Score: 5, Undos: s.undos})
+}
+func (s scorer) View() string { return "playing" }
+
+// counter is a game that counts keys until q is pressed, and can be suspended.
+type counter struct{ keys int }
+
+func (c counter) Init() tea.Cmd { return nil }
+func (c counter) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		if msg.String() == "q" {
+			return c, tea.Quit
+		}
+		c.keys++
+	}
+	return c, nil
+}
+func (c counter) View() string { return "playing" }
+func (c counter) Suspend() any { return c.keys }
+
+func init() {
+	game.Register(game.Game{
+		ID:   "quitter",
+		Name: "quitter",
+		New:  func(game.Options) tea.Model { return quitter{} },
+	})
+	game.Register(game.Game{
+		ID:   "sequencer",
+		Name: "sequencer",
+		New:  func(game.Options) tea.Model { return sequencer{} },
+	})
+
+	game.Register(game.Game{
+		ID:         "scorer",
+		Name:       "scorer",
+		HighScores: true,
+		New:        func(game.Options) tea.Model { return scorer{} },
+	})
+
+	game.Register(game.Game{
+		ID:    "challenge",
+		Name:  "challenge",
+		New:   func(game.Options) tea.Model { return scorer{} },
+		Daily: func(game.Options) tea.Model { return scorer{} },
+	})
+
+	game.Register(game.Game{
+		ID:    "abandoned",
+		Name:  "abandoned",
+		New:   func(game.Options) tea.Model { return quitter{} },
+		Daily: func(game.Options) tea.Model { return quitter{} },
+	})
+
+	game.Register(game.Game{
+		ID:   "counter",
+		Name: "counter",
+		New:  func(game.Options) tea.Model { return counter{} },
+		Resume: func(data []byte) (tea.Model, error) {
+			var c counter
+			err := json.Unmarshal(data, &c.keys)
+			return c, err
+		},
+	})
+}
+
+func run(t *testing.T, m tea.Model, keys ...string) tea.Model {
+	t.Helper()
+
+	in, out := io.Pipe()
+	p := tea.NewProgram(m, tea.WithInput(in), tea.WithOutput(io.Discard))
+
+	go func() {
+		for _, k := range keys {
+			time.Sleep(50 * time.Millisecond)
+			out.Write([]byte(k))
+		}
+	}()
+
+	done := make(chan tea.Model)
+	go func() {
+		final, err := p.Run()
+		if err != nil {
+			t.Error(err)
+		}
+		done <- final
+	}()
+
+	select {
+	case final := <-done:
+		return final
+	case <-time.After(5 * time.Second):
+		p.Kill()
+		t.Fatal("program did not exit")
+		return nil
+	}
+}
+
+func TestGameReturnsToMenu(t *testing.T) {
+	m := New()
+	*m.selected = "quitter"
+	m.menu = m.newMenu()
+
+	// Pick the game, leave it with any key, then leave gg.
+	final := run(t, m, "\r", "x", "\x03").(Model)
+
+	if final.game != nil {
+		t.Fatal("expected the game to have been left")
+	}
+
+	if final.session != 1 {
+		t.Errorf("expected one game to have been played, got %d", final.session)
+	}
+
+	if *final.selected != "quitter" {
+		t.Errorf("expected the menu to remember quitter, got %q", *final.selected)
+	}
+}
+
+func TestPlayExitsAfterGame(t *testing.T) {
+	g, _ := game.Lookup("quitter")
+	final := run(t, Play(g, quitter{}), "x").(Model)
+
+	if final.game != nil {
+		t.Fatal("expected the game to have been left")
+	}
+}
+
+func TestSequenceReturnsToMenu(t *testing.T) {
+	m := New()
+	*m.selected = "sequencer"
+	m.menu = m.newMenu()
+
+	// Pick the game, leave it with any key, then leave gg. Had the quit at
+	// the end of the sequence ended gg, the last key would be left over.
+	final := run(t, m, "\r", "x", "\x03").(Model)
+
+	if final.game != nil || final.menu == nil {
+		t.Fatal("expected the game to have been left for the menu")
+	}
+}
+
+func TestStaleProgramMessagesAreDropped(t *testing.T) {
+	m := New()
+	m.game = quitter{}
+	cmd := m.wrap(tea.ClearScreen)
+	m.session++
+
+	_, cmd = m.Update(cmd())
+	if cmd != nil {
+		t.Error("expected a message of the program from an earlier game to be dropped")
+	}
+}
+
+func TestHighScoreIsSaved(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	g, _ := game.Lookup("scorer")
+
+	// End and leave the game, enter initials, then dismiss the leaderboard.
+	run(t, Play(g, scorer{}), "x", "q", "ab", "\r", "x")
+
+	board, err := scores.Board("scorer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(board) != 1 || board[0].Name != "AB" || board[0].Score != 5 {
+		t.Errorf("expected AB with 5 on the leaderboard, got %v", board)
+	}
+
+	played, err := stats.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := played["scorer"]; s == nil || s.Played != 1 || s.Scores.Best != 5 {
+		t.Errorf("expected the round to be added to the stats, got %+v", s)
+	}
+}
+
+func TestUndosAreSavedWithTheScore(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	g, _ := game.Lookup("scorer")
+	run(t, Play(g, scorer{undos: 2}), "x", "q", "ab", "\r", "x")
+
+	board, err := scores.Board("scorer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(board) != 1 || board[0].Undos != 2 {
+		t.Errorf("expected the score to have been reached with 2 undos, got %v", board)
+	}
+}
+
+func TestSuspendedGameContinues(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	g, _ := game.Lookup("counter")
+	run(t, Play(g, counter{}), "a", "b", "q")
+
+	// The suspended game is the first option of the menu.
+	final := run(t, New(), "\r", "c", "q", "\x03").(Model)
+
+	if final.session != 1 {
+		t.Fatalf("expected the suspended game to have been continued, got %d sessions", final.session)
+	}
+
+	data, err := save.Load("counter")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "3" {
+		t.Errorf("expected 3 keys to have been counted, got %s", data)
+	}
+}
+
+func TestDailyResultIsRecorded(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	g, _ := game.Lookup("challenge")
+
+	// End and leave the game, then dismiss the shared text.
+	run(t, Daily(g), "x", "q", "x")
+
+	r, played, err := daily.Lookup(daily.Today(), "challenge")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !played || r.Score != 5 {
+		t.Fatalf("expected today's result to be 5, got %v, %v", r, played)
+	}
+
+	// The challenge can't be played twice, its result is shown instead.
+	final := run(t, Daily(g), "x").(Model)
+	if final.session != 0 {
+		t.Errorf("expected no game to have been started, got %d sessions", final.session)
+	}
+}
+
+func TestAbandonedDailyIsRecorded(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	g, _ := game.Lookup("abandoned")
+
+	// Leave the game before it's over, then dismiss the shared text.
+	run(t, Daily(g), "x", "x")
+
+	r, played, err := daily.Lookup(daily.Today(), "abandoned")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !played || r.Summary != "abandoned" {
+		t.Errorf("expected today's round to have been abandoned, got %v, %v", r, played)
+	}
+}
+
+func TestDailyIsRecordedOnExit(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	g, _ := game.Lookup("abandoned")
+	run(t, Daily(g), "\x03")
+
+	if _, played, err := daily.Lookup(daily.Today(), "abandoned"); err != nil || !played {
+		t.Errorf("expected today's round to have been recorded, got %v, %v", played, err)
+	}
+}
+
+type tickMsg struct{}
+type startMsg struct{}
+
+// ticker is a real-time game that writes down every message it gets, along
+// with a random number for every tick, until q is pressed.
+type ticker struct {
+	opts  game.Options
+	trace *strings.Builder
+}
+
+func (t ticker) Init() tea.Cmd {
+	return tea.Batch(game.Tick(10*time.Millisecond, tickMsg{}), func() tea.Msg { return startMsg{} })
+}
+
+func (t ticker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case startMsg:
+		t.trace.WriteString("start ")
+	case tickMsg:
+		fmt.Fprintf(t.trace, "%d ", t.opts.Rand.IntN(100))
+		return t, game.Tick(10*time.Millisecond, tickMsg{})
+	case tea.KeyMsg:
+		if msg.String() == "q" {
+			return t, tea.Quit
+		}
+		t.trace.WriteString(msg.String() + " ")
+	}
+	return t, nil
+}
+
+func (t ticker) View() string { return t.trace.String() }
+
+func TestRecordingReplays(t *testing.T) {
+	g := game.Game{ID: "ticker", Name: "ticker", Recordable: true}
+	newTicker := func() ticker {
+		return ticker{game.Seeded(7), new(strings.Builder)}
+	}
+
+	recorded := newTicker()
+	rec := replay.New(g.ID, nil)
+	run(t, Record(g, recorded, rec), "a", "b", "q")
+
+	if !strings.Contains(recorded.View(), "a ") || strings.Count(recorded.View(), " ") < 5 {
+		t.Fatalf("expected keys and ticks to have been played, got %q", recorded.View())
+	}
+
+	var p tea.Model = replay.NewPlayer(rec, newTicker(), 1)
+	for range rec.Events {
+		p, _ = p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'.'}})
+	}
+
+	if got := p.View(); !strings.HasPrefix(got, recorded.View()+"\n") {
+		t.Errorf("expected the replay to show\n%q\ngot\n%q", recorded.View(), got)
+	}
+}
+
+func TestKeysAreShown(t *testing.T) {
+	g := game.Game{ID: "snake-keys", Name: "snake keys"}
+	game.Register(game.Game{
+		ID:   g.ID,
+		Name: g.Name,
+		New:  func(game.Options) tea.Model { return counter{} },
+		Keys: []game.Binding{{Action: "turn", Keys: []string{"t"}, Help: "turn around"}},
+	})
+
+	m := tea.Model(Play(g, counter{}))
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(game.HelpKey)})
+	if view := m.View(); !strings.Contains(view, "turn around") {
+		t.Fatalf("expected the keys to be shown, got %q", view)
+	}
+
+	// Keys don't reach the game while its keys are shown.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+
+	if m.View() != "playing" {
+		t.Fatalf("expected the game to be shown again, got %q", m.View())
+	}
+	if keys := m.(Model).game.(counter).keys; keys != 1 {
+		t.Errorf("expected the game to get one key, got %d", keys)
+	}
+}
+
+func TestGameIsCentered(t *testing.T) {
+	g, _ := game.Lookup("counter")
+
+	m := tea.Model(Play(g, counter{}))
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 11, Height: 3})
+	if lines := strings.Split(m.View(), "\n"); len(lines) != 3 || lines[1] != "  playing  " {
+		t.Fatalf("expected the game in the middle of the terminal, got %q", m.View())
+	}
+
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 5, Height: 3})
+	if view := m.View(); !strings.Contains(view, "terminal too small, need 7x1") {
+		t.Errorf("expected the game not to be drawn in a narrower terminal, got %q", view)
+	}
+}
+
+func TestAchievementIsToasted(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	m := tea.Model(Play(game.Game{ID: "twenty48", Name: "2048"}, counter{}))
+	_, cmd := m.Update(gameMsg{0, "0", game.EventMsg{Event: game.Event{Name: "tile", Value: 2048}}})
+	if cmd == nil {
+		t.Fatal("expected the event to unlock an achievement")
+	}
+
+	msg, ok := cmd().(achievements.UnlockedMsg)
+	if !ok || msg.Err != nil || len(msg.Achievements) != 1 {
+		t.Fatalf("expected 2048 to be unlocked, got %+v", msg)
+	}
+
+	m, _ = m.Update(msg)
+	if view := m.View(); !strings.Contains(view, "achievement unlocked: 2048") || !strings.Contains(view, "playing") {
+		t.Fatalf("expected the toast over the game, got %q", view)
+	}
+
+	m, _ = m.Update(toastDoneMsg{m.(Model).toastID})
+	if view := m.View(); view != "playing" {
+		t.Errorf("expected the toast to be gone, got %q", view)
+	}
+}
@@ -0,0 +1,244 @@
+package replay
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// frameInterval is the time between two updates of the replay clock.
+const frameInterval = 50 * time.Millisecond
+
+// minSpeed and maxSpeed bound the playback speed.
+const (
+	minSpeed = 0.25
+	maxSpeed = 64
+)
+
+var teaPkgPath = reflect.TypeOf(tea.QuitMsg{}).PkgPath()
+
+type frameMsg struct{}
+
+// Player plays a recording back. It sends the recorded events to the game
+// when their time has come on its own clock, which runs at the chosen speed
+// and can be paused or stepped through one game message at a time. The keys
+// pressed while it runs control the replay and never reach the game.
+type Player struct {
+	rec  *Recording
+	game tea.Model
+
+	// pending holds the messages produced by the game's commands until the
+	// recording sends them, by the ID of the command.
+	pending map[string]tea.Msg
+	cmds    int
+
+	next   int
+	now    time.Duration
+	speed  float64
+	paused bool
+
+	// started is set once the game's Init command has run, and quit once
+	// the game has asked to quit.
+	started bool
+	quit    bool
+
+	// missing counts the recorded messages that the game never produced,
+	// which means that the replay has gone out of sync.
+	missing int
+
+	status lipgloss.Style
+
+	// size is the size of the player's terminal, which may differ from the
+	// one the game was recorded in.
+	size tea.WindowSizeMsg
+}
+
+// NewPlayer returns a player for rec, which replays into model. The model must
+// have been built from the recording's arguments and not have been started.
+func NewPlayer(rec *Recording, model tea.Model, speed float64) Player {
+	return Player{
+		rec:     rec,
+		game:    model,
+		pending: map[string]tea.Msg{},
+		speed:   min(max(speed, minSpeed), maxSpeed),
+		status:  theme.Current().Muted,
+	}
+}
+
+func (p Player) Init() tea.Cmd {
+	// Init can't keep what the game's Init command produces, so the game
+	// is started on the first frame.
+	return func() tea.Msg { return frameMsg{} }
+}
+
+func (p Player) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// The game gets the recorded sizes instead.
+		p.size = msg
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return p, tea.Quit
+		case " ", "p":
+			p.paused = !p.paused
+		case ".", "right":
+			p.paused = true
+			p.start()
+			p.step()
+		case "+", "=":
+			p.speed = min(p.speed*2, maxSpeed)
+		case "-":
+			p.speed = max(p.speed/2, minSpeed)
+		}
+	case frameMsg:
+		if !p.started {
+			p.start()
+		} else if !p.paused {
+			p.now += time.Duration(float64(frameInterval) * p.speed)
+			p.play()
+		}
+
+		return p, tea.Tick(frameInterval, func(time.Time) tea.Msg {
+			return frameMsg{}
+		})
+	}
+
+	return p, nil
+}
+
+func (p Player) View() string {
+	var b strings.Builder
+
+	b.WriteString(p.game.View())
+	b.WriteString("\n\n")
+
+	state := fmt.Sprintf("%gx", p.speed)
+	switch {
+	case p.done():
+		state = "end of replay"
+	case p.paused:
+		state += ", paused"
+	}
+
+	line := fmt.Sprintf("replay of %s: %s / %s, %s",
+		p.rec.Game, formatDuration(min(p.now, p.rec.Duration())), formatDuration(p.rec.Duration()), state)
+	if p.missing > 0 {
+		line += fmt.Sprintf(", out of sync (%d messages missing)", p.missing)
+	}
+	b.WriteString(p.status.Render(line))
+	b.WriteString("\n")
+	b.WriteString(p.status.Render("space: pause  .: step  +/-: speed  q: quit"))
+
+	return game.Place(p.size.Width, p.size.Height, b.String())
+}
+
+// start runs the game's Init command, unless it has already been run.
+func (p *Player) start() {
+	if !p.started {
+		p.started = true
+		p.run(p.game.Init())
+	}
+}
+
+func (p *Player) done() bool {
+	return p.quit || p.next >= len(p.rec.Events)
+}
+
+// play sends the events that are due by the player's clock.
+func (p *Player) play() {
+	for !p.done() && p.rec.Events[p.next].At <= p.now {
+		p.send(p.rec.Events[p.next])
+	}
+}
+
+// step sends the events up to and including the next message of the game
+// itself, which usually moves it on by one frame.
+func (p *Player) step() {
+	for !p.done() {
+		e := p.rec.Events[p.next]
+		p.now = max(p.now, e.At)
+		p.send(e)
+
+		if e.Msg != "" {
+			return
+		}
+	}
+}
+
+func (p *Player) send(e Event) {
+	p.next++
+
+	var msg tea.Msg
+	switch {
+	case e.Key != nil:
+		msg = tea.KeyMsg(*e.Key)
+	case e.Size != nil:
+		msg = tea.WindowSizeMsg{Width: e.Size.Width, Height: e.Size.Height}
+	case e.Focus != nil && *e.Focus:
+		msg = tea.FocusMsg{}
+	case e.Focus != nil:
+		msg = tea.BlurMsg{}
+	default:
+		var ok bool
+		msg, ok = p.pending[e.Msg]
+		if !ok {
+			p.missing++
+			return
+		}
+		delete(p.pending, e.Msg)
+	}
+
+	var cmd tea.Cmd
+	p.game, cmd = p.game.Update(msg)
+	p.run(cmd)
+}
+
+// run runs a command returned by the game right away and keeps its messages
+// until the recording sends them. Commands get their IDs the same way as while
+// recording, so that the IDs in the recording refer to them.
+func (p *Player) run(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+
+	p.collect(strconv.Itoa(p.cmds), cmd)
+	p.cmds++
+}
+
+func (p *Player) collect(id string, cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+
+	switch msg := cmd().(type) {
+	case nil:
+	case tea.QuitMsg:
+		p.quit = true
+	case tea.BatchMsg:
+		for i, c := range msg {
+			p.collect(CommandID(id, i), c)
+		}
+	case game.TickMsg:
+		p.pending[id] = msg.Msg
+	case game.OverMsg, game.EventMsg:
+		// Results and events are only of interest to the launcher, and
+		// a replay doesn't unlock anything.
+	default:
+		if reflect.TypeOf(msg).PkgPath() != teaPkgPath {
+			p.pending[id] = msg
+		}
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Truncate(100 * time.Millisecond).String()
+}
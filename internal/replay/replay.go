@@ -0,0 +1,187 @@
+// Package replay records rounds of real-time games and plays them back.
+//
+// A game is deterministic once its random numbers come from a seed, as long
+// as its model sees the same messages in the same order. A recording keeps the
+// arguments the game was started with, which include the seed, and a log of
+// every message its model received: keys and sizes are kept as they are,
+// while the messages produced by the game's own commands, such as its ticks,
+// are referred to by the command that produced them. A replay runs the
+// commands again to get those messages back.
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// version is the current version of the recording format. Recordings made by
+// a newer version of gg can't be replayed.
+const version = 1
+
+// Size is a terminal size, as sent with tea.WindowSizeMsg.
+type Size struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Event is a message received by the game, along with when it arrived.
+// Exactly one of Key, Size, Focus and Msg is set.
+type Event struct {
+	// At is the time since the start of the recording.
+	At time.Duration `json:"at"`
+
+	Key  *tea.Key `json:"key,omitempty"`
+	Size *Size    `json:"size,omitempty"`
+
+	// Focus is true when the terminal gained focus and false when it lost
+	// it.
+	Focus *bool `json:"focus,omitempty"`
+
+	// Msg is the ID of the command that produced the message.
+	Msg string `json:"msg,omitempty"`
+}
+
+// Recording is the log of a round of a game.
+type Recording struct {
+	Version int `json:"version"`
+
+	// Game is the ID of the game, and Args the command line arguments it
+	// was started with.
+	Game string   `json:"game"`
+	Args []string `json:"args"`
+
+	Events []Event `json:"events"`
+
+	start   time.Time
+	cmds    int
+	stopped bool
+}
+
+// New starts the recording of the game with the given ID, started with args.
+// The args must pin the seed, so that parsing them again builds the same
+// round.
+func New(game string, args []string) *Recording {
+	return &Recording{
+		Version: version,
+		Game:    game,
+		Args:    args,
+		start:   time.Now(),
+	}
+}
+
+// The methods used while recording do nothing on a nil Recording, so that the
+// host can call them whether or not it is recording.
+
+// Command returns the ID of the next command returned by the game. The host
+// must ask for one for every command other than nil that the game returns, in
+// order, and use CommandID for the commands in batches.
+func (r *Recording) Command() string {
+	if r == nil {
+		return ""
+	}
+
+	id := strconv.Itoa(r.cmds)
+	r.cmds++
+	return id
+}
+
+// CommandID returns the ID of the i-th command of the batch produced by the
+// command with the given ID.
+func CommandID(batch string, i int) string {
+	return batch + "." + strconv.Itoa(i)
+}
+
+// Input records msg if it is a key, a size or a change of focus sent to the
+// game. Other messages are ignored.
+func (r *Recording) Input(msg tea.Msg) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		key := tea.Key(msg)
+		r.add(Event{Key: &key})
+	case tea.WindowSizeMsg:
+		r.add(Event{Size: &Size{msg.Width, msg.Height}})
+	case tea.FocusMsg:
+		focus := true
+		r.add(Event{Focus: &focus})
+	case tea.BlurMsg:
+		focus := false
+		r.add(Event{Focus: &focus})
+	}
+}
+
+// Message records that the message produced by the command with the given ID
+// was sent to the game.
+func (r *Recording) Message(id string) {
+	r.add(Event{Msg: id})
+}
+
+func (r *Recording) add(e Event) {
+	if r == nil || r.stopped {
+		return
+	}
+
+	e.At = time.Since(r.start)
+	r.Events = append(r.Events, e)
+}
+
+// Stop ends the recording. Later events are ignored.
+func (r *Recording) Stop() {
+	if r != nil {
+		r.stopped = true
+	}
+}
+
+// Duration is the time from the start of the recording to its last event.
+func (r *Recording) Duration() time.Duration {
+	if len(r.Events) == 0 {
+		return 0
+	}
+
+	return r.Events[len(r.Events)-1].At
+}
+
+// Save writes the recording to the file at path.
+func (r *Recording) Save(path string) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Load reads the recording in the file at path.
+func Load(path string) (*Recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var r Recording
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("replay: reading %s: %w", path, err)
+	}
+
+	if r.Version > version {
+		return nil, fmt.Errorf("replay: %s was recorded by a newer version of gg", path)
+	}
+
+	for i, e := range r.Events {
+		n := 0
+		for _, set := range []bool{e.Key != nil, e.Size != nil, e.Focus != nil, e.Msg != ""} {
+			if set {
+				n++
+			}
+		}
+		if n != 1 {
+			return nil, fmt.Errorf("replay: reading %s: event %d is malformed", path, i)
+		}
+	}
+
+	return &r, nil
+}
@@ -0,0 +1,81 @@
+package replay
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSaveLoad(t *testing.T) {
+	rec := New("snake", []string{"-seed", "42"})
+	rec.Input(tea.WindowSizeMsg{Width: 80, Height: 24})
+	rec.Message(rec.Command())
+	rec.Input(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	rec.Input(tea.KeyMsg{Type: tea.KeyUp, Alt: true})
+	rec.Input(tea.BlurMsg{})
+	rec.Input(tea.FocusMsg{})
+	rec.Message(CommandID(rec.Command(), 1))
+	rec.Stop()
+	rec.Input(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if len(rec.Events) != 7 {
+		t.Fatalf("expected 7 events before the recording stopped, got %d", len(rec.Events))
+	}
+
+	path := filepath.Join(t.TempDir(), "round.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Game != "snake" || !reflect.DeepEqual(loaded.Args, rec.Args) {
+		t.Errorf("expected snake with %v, got %s with %v", rec.Args, loaded.Game, loaded.Args)
+	}
+	if !reflect.DeepEqual(loaded.Events, rec.Events) {
+		t.Errorf("expected the events to survive a round trip, got %+v", loaded.Events)
+	}
+	if loaded.Events[6].Msg != "1.1" {
+		t.Errorf("expected the last message to come from command 1.1, got %q", loaded.Events[6].Msg)
+	}
+	if *loaded.Events[4].Focus || !*loaded.Events[5].Focus {
+		t.Error("expected the terminal to have lost focus and gained it again")
+	}
+}
+
+func TestLoadRejectsBadRecordings(t *testing.T) {
+	for name, data := range map[string]string{
+		"newer":     `{"version": 2, "game": "snake", "events": []}`,
+		"malformed": `{"version": 1, "game": "snake", "events": [{"at": 1, "msg": "0", "size": {"width": 1, "height": 1}}]}`,
+		"empty":     `{"version": 1, "game": "snake", "events": [{"at": 1}]}`,
+		"not json":  `snake`,
+	} {
+		path := filepath.Join(t.TempDir(), "round.json")
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "replay:") {
+			t.Errorf("%s: expected an error, got %v", name, err)
+		}
+	}
+}
+
+func TestNilRecording(t *testing.T) {
+	var rec *Recording
+
+	// A launcher that isn't recording calls these all the time.
+	if id := rec.Command(); id != "" {
+		t.Errorf("expected no ID, got %q", id)
+	}
+	rec.Input(tea.KeyMsg{Type: tea.KeyEnter})
+	rec.Message("0")
+	rec.Stop()
+}
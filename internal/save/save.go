@@ -0,0 +1,118 @@
+// Package save suspends games to disk so that they can be continued later.
+// Every suspended game is kept in its own versioned JSON document in gg's data
+// directory, and a game is removed from disk once it has been continued.
+package save
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/storage"
+)
+
+// version is the current version of the save documents. Documents written by
+// a newer version of gg can't be continued.
+const version = 1
+
+// ErrNotFound is returned when a game has no suspended state.
+var ErrNotFound = errors.New("save: no suspended game")
+
+type document struct {
+	Version int             `json:"version"`
+	Game    string          `json:"game"`
+	Saved   time.Time       `json:"saved"`
+	State   json.RawMessage `json:"state"`
+}
+
+// Info describes a suspended game.
+type Info struct {
+	Game  string
+	Saved time.Time
+}
+
+func fileName(game string) string {
+	return "suspended-" + game + ".json"
+}
+
+// Store saves state as the suspended game with the given ID, replacing any
+// earlier one. The state must be encodable with encoding/json.
+func Store(game string, state any) error {
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("save: encoding %s: %w", game, err)
+	}
+
+	doc := document{
+		Version: version,
+		Game:    game,
+		Saved:   time.Now(),
+		State:   raw,
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return storage.Update(fileName(game), func([]byte) ([]byte, error) {
+		return data, nil
+	})
+}
+
+func read(game string) (document, error) {
+	var doc document
+
+	data, err := storage.Read(fileName(game))
+	if err != nil {
+		return doc, err
+	}
+
+	if data == nil {
+		return doc, ErrNotFound
+	}
+
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return doc, fmt.Errorf("save: reading %s: %w", game, err)
+	}
+
+	if doc.Version > version {
+		return doc, fmt.Errorf("save: %s was suspended by a newer version of gg", game)
+	}
+
+	return doc, nil
+}
+
+// Load returns the state of the suspended game with the given ID.
+func Load(game string) ([]byte, error) {
+	doc, err := read(game)
+	return doc.State, err
+}
+
+// Delete removes the suspended game with the given ID.
+func Delete(game string) error {
+	return storage.Remove(fileName(game))
+}
+
+// Latest returns the most recently suspended game out of the given IDs. The
+// boolean is false if none of them is suspended. Documents that can't be
+// continued, such as those written by a newer gg, are skipped.
+func Latest(games []string) (Info, bool) {
+	var latest Info
+	found := false
+
+	for _, game := range games {
+		doc, err := read(game)
+		if err != nil {
+			continue
+		}
+
+		if !found || doc.Saved.After(latest.Saved) {
+			latest = Info{Game: game, Saved: doc.Saved}
+			found = true
+		}
+	}
+
+	return latest, found
+}
@@ -0,0 +1,64 @@
+package save
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type state struct {
+	Grid [][]int `json:"grid"`
+}
+
+func TestStoreAndLoad(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	want := state{Grid: [][]int{{1, 2}, {3, 4}}}
+	if err := Store("sudoku", want); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := Load("sudoku")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got state
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Grid[1][0] != 3 || len(got.Grid) != 2 {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if err := Delete("sudoku"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load("sudoku"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after Delete, got %v", err)
+	}
+}
+
+func TestLatest(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	if _, ok := Latest([]string{"sudoku", "tetris"}); ok {
+		t.Fatal("expected nothing to be suspended")
+	}
+
+	Store("sudoku", state{})
+	time.Sleep(10 * time.Millisecond)
+	Store("tetris", state{})
+
+	info, ok := Latest([]string{"sudoku", "tetris", "twenty48"})
+	if !ok {
+		t.Fatal("expected a suspended game")
+	}
+
+	if info.Game != "tetris" {
+		t.Errorf("expected tetris to be the latest, got %s", info.Game)
+	}
+}
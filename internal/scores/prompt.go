@@ -0,0 +1,139 @@
+package scores
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxInitials is the number of characters a player can enter as their name.
+const maxInitials = 3
+
+type savedMsg struct {
+	board []Entry
+	rank  int
+	err   error
+}
+
+// Prompt is the screen shown after a round whose score made the leaderboard.
+// It asks for the player's initials, saves the entry and then shows the
+// updated leaderboard until a key is pressed.
+type Prompt struct {
+	game  string
+	title string
+	score int
+	undos int
+
+	initials []rune
+	saving   bool
+	saved    bool
+	board    []Entry
+	rank     int
+	err      error
+
+	highlight lipgloss.Style
+}
+
+// NewPrompt returns the initials screen for a score in the game with the given
+// ID, reached with the given number of undos. The title is the game's name as
+// shown to the player.
+func NewPrompt(game, title string, score, undos int) Prompt {
+	return Prompt{
+		game:      game,
+		title:     title,
+		score:     score,
+		undos:     undos,
+		highlight: theme.Current().Accent.Bold(true),
+	}
+}
+
+func (p Prompt) Init() tea.Cmd {
+	return nil
+}
+
+func (p Prompt) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case savedMsg:
+		p.saving = false
+		p.saved = true
+		p.board = msg.board
+		p.rank = msg.rank
+		p.err = msg.err
+	case tea.KeyMsg:
+		if p.saved {
+			return p, tea.Quit
+		}
+
+		if p.saving {
+			return p, nil
+		}
+
+		switch msg.Type {
+		case tea.KeyEsc:
+			return p, tea.Quit
+		case tea.KeyEnter:
+			if len(p.initials) > 0 {
+				p.saving = true
+				return p, p.save()
+			}
+		case tea.KeyBackspace:
+			if len(p.initials) > 0 {
+				p.initials = p.initials[:len(p.initials)-1]
+			}
+		case tea.KeyRunes:
+			for _, r := range msg.Runes {
+				if len(p.initials) < maxInitials && r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+					p.initials = append(p.initials, unicode.ToUpper(r))
+				}
+			}
+		}
+	}
+
+	return p, nil
+}
+
+func (p Prompt) save() tea.Cmd {
+	entry := Entry{
+		Name:  string(p.initials),
+		Score: p.score,
+		Date:  time.Now(),
+		Undos: p.undos,
+	}
+
+	return func() tea.Msg {
+		board, rank, err := Add(p.game, entry)
+		return savedMsg{board, rank, err}
+	}
+}
+
+func (p Prompt) View() string {
+	s := fmt.Sprintf("New high score in %s!\n\nScore: %d\n\n", p.title, p.score)
+
+	if !p.saved {
+		name := string(p.initials) + strings.Repeat("_", maxInitials-len(p.initials))
+		s += "Enter your initials: " + p.highlight.Render(name) + "\n\n"
+		s += "enter to save, esc to skip\n"
+		return s
+	}
+
+	if p.err != nil {
+		s += fmt.Sprintf("The score could not be saved: %v\n", p.err)
+	} else {
+		for i, e := range p.board {
+			line := Format(i+1, e)
+			if i+1 == p.rank {
+				line = p.highlight.Render(line)
+			}
+			s += line + "\n"
+		}
+	}
+
+	s += "\nPress any key to continue.\n"
+	return s
+}
@@ -0,0 +1,155 @@
+// Package scores keeps a leaderboard for every game that has a score. The
+// leaderboards live in a single versioned file in gg's data directory.
+package scores
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/storage"
+)
+
+const (
+	fileName = "scores.json"
+
+	// version is the current version of the scores file. Files written by
+	// a newer version of gg are refused rather than overwritten.
+	version = 1
+
+	// Size is the number of entries kept on each leaderboard.
+	Size = 10
+)
+
+// errNotRanked aborts an update when the entry didn't make the leaderboard.
+var errNotRanked = errors.New("scores: not ranked")
+
+// Entry is a single line of a leaderboard.
+type Entry struct {
+	Name  string    `json:"name"`
+	Score int       `json:"score"`
+	Date  time.Time `json:"date"`
+
+	// Undos is the number of moves taken back to reach the score.
+	Undos int `json:"undos,omitempty"`
+}
+
+type file struct {
+	Version int                `json:"version"`
+	Boards  map[string][]Entry `json:"boards"`
+}
+
+func decode(data []byte) (file, error) {
+	f := file{Version: version}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &f); err != nil {
+			return f, fmt.Errorf("scores: reading %s: %w", fileName, err)
+		}
+	}
+
+	if f.Version > version {
+		return f, fmt.Errorf("scores: %s was written by a newer version of gg", fileName)
+	}
+
+	if f.Boards == nil {
+		f.Boards = map[string][]Entry{}
+	}
+
+	return f, nil
+}
+
+func load() (file, error) {
+	data, err := storage.Read(fileName)
+	if err != nil {
+		return file{}, err
+	}
+
+	return decode(data)
+}
+
+// All returns every leaderboard, keyed by game ID.
+func All() (map[string][]Entry, error) {
+	f, err := load()
+	return f.Boards, err
+}
+
+// Board returns the leaderboard of a game, best score first.
+func Board(game string) ([]Entry, error) {
+	f, err := load()
+	return f.Boards[game], err
+}
+
+// Qualifies reports whether score would make it onto the leaderboard of game.
+func Qualifies(game string, score int) (bool, error) {
+	board, err := Board(game)
+	if err != nil {
+		return false, err
+	}
+
+	return rank(board, score) <= Size, nil
+}
+
+// Add puts an entry on the leaderboard of game. It returns the updated
+// leaderboard and the entry's rank on it, starting at 1, or 0 if the score
+// wasn't good enough.
+func Add(game string, e Entry) ([]Entry, int, error) {
+	var board []Entry
+	var r int
+
+	err := storage.Update(fileName, func(data []byte) ([]byte, error) {
+		f, err := decode(data)
+		if err != nil {
+			return nil, err
+		}
+
+		board = f.Boards[game]
+		r = rank(board, e.Score)
+		if r > Size {
+			r = 0
+			return nil, errNotRanked
+		}
+
+		board = append(board[:r-1], append([]Entry{e}, board[r-1:]...)...)
+		if len(board) > Size {
+			board = board[:Size]
+		}
+
+		f.Version = version
+		f.Boards[game] = board
+
+		return json.MarshalIndent(f, "", "  ")
+	})
+	if errors.Is(err, errNotRanked) {
+		return board, 0, nil
+	}
+
+	return board, r, err
+}
+
+// rank returns the position, starting at 1, that score would take on board.
+// Ties go below the existing entries, so the first to reach a score keeps
+// the higher place.
+func rank(board []Entry, score int) int {
+	for i, e := range board {
+		if score > e.Score {
+			return i + 1
+		}
+	}
+
+	return len(board) + 1
+}
+
+// Format returns a leaderboard line for the entry at the given rank.
+func Format(rank int, e Entry) string {
+	line := fmt.Sprintf("%2d. %-3s %8d   %s", rank, e.Name, e.Score, e.Date.Local().Format(time.DateOnly))
+	switch {
+	case e.Undos == 1:
+		line += "   1 undo"
+	case e.Undos > 1:
+		line += fmt.Sprintf("   %d undos", e.Undos)
+	}
+
+	return line
+}
@@ -0,0 +1,110 @@
+package scores
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/storage"
+)
+
+func TestAdd(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	for i, score := range []int{10, 30, 20, 30} {
+		_, rank, err := Add("snake", Entry{Name: string(rune('A' + i)), Score: score, Date: time.Now()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rank == 0 {
+			t.Fatalf("expected score %d to be ranked", score)
+		}
+	}
+
+	board, err := Board("snake")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The second 30 was entered later, so it goes below the first one.
+	want := []string{"B", "D", "C", "A"}
+	for i, name := range want {
+		if board[i].Name != name {
+			t.Fatalf("expected %v on the board, got %v", want, board)
+		}
+	}
+}
+
+func TestBoardIsBounded(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	for i := range Size + 5 {
+		if _, _, err := Add("dodger", Entry{Name: "AAA", Score: i + 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	board, _ := Board("dodger")
+	if len(board) != Size {
+		t.Fatalf("expected %d entries, got %d", Size, len(board))
+	}
+
+	if board[0].Score != Size+5 || board[Size-1].Score != 6 {
+		t.Errorf("expected the best %d scores to be kept, got %v", Size, board)
+	}
+
+	if ok, _ := Qualifies("dodger", 6); ok {
+		t.Error("expected a tie with the last entry not to qualify")
+	}
+
+	if ok, _ := Qualifies("dodger", 7); !ok {
+		t.Error("expected a better score to qualify")
+	}
+
+	_, rank, err := Add("dodger", Entry{Name: "ZZZ", Score: 1})
+	if err != nil || rank != 0 {
+		t.Errorf("expected a low score not to be ranked, got rank %d, %v", rank, err)
+	}
+}
+
+func TestNewerVersionIsRefused(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	err := storage.Update(fileName, func([]byte) ([]byte, error) {
+		return []byte(`{"version": 99, "boards": {}}`), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Board("snake"); err == nil {
+		t.Error("expected an error for a file from a newer gg")
+	}
+
+	if _, _, err := Add("snake", Entry{Name: "AAA", Score: 1}); err == nil {
+		t.Error("expected a file from a newer gg not to be overwritten")
+	}
+}
+
+func TestUndosAreKept(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	if _, _, err := Add("twenty48", Entry{Name: "AAA", Score: 512, Undos: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	board, err := Board("twenty48")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if board[0].Undos != 2 {
+		t.Fatalf("expected the undos to be kept, got %v", board)
+	}
+
+	if line := Format(1, board[0]); !strings.HasSuffix(line, "2 undos") {
+		t.Errorf("expected the line to show the undos, got %q", line)
+	}
+	if line := Format(1, Entry{Name: "BBB", Score: 256}); strings.Contains(line, "undo") {
+		t.Errorf("expected no undos on the line, got %q", line)
+	}
+}
@@ -0,0 +1,236 @@
+package stats
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// minTries is the number of tries an item needs before its error rate
+	// is shown, so that a single slip doesn't top the list.
+	minTries = 5
+
+	// shownTallies is the number of items with the most errors shown.
+	shownTallies = 5
+)
+
+type loadedMsg struct {
+	games map[string]*Game
+	err   error
+}
+
+// Screen shows the stats of one game at a time, along with a sparkline of the
+// history of each score and stat. The player flips through the games that have
+// been played with the arrow keys.
+type Screen struct {
+	// start is the ID of the game to show first, if any.
+	start string
+
+	loaded bool
+	games  []game.Game
+	stats  map[string]*Game
+	shown  int
+	err    error
+
+	title lipgloss.Style
+	muted lipgloss.Style
+	spark lipgloss.Style
+}
+
+// NewScreen returns the stats screen, starting on the game with the given ID,
+// or on the first game played if id is empty.
+func NewScreen(id string) Screen {
+	t := theme.Current()
+
+	return Screen{
+		start: id,
+		title: t.Accent.Bold(true),
+		muted: t.Muted,
+		spark: t.Players[0],
+	}
+}
+
+func (s Screen) Init() tea.Cmd {
+	return func() tea.Msg {
+		games, err := All()
+		return loadedMsg{games, err}
+	}
+}
+
+func (s Screen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case loadedMsg:
+		s.loaded = true
+		s.stats = msg.games
+		s.err = msg.err
+
+		for _, g := range game.All() {
+			if _, ok := s.stats[g.ID]; ok {
+				if g.ID == s.start {
+					s.shown = len(s.games)
+				}
+				s.games = append(s.games, g)
+			}
+		}
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return s, tea.Quit
+		case "left", "h", "shift+tab":
+			if len(s.games) > 0 {
+				s.shown = (s.shown + len(s.games) - 1) % len(s.games)
+			}
+		case "right", "l", "tab":
+			if len(s.games) > 0 {
+				s.shown = (s.shown + 1) % len(s.games)
+			}
+		}
+	}
+
+	return s, nil
+}
+
+func (s Screen) View() string {
+	if !s.loaded {
+		return ""
+	}
+
+	var b strings.Builder
+	switch {
+	case s.err != nil:
+		fmt.Fprintf(&b, "The stats are unavailable: %v\n", s.err)
+	case len(s.games) == 0:
+		b.WriteString("No game has been played to the end yet.\n")
+	default:
+		s.writeGame(&b, s.games[s.shown], s.stats[s.games[s.shown].ID])
+	}
+
+	b.WriteString("\n" + s.muted.Render("left/right: other games  q: quit"))
+	return b.String()
+}
+
+func (s Screen) writeGame(b *strings.Builder, g game.Game, st *Game) {
+	b.WriteString(s.title.Render(g.Title()))
+	b.WriteString(s.muted.Render(fmt.Sprintf("  %d/%d", s.shown+1, len(s.games))))
+	b.WriteString("\n\n")
+
+	line := func(label, value string) {
+		fmt.Fprintf(b, "%-16s%s\n", label, value)
+	}
+
+	line("rounds played", strconv.Itoa(st.Played))
+
+	if st.Wins+st.Losses+st.Draws > 0 {
+		if g.Players > 1 {
+			line("first player", fmt.Sprintf("%d won", st.Wins))
+			line("second player", fmt.Sprintf("%d won", st.Losses))
+			line("draws", strconv.Itoa(st.Draws))
+		} else {
+			line("won", fmt.Sprintf("%d (%s)", st.Wins, percent(st.Wins, st.Wins+st.Losses+st.Draws)))
+			line("lost", strconv.Itoa(st.Losses))
+			line("drawn", strconv.Itoa(st.Draws))
+		}
+	}
+
+	measure := func(name string, m *Measure) {
+		line(name, fmt.Sprintf("best %s, average %s", m.format(m.Best), m.format(m.Average())))
+		if len(m.History) > 1 {
+			line("", s.spark.Render(Sparkline(m.History)))
+		}
+	}
+
+	if st.Scores != nil {
+		measure("score", st.Scores)
+	}
+
+	names := make([]string, 0, len(st.Stats))
+	for name := range st.Stats {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		measure(name, st.Stats[name])
+	}
+
+	if worst := worstTallies(st.Tallies); len(worst) > 0 {
+		line("most errors", strings.Join(worst, "  "))
+	}
+}
+
+// worstTallies returns the items with the highest error rates, along with
+// their rates, e.g. "e 12%".
+func worstTallies(tallies map[string]game.Tally) []string {
+	type rate struct {
+		item string
+		game.Tally
+	}
+
+	var rates []rate
+	for item, t := range tallies {
+		if t.Tries >= minTries && t.Errors > 0 {
+			rates = append(rates, rate{item, t})
+		}
+	}
+
+	slices.SortFunc(rates, func(a, b rate) int {
+		// a.Errors/a.Tries > b.Errors/b.Tries, without dividing.
+		if c := cmp.Compare(b.Errors*a.Tries, a.Errors*b.Tries); c != 0 {
+			return c
+		}
+		return strings.Compare(a.item, b.item)
+	})
+
+	var worst []string
+	for _, r := range rates[:min(len(rates), shownTallies)] {
+		worst = append(worst, r.item+" "+percent(r.Errors, r.Tries))
+	}
+	return worst
+}
+
+func percent(n, total int) string {
+	return fmt.Sprintf("%.0f%%", 100*float64(n)/float64(total))
+}
+
+func (m Measure) format(v float64) string {
+	if m.Seconds {
+		d := time.Duration(math.Round(v)) * time.Second
+		return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+	}
+
+	return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64)
+}
+
+// sparks are the bars of a sparkline, from the lowest to the highest.
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values as a line of bars, scaled from the lowest value to
+// the highest.
+func Sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	lo, hi := slices.Min(values), slices.Max(values)
+
+	line := make([]rune, len(values))
+	for i, v := range values {
+		level := len(sparks) / 2
+		if hi > lo {
+			level = int((v - lo) / (hi - lo) * float64(len(sparks)-1))
+		}
+		line[i] = sparks[level]
+	}
+
+	return string(line)
+}
@@ -0,0 +1,182 @@
+// Package stats keeps the player's lifetime stats of every game, built from
+// the results the games report at the end of each round. The stats live in a
+// single versioned file in gg's data directory.
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Kaamkiya/gg/internal/game"
+	"github.com/Kaamkiya/gg/internal/storage"
+)
+
+const (
+	fileName = "stats.json"
+
+	// version is the current version of the stats file. Files written by a
+	// newer version of gg are refused rather than overwritten.
+	version = 1
+
+	// historySize is the number of recent values kept of every score and
+	// stat, to show how they went over time.
+	historySize = 30
+)
+
+// Game holds the lifetime stats of a game.
+type Game struct {
+	// Played counts the rounds that were played to the end.
+	Played int `json:"played"`
+
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Draws  int `json:"draws"`
+
+	// Scores is the score of the rounds of games with high scores.
+	Scores *Measure `json:"scores,omitempty"`
+
+	// Stats are the stats reported by the rounds, by name.
+	Stats map[string]*Measure `json:"stats,omitempty"`
+
+	// Tallies add up the tallies reported by the rounds, by item.
+	Tallies map[string]game.Tally `json:"tallies,omitempty"`
+}
+
+// Measure is the lifetime record of a score or stat.
+type Measure struct {
+	Count int     `json:"count"`
+	Total float64 `json:"total"`
+	Best  float64 `json:"best"`
+
+	// History holds the most recent values, the latest last.
+	History []float64 `json:"history"`
+
+	LowerIsBetter bool `json:"lower_is_better,omitempty"`
+	Seconds       bool `json:"seconds,omitempty"`
+}
+
+// Average returns the average of every value of the measure.
+func (m Measure) Average() float64 {
+	if m.Count == 0 {
+		return 0
+	}
+
+	return m.Total / float64(m.Count)
+}
+
+func (m *Measure) add(v float64) {
+	better := v > m.Best
+	if m.LowerIsBetter {
+		better = v < m.Best
+	}
+	if m.Count == 0 || better {
+		m.Best = v
+	}
+
+	m.Count++
+	m.Total += v
+	m.History = append(m.History, v)
+	if len(m.History) > historySize {
+		m.History = m.History[len(m.History)-historySize:]
+	}
+}
+
+type file struct {
+	Version int              `json:"version"`
+	Games   map[string]*Game `json:"games"`
+}
+
+func decode(data []byte) (file, error) {
+	f := file{Version: version}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &f); err != nil {
+			return f, fmt.Errorf("stats: reading %s: %w", fileName, err)
+		}
+	}
+
+	if f.Version > version {
+		return f, fmt.Errorf("stats: %s was written by a newer version of gg", fileName)
+	}
+
+	if f.Games == nil {
+		f.Games = map[string]*Game{}
+	}
+
+	return f, nil
+}
+
+// All returns the stats of every game that has been played, keyed by game ID.
+func All() (map[string]*Game, error) {
+	data, err := storage.Read(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := decode(data)
+	return f.Games, err
+}
+
+// Record adds the result of a round of g to its stats.
+func Record(g game.Game, r game.Result) error {
+	return storage.Update(fileName, func(data []byte) ([]byte, error) {
+		f, err := decode(data)
+		if err != nil {
+			return nil, err
+		}
+
+		s, ok := f.Games[g.ID]
+		if !ok {
+			s = &Game{}
+			f.Games[g.ID] = s
+		}
+		s.add(g, r)
+
+		f.Version = version
+		return json.MarshalIndent(f, "", "  ")
+	})
+}
+
+func (s *Game) add(g game.Game, r game.Result) {
+	s.Played++
+
+	switch r.Outcome {
+	case game.Win:
+		s.Wins++
+	case game.Loss:
+		s.Losses++
+	case game.Draw:
+		s.Draws++
+	}
+
+	if g.HighScores {
+		if s.Scores == nil {
+			s.Scores = &Measure{}
+		}
+		s.Scores.add(float64(r.Score))
+	}
+
+	for _, stat := range r.Stats {
+		if s.Stats == nil {
+			s.Stats = map[string]*Measure{}
+		}
+
+		m, ok := s.Stats[stat.Name]
+		if !ok {
+			m = &Measure{LowerIsBetter: stat.LowerIsBetter, Seconds: stat.Seconds}
+			s.Stats[stat.Name] = m
+		}
+		m.add(stat.Value)
+	}
+
+	for item, t := range r.Tallies {
+		if s.Tallies == nil {
+			s.Tallies = map[string]game.Tally{}
+		}
+
+		total := s.Tallies[item]
+		total.Tries += t.Tries
+		total.Errors += t.Errors
+		s.Tallies[item] = total
+	}
+}
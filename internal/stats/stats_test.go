@@ -0,0 +1,105 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/game"
+)
+
+func TestRecord(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	snake := game.Game{ID: "snake", HighScores: true}
+	sudoku := game.Game{ID: "sudoku"}
+
+	for _, score := range []int{4, 9, 6} {
+		if err := Record(snake, game.Result{Score: score}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, secs := range []float64{300, 240, 270} {
+		r := game.Result{
+			Outcome: game.Win,
+			Stats:   []game.Stat{{Name: "solve time", Value: secs, LowerIsBetter: true, Seconds: true}},
+			Tallies: map[string]game.Tally{"5": {Tries: 2, Errors: 1}},
+		}
+		if err := Record(sudoku, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	games, err := All()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := games["snake"]
+	if s.Played != 3 || s.Scores.Best != 9 || s.Scores.Average() != 19.0/3 {
+		t.Errorf("expected 3 rounds with a best score of 9, got %+v", s.Scores)
+	}
+	if !reflect.DeepEqual(s.Scores.History, []float64{4, 9, 6}) {
+		t.Errorf("expected the scores in order, got %v", s.Scores.History)
+	}
+
+	s = games["sudoku"]
+	if s.Scores != nil {
+		t.Error("expected no scores for a game without high scores")
+	}
+	if s.Wins != 3 || s.Losses != 0 {
+		t.Errorf("expected 3 wins, got %d wins and %d losses", s.Wins, s.Losses)
+	}
+	if solve := s.Stats["solve time"]; solve.Best != 240 || solve.format(solve.Best) != "4:00" {
+		t.Errorf("expected the best time to be the lowest, got %+v", solve)
+	}
+	if got := s.Tallies["5"]; got != (game.Tally{Tries: 6, Errors: 3}) {
+		t.Errorf("expected the tallies to add up, got %+v", got)
+	}
+}
+
+func TestHistoryIsCapped(t *testing.T) {
+	var m Measure
+	for i := range historySize + 5 {
+		m.add(float64(i))
+	}
+
+	if len(m.History) != historySize || m.History[0] != 5 {
+		t.Errorf("expected the last %d values, got %v", historySize, m.History)
+	}
+	if m.Count != historySize+5 || m.Best != historySize+4 {
+		t.Errorf("expected every value to count, got %+v", m)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   string
+	}{
+		{nil, ""},
+		{[]float64{3, 3}, "▅▅"},
+		{[]float64{0, 7, 1, 6}, "▁█▂▇"},
+	}
+
+	for _, tt := range tests {
+		if got := Sparkline(tt.values); got != tt.want {
+			t.Errorf("Sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestWorstTallies(t *testing.T) {
+	tallies := map[string]game.Tally{
+		"a": {Tries: 10, Errors: 1},
+		"b": {Tries: 10, Errors: 5},
+		"c": {Tries: 2, Errors: 2},
+		"d": {Tries: 20, Errors: 0},
+		"e": {Tries: 20, Errors: 5},
+	}
+
+	want := []string{"b 50%", "e 25%", "a 10%"}
+	if got := worstTallies(tallies); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
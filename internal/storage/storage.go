@@ -0,0 +1,231 @@
+// Package storage keeps gg's files in the user's data directory. Files are
+// replaced atomically and updates are serialized with a lock file, so that two
+// gg processes writing at the same time can't lose each other's changes.
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// lockTimeout is how long Update waits for another process to finish.
+	lockTimeout = 5 * time.Second
+	// staleLockAge is the age after which a lock file is assumed to have
+	// been left behind by a process that crashed.
+	staleLockAge = 30 * time.Second
+)
+
+// ErrLocked is returned when a file stays locked for longer than lockTimeout.
+var ErrLocked = errors.New("storage: file is locked by another gg process")
+
+// Dir returns gg's data directory, $XDG_DATA_HOME/gg or ~/.local/share/gg if
+// XDG_DATA_HOME isn't set. The directory is created if it doesn't exist.
+func Dir() (string, error) {
+	base := os.Getenv("XDG_DATA_HOME")
+	if base == "" || !filepath.IsAbs(base) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("storage: finding the data directory: %w", err)
+		}
+		base = filepath.Join(home, ".local", "share")
+	}
+
+	dir := filepath.Join(base, "gg")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("storage: creating the data directory: %w", err)
+	}
+
+	return dir, nil
+}
+
+// Path returns the path of the named file in the data directory.
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, name), nil
+}
+
+// Read returns the contents of the named file. A file that doesn't exist yet
+// reads as empty.
+func Read(name string) ([]byte, error) {
+	path, err := Path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	return data, err
+}
+
+// Update locks the named file, passes its current contents to fn and replaces
+// the file with what fn returns. The file is left untouched if fn fails.
+func Update(name string, fn func(data []byte) ([]byte, error)) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	data, err = fn(data)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(path, data)
+}
+
+// Remove deletes the named file, if it exists.
+func Remove(name string) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// writeFile writes data to a temporary file next to path and renames it over
+// path, so readers see either the old or the new contents but never a mix.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// lock takes the lock for path by creating path.lock, which only one process
+// can do at a time, and writing a token of its own to it. The returned
+// function releases the lock.
+func lock(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_, err = f.WriteString(token)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(lockPath)
+				return nil, err
+			}
+			return func() { release(lockPath, token) }, nil
+		}
+
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		// Other processes may find the same stale lock, and one of them
+		// may break it and take the lock before this one gets to it, so
+		// only the lock found stale is removed.
+		if stale, ok := staleToken(lockPath); ok {
+			release(lockPath, stale)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, ErrLocked
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// staleToken returns the token of the lock file at lockPath and reports
+// whether it is older than staleLockAge.
+func staleToken(lockPath string) (string, bool) {
+	f, err := os.Open(lockPath)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	// The age and the token are those of the same file, even if the lock
+	// is broken and taken again in the meantime.
+	info, err := f.Stat()
+	if err != nil || time.Since(info.ModTime()) <= staleLockAge {
+		return "", false
+	}
+
+	token, err := io.ReadAll(f)
+	if err != nil {
+		return "", false
+	}
+	return string(token), true
+}
+
+// release removes the lock file at lockPath if it still holds token, so that
+// a lock another process has taken since is left alone.
+func release(lockPath, token string) {
+	if data, err := os.ReadFile(lockPath); err == nil && string(data) == token {
+		os.Remove(lockPath)
+	}
+}
+
+// newToken returns a random token telling the locks of each process apart.
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("storage: making a lock token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDir(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", base)
+
+	dir, err := Dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dir != filepath.Join(base, "gg") {
+		t.Errorf("expected the data directory in XDG_DATA_HOME, got %s", dir)
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Errorf("expected the data directory to be created")
+	}
+}
+
+func TestReadMissingFile(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	data, err := Read("missing.json")
+	if err != nil || data != nil {
+		t.Errorf("expected no data and no error, got %q, %v", data, err)
+	}
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := Update("counter", func(data []byte) ([]byte, error) {
+				n, _ := strconv.Atoi(string(data))
+				return []byte(strconv.Itoa(n + 1)), nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, err := Read("counter")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "20" {
+		t.Errorf("expected every update to be kept, got %s", data)
+	}
+}
+
+func TestStaleLockIsBroken(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	path, err := Path("stale")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path+".lock", nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleLockAge)
+	os.Chtimes(path+".lock", old, old)
+
+	err = Update("stale", func([]byte) ([]byte, error) {
+		return []byte("ok"), nil
+	})
+	if err != nil {
+		t.Fatalf("expected the stale lock to be broken, got %v", err)
+	}
+}
+
+func TestLockIsOnlyReleasedByItsHolder(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	path, err := Path("held")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unlock, err := lock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process broke the lock, thinking it stale, and took it.
+	if err := os.WriteFile(path+".lock", []byte("other"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+	if _, err := os.Stat(path + ".lock"); err != nil {
+		t.Fatal("expected the lock of the other process to be left alone")
+	}
+
+	// A process that found the lock stale before it was taken again
+	// leaves it alone too.
+	release(path+".lock", "")
+	if _, err := os.Stat(path + ".lock"); err != nil {
+		t.Fatal("expected a lock taken again to be left alone")
+	}
+
+	release(path+".lock", "other")
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Error("expected the holder to release the lock")
+	}
+}
@@ -0,0 +1,123 @@
+package theme
+
+// variants are the palettes of a theme for terminals with a dark and a light
+// background. Dark and Light are the same on both.
+type variants struct {
+	dark, light palette
+}
+
+// tetrominoes and tiles2048 are the colors gg has always drawn the pieces of
+// tetris and the tiles of 2048 with.
+var (
+	tetrominoes = [8]string{"#063970", "#4CA74F", "#CF6209", "#D85B85", "#2692E8", "#9047A3", "#CA1F7B", "#FFFDD0"}
+	tiles2048   = [11]string{"#EEE4DA", "#EDE0C8", "#F2B179", "#F59563", "#F67C5F", "#F65E3B", "#EDCF72", "#EDCC61", "#EDC850", "#EDC53F", "#EDC22E"}
+)
+
+// tiles returns the colors of the tiles of 2048, starting with the empty cell.
+func tiles(empty string, values [11]string) [12]string {
+	t := [12]string{empty}
+	copy(t[1:], values[:])
+	return t
+}
+
+var dark = palette{
+	text:    "#F9F6F2",
+	muted:   "#6E7072",
+	accent:  "#FFD900",
+	good:    "#00FF00",
+	bad:     "#FF2900",
+	players: [2]string{"#AAAAFF", "#FFAAAA"},
+	cursor:  "#0000FF",
+	board:   "#3C3A32",
+	border:  "#BEC1C6",
+	pieces:  tetrominoes,
+	tiles:   tiles("#3C3A32", tiles2048),
+}
+
+var light = palette{
+	text:    "#1C1B19",
+	muted:   "#8A8A8A",
+	accent:  "#B35900",
+	good:    "#1E7B34",
+	bad:     "#C62828",
+	players: [2]string{"#3F51B5", "#D84315"},
+	cursor:  "#9EC1FF",
+	board:   "#EEE8D5",
+	border:  "#200C0C",
+	pieces:  tetrominoes,
+	tiles:   tiles("#CDC1B4", tiles2048),
+}
+
+// The high-contrast theme sticks to saturated colors, away from the
+// background.
+var highContrastPieces = [8]string{"#0000FF", "#00FF00", "#FF8000", "#FF00FF", "#00FFFF", "#8000FF", "#FF0000", "#FFFF00"}
+
+var highContrastTiles = [11]string{"#FFFFFF", "#C0C0C0", "#FFFF00", "#FF8000", "#FF0000", "#FF00FF", "#8000FF", "#0000FF", "#00FFFF", "#00FF00", "#008000"}
+
+// The colorblind theme uses the palette of Okabe and Ito, whose colors stay
+// apart with every common kind of color blindness, and the tiles of 2048 follow
+// viridis, which stays ordered by lightness.
+var okabeIto = [8]string{"#0072B2", "#009E73", "#E69F00", "#CC79A7", "#56B4E9", "#999999", "#D55E00", "#F0E442"}
+
+var viridis = [11]string{"#440154", "#482475", "#414487", "#355F8D", "#2A788E", "#21918C", "#22A884", "#44BF70", "#7AD151", "#BDDF26", "#FDE725"}
+
+var palettes = map[string]variants{
+	Dark:  {dark, dark},
+	Light: {light, light},
+	HighContrast: {
+		dark: palette{
+			text:    "#FFFFFF",
+			muted:   "#C0C0C0",
+			accent:  "#FFFF00",
+			good:    "#00FF00",
+			bad:     "#FF0000",
+			players: [2]string{"#00FFFF", "#FF00FF"},
+			cursor:  "#FFFFFF",
+			board:   "#000000",
+			border:  "#FFFFFF",
+			pieces:  highContrastPieces,
+			tiles:   tiles("#000000", highContrastTiles),
+		},
+		light: palette{
+			text:    "#000000",
+			muted:   "#404040",
+			accent:  "#0000C0",
+			good:    "#006400",
+			bad:     "#B00000",
+			players: [2]string{"#0000FF", "#C00000"},
+			cursor:  "#000000",
+			board:   "#FFFFFF",
+			border:  "#000000",
+			pieces:  highContrastPieces,
+			tiles:   tiles("#E0E0E0", highContrastTiles),
+		},
+	},
+	Colorblind: {
+		dark: palette{
+			text:    "#FFFFFF",
+			muted:   "#999999",
+			accent:  "#F0E442",
+			good:    "#009E73",
+			bad:     "#D55E00",
+			players: [2]string{"#56B4E9", "#E69F00"},
+			cursor:  "#0072B2",
+			board:   "#1F1F1F",
+			border:  "#999999",
+			pieces:  okabeIto,
+			tiles:   tiles("#1F1F1F", viridis),
+		},
+		light: palette{
+			text:    "#000000",
+			muted:   "#6E6E6E",
+			accent:  "#0072B2",
+			good:    "#009E73",
+			bad:     "#D55E00",
+			players: [2]string{"#0072B2", "#E69F00"},
+			cursor:  "#56B4E9",
+			board:   "#F0F0F0",
+			border:  "#000000",
+			pieces:  okabeIto,
+			tiles:   tiles("#F0F0F0", viridis),
+		},
+	},
+}
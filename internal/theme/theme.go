@@ -0,0 +1,242 @@
+// Package theme holds the styles gg draws with. Games don't pick colors of
+// their own: they take the styles of the current theme, by what they draw
+// rather than by color, so that the player can swap every color at once for
+// one that suits their terminal or their eyes.
+package theme
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Auto is the name of the default theme, which is Dark or Light depending on
+// the background of the terminal.
+const Auto = "auto"
+
+// The names of the other themes.
+const (
+	Dark         = "dark"
+	Light        = "light"
+	HighContrast = "high-contrast"
+	Colorblind   = "colorblind"
+	Monochrome   = "mono"
+)
+
+// The colors of text on the pieces, tiles and cursor.
+const (
+	lightText = "#F9F6F2"
+	darkText  = "#000000"
+)
+
+// Theme is a set of styles, each named after what it draws.
+type Theme struct {
+	Name string
+
+	// Text is for ordinary text, and Muted for text of less importance,
+	// such as hints or the characters yet to be typed.
+	Text  lipgloss.Style
+	Muted lipgloss.Style
+
+	// Accent draws the eye to something, such as a new high score or the
+	// status of a game.
+	Accent lipgloss.Style
+
+	// Good and Bad tell what went right from what went wrong, such as the
+	// characters typed correctly and those mistyped.
+	Good lipgloss.Style
+	Bad  lipgloss.Style
+
+	// Players tell the two sides of a game apart: the X and the O, the
+	// two paddles, the player and the dealer. One-player games draw the
+	// player with Players[0].
+	Players [2]lipgloss.Style
+
+	// Cursor marks the selected cell of a board.
+	Cursor lipgloss.Style
+
+	// Board is the background of boards, and Border the color of their
+	// borders.
+	Board  lipgloss.Style
+	Border lipgloss.TerminalColor
+
+	// Pieces tell apart the kinds of pieces of a game, such as the
+	// tetrominoes. They set a background and the color of text on it.
+	Pieces [8]lipgloss.Style
+
+	// Tiles are the tiles of 2048 by their exponent: Tiles[0] is an empty
+	// cell, Tiles[1] the 2, and so on up to Tiles[11], the 2048. Larger
+	// tiles use Tiles[11] too.
+	Tiles [12]lipgloss.Style
+}
+
+// Tile returns the style of the 2048 tile with the given value, or of an empty
+// cell if value is 0.
+func (t Theme) Tile(value int) lipgloss.Style {
+	exp := 0
+	for v := value; v > 1; v /= 2 {
+		exp++
+	}
+
+	return t.Tiles[min(exp, len(t.Tiles)-1)]
+}
+
+// current is the theme games draw with. Until Set is called, it is the dark
+// theme, which keeps tests away from the terminal.
+var current = palettes[Dark].dark.theme(Dark)
+
+// Current returns the theme games should draw with.
+func Current() Theme {
+	return current
+}
+
+// Names returns the names of the themes that can be passed to Set.
+func Names() []string {
+	return []string{Auto, Dark, Light, HighContrast, Colorblind, Monochrome}
+}
+
+// Set makes the theme with the given name the current one. The empty name
+// selects Auto. Auto, and the themes that adapt to the background, ask the
+// terminal for its background color, so Set must be called before the
+// program takes over the terminal.
+//
+// If NO_COLOR is set, lipgloss draws no color whatever the theme, so the
+// monochrome theme, which tells things apart with bold and reverse text
+// instead, is used in place of the one named, once the name is checked.
+func Set(name string) error {
+	if name == "" {
+		name = Auto
+	}
+
+	if name != Auto && name != Monochrome {
+		if _, ok := palettes[name]; !ok {
+			return fmt.Errorf("theme: unknown theme %q, expected one of %s", name, strings.Join(Names(), ", "))
+		}
+	}
+
+	switch {
+	case os.Getenv("NO_COLOR") != "" || name == Monochrome:
+		current = monochrome()
+	case name == Dark || name == Light:
+		current = palettes[name].dark.theme(name)
+	default:
+		dark := lipgloss.HasDarkBackground()
+		if name == Auto {
+			name = Light
+			if dark {
+				name = Dark
+			}
+		}
+
+		p := palettes[name].light
+		if dark {
+			p = palettes[name].dark
+		}
+		current = p.theme(name)
+	}
+
+	return nil
+}
+
+// palette is the colors of a theme, as hex strings. The color of the text on
+// the pieces, tiles and cursor is chosen for contrast with their background.
+type palette struct {
+	text, muted, accent, good, bad string
+	players                        [2]string
+	cursor, board, border          string
+	pieces                         [8]string
+	tiles                          [12]string
+}
+
+func (p palette) theme(name string) Theme {
+	fg := func(c string) lipgloss.Style {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(c))
+	}
+	bg := func(c string) lipgloss.Style {
+		return lipgloss.NewStyle().Background(lipgloss.Color(c)).Foreground(lipgloss.Color(textOn(c)))
+	}
+
+	t := Theme{
+		Name:    name,
+		Text:    fg(p.text),
+		Muted:   fg(p.muted),
+		Accent:  fg(p.accent),
+		Good:    fg(p.good),
+		Bad:     fg(p.bad),
+		Players: [2]lipgloss.Style{fg(p.players[0]), fg(p.players[1])},
+		Cursor:  bg(p.cursor),
+		Board:   lipgloss.NewStyle().Background(lipgloss.Color(p.board)),
+		Border:  lipgloss.Color(p.border),
+	}
+	for i, c := range p.pieces {
+		t.Pieces[i] = bg(c)
+	}
+	for i, c := range p.tiles {
+		t.Tiles[i] = bg(c)
+	}
+
+	return t
+}
+
+// monochrome returns the theme used without colors, which relies on text
+// attributes alone.
+func monochrome() Theme {
+	plain := lipgloss.NewStyle()
+	reverse := plain.Reverse(true)
+
+	t := Theme{
+		Name:    Monochrome,
+		Text:    plain,
+		Muted:   plain.Faint(true),
+		Accent:  plain.Bold(true),
+		Good:    plain,
+		Bad:     reverse,
+		Players: [2]lipgloss.Style{plain.Bold(true), plain},
+		Cursor:  reverse,
+		Board:   plain,
+		Border:  lipgloss.NoColor{},
+	}
+	for i := range t.Pieces {
+		t.Pieces[i] = reverse
+	}
+	t.Tiles[0] = plain
+	for i := 1; i < len(t.Tiles); i++ {
+		t.Tiles[i] = reverse
+	}
+
+	return t
+}
+
+// textOn returns the color of text on a background of the hex color bg: black
+// or white, whichever contrasts more with it.
+func textOn(bg string) string {
+	// The relative luminance as defined by WCAG 2, above which black text
+	// has the better contrast ratio.
+	const threshold = 0.179
+
+	if luminance(bg) > threshold {
+		return darkText
+	}
+	return lightText
+}
+
+func luminance(hex string) float64 {
+	rgb, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil {
+		return 0
+	}
+
+	linear := func(c uint64) float64 {
+		v := float64(c) / 255
+		if v <= 0.03928 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+
+	return 0.2126*linear(rgb>>16&0xff) + 0.7152*linear(rgb>>8&0xff) + 0.0722*linear(rgb&0xff)
+}
@@ -0,0 +1,100 @@
+package theme
+
+import (
+	"strings"
+	"testing"
+)
+
+func keepCurrent(t *testing.T) {
+	t.Helper()
+
+	saved := current
+	t.Cleanup(func() { current = saved })
+}
+
+func TestSet(t *testing.T) {
+	keepCurrent(t)
+	t.Setenv("NO_COLOR", "")
+
+	if err := Set(Light); err != nil {
+		t.Fatal(err)
+	}
+	if Current().Name != Light {
+		t.Errorf("expected the light theme, got %q", Current().Name)
+	}
+
+	err := Set("solarized")
+	if err == nil || !strings.Contains(err.Error(), `unknown theme "solarized"`) {
+		t.Fatalf("expected an unknown theme to be refused, got %v", err)
+	}
+	if Current().Name != Light {
+		t.Errorf("expected a refused theme to leave the current one, got %q", Current().Name)
+	}
+}
+
+func TestNoColor(t *testing.T) {
+	keepCurrent(t)
+	t.Setenv("NO_COLOR", "1")
+
+	if err := Set(Colorblind); err != nil {
+		t.Fatal(err)
+	}
+	if Current().Name != Monochrome {
+		t.Errorf("expected NO_COLOR to select the monochrome theme, got %q", Current().Name)
+	}
+
+	if err := Set("solarized"); err == nil {
+		t.Error("expected an unknown theme to be refused even with NO_COLOR")
+	}
+}
+
+func TestPalettes(t *testing.T) {
+	for name, v := range palettes {
+		for _, p := range []palette{v.dark, v.light} {
+			for _, c := range append(append(p.pieces[:], p.tiles[:]...), p.text, p.muted, p.cursor, p.board) {
+				if len(c) != 7 || luminance(c) == 0 && c != "#000000" {
+					t.Errorf("%s: %q isn't a hex color", name, c)
+				}
+			}
+		}
+	}
+}
+
+func TestTextOn(t *testing.T) {
+	tests := []struct {
+		bg, want string
+	}{
+		{"#000000", lightText},
+		{"#FFFFFF", darkText},
+		{"#063970", lightText},
+		{"#EEE4DA", darkText},
+		{"#FDE725", darkText},
+	}
+
+	for _, tt := range tests {
+		if got := textOn(tt.bg); got != tt.want {
+			t.Errorf("textOn(%s) = %s, want %s", tt.bg, got, tt.want)
+		}
+	}
+}
+
+func TestTile(t *testing.T) {
+	th := Current()
+
+	tests := []struct {
+		value, exp int
+	}{
+		{0, 0},
+		{2, 1},
+		{4, 2},
+		{2048, 11},
+		{8192, 11},
+	}
+
+	for _, tt := range tests {
+		got, want := th.Tile(tt.value).GetBackground(), th.Tiles[tt.exp].GetBackground()
+		if got != want {
+			t.Errorf("Tile(%d) = %v, want the color of Tiles[%d], %v", tt.value, got, tt.exp, want)
+		}
+	}
+}